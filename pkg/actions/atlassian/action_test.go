@@ -0,0 +1,60 @@
+package atlassian_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/apple/pkl-go/pkl"
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/hatchery/pkg/actions/atlassian"
+	"github.com/m-mizutani/hatchery/pkg/domain/config"
+	"github.com/m-mizutani/hatchery/pkg/domain/model"
+	"github.com/m-mizutani/hatchery/pkg/infra"
+	"github.com/m-mizutani/hatchery/pkg/infra/cs"
+	"github.com/m-mizutani/hatchery/pkg/utils"
+)
+
+func TestAtlassianAudit(t *testing.T) {
+	var queries []string
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET api.atlassian.com/admin/v1/orgs/test-org/events", func(w http.ResponseWriter, r *http.Request) {
+		gt.Equal(t, r.Header.Get("Authorization"), "Bearer test-key")
+		queries = append(queries, r.URL.RawQuery)
+
+		if r.URL.Query().Get("cursor") == "" {
+			_, _ = w.Write([]byte(`{"data":[{"id":"1"}],"links":{"next":"https://api.atlassian.com/admin/v1/orgs/test-org/events?cursor=next-1"}}`))
+		} else {
+			_, _ = w.Write([]byte(`{"data":[{"id":"2"}],"links":{}}`))
+		}
+	})
+
+	mock := cs.NewMock()
+	clients := infra.New(infra.WithCloudStorage(mock), infra.WithHTTPClient(utils.NewFakeHTTPClient(mux)))
+
+	ctx := context.Background()
+	_, ctx = utils.CtxRequestID(ctx)
+	now := time.Date(2024, 4, 1, 10, 0, 0, 0, time.UTC)
+	ctx = utils.CtxWithNow(ctx, func() time.Time { return now })
+
+	req := &config.AtlassianAuditImpl{
+		OrgId:    "test-org",
+		ApiKey:   "test-key",
+		Duration: &pkl.Duration{Value: 1, Unit: pkl.Hour},
+		Bucket:   "test-bucket",
+	}
+	gt.NoError(t, atlassian.Exec(ctx, clients, req)).Must()
+
+	gt.A(t, queries).Equal([]string{
+		"from=1711962000000&to=1711965600000",
+		"cursor=next-1",
+	})
+	gt.A(t, mock.Results).Length(2).
+		At(0, func(t testing.TB, v *cs.MockResult) {
+			gt.Equal(t, v.Object, model.DefaultLogObjectName(ctx, req, now, 0))
+		}).
+		At(1, func(t testing.TB, v *cs.MockResult) {
+			gt.Equal(t, v.Object, model.DefaultLogObjectName(ctx, req, now, 1))
+		})
+}
@@ -0,0 +1,136 @@
+package box
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/m-mizutani/goerr"
+	"github.com/m-mizutani/hatchery/pkg/domain/config"
+	"github.com/m-mizutani/hatchery/pkg/domain/interfaces"
+	"github.com/m-mizutani/hatchery/pkg/domain/model"
+	"github.com/m-mizutani/hatchery/pkg/infra"
+	"github.com/m-mizutani/hatchery/pkg/infra/oauth"
+	"github.com/m-mizutani/hatchery/pkg/infra/output"
+	"github.com/m-mizutani/hatchery/pkg/utils"
+)
+
+const (
+	// Client Credentials Grant with enterprise subject.
+	// See https://developer.box.com/guides/authentication/client-credentials/
+	tokenURL = "https://api.box.com/oauth2/token"
+	// See https://developer.box.com/reference/get-events/
+	eventsURL = "https://api.box.com/2.0/events"
+)
+
+func Exec(ctx context.Context, clients *infra.Clients, req *config.BoxImpl) error {
+	ts := oauth.NewTokenSource(clients.HTTPClient(), oauth.Config{
+		TokenURL:     tokenURL,
+		ClientID:     req.GetClientId(),
+		ClientSecret: req.GetClientSecret(),
+		Params: url.Values{
+			"box_subject_type": {"enterprise"},
+			"box_subject_id":   {req.GetEnterpriseId()},
+		},
+	})
+	httpClient := oauth.NewClient(clients.HTTPClient(), ts)
+
+	now := utils.CtxNow(ctx)
+	var streamPosition string
+	for seq := 0; req.MaxPages == nil || seq < *req.MaxPages; seq++ {
+		next, err := crawl(ctx, clients, httpClient, req, now, seq, streamPosition)
+		if err != nil {
+			return goerr.Wrap(err, "failed to crawl Box admin logs").With("seq", seq).With("req", req)
+		}
+		if next == nil {
+			break
+		}
+		streamPosition = *next
+	}
+
+	return nil
+}
+
+func crawl(ctx context.Context, clients *infra.Clients, httpClient interfaces.HTTPClient, req *config.BoxImpl, end time.Time, seq int, streamPosition string) (*string, error) {
+	start := end.Add(-req.GetDuration().GoDuration())
+
+	qv := url.Values{}
+	qv.Add("stream_type", "admin_logs")
+	qv.Add("created_after", start.UTC().Format(time.RFC3339))
+	qv.Add("created_before", end.UTC().Format(time.RFC3339))
+	qv.Add("limit", fmt.Sprintf("%d", req.GetLimit()))
+	if streamPosition != "" {
+		qv.Add("stream_position", streamPosition)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, eventsURL+"?"+qv.Encode(), nil)
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to create HTTP request")
+	}
+	httpReq.Header.Set("Accept", "application/json")
+
+	httpResp, err := httpClient.Do(httpReq)
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to send HTTP request")
+	}
+	defer utils.SafeClose(httpResp.Body)
+
+	if httpResp.StatusCode != http.StatusOK {
+		data, _ := io.ReadAll(httpResp.Body)
+		return nil, goerr.New("unexpected status code").With("status", httpResp.Status).With("body", string(data))
+	}
+
+	body, err := io.ReadAll(httpResp.Body)
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to read response body")
+	}
+
+	var resp apiResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, goerr.Wrap(err, "failed to unmarshal response body")
+	}
+
+	// Box returns an empty chunk with next_stream_position when no more events
+	if resp.ChunkSize == 0 {
+		return nil, nil
+	}
+
+	objName := model.DefaultLogObjectName(ctx, req, end, seq)
+	w, err := output.NewLogObjectWriter(ctx, clients.CloudStorage(), req, objName, output.WithWindow(start, end))
+	if err != nil {
+		return nil, err
+	}
+
+	n, err := io.Copy(w, bytes.NewReader(body))
+	if err != nil {
+		w.Abort()
+		return nil, goerr.Wrap(err, "failed to write response to object writer").With("bytes", n)
+	}
+	if err := w.Close(); err != nil {
+		return nil, goerr.Wrap(err, "failed to close object writer").With("object", objName)
+	}
+
+	utils.CtxLogger(ctx).Info("harvested Box admin logs", "events", resp.ChunkSize, "bytes", n, "object", w.Object())
+
+	if resp.ChunkSize < req.GetLimit() {
+		return nil, nil
+	}
+
+	// next_stream_position is either number or string depending on stream type
+	next := string(bytes.Trim(resp.NextStreamPosition, `"`))
+	if next == "" || next == streamPosition {
+		return nil, nil
+	}
+	return &next, nil
+}
+
+type apiResponse struct {
+	ChunkSize          int               `json:"chunk_size"`
+	NextStreamPosition json.RawMessage   `json:"next_stream_position"`
+	Entries            []json.RawMessage `json:"entries"`
+}
@@ -0,0 +1,70 @@
+package box_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/apple/pkl-go/pkl"
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/hatchery/pkg/actions/box"
+	"github.com/m-mizutani/hatchery/pkg/domain/config"
+	"github.com/m-mizutani/hatchery/pkg/domain/model"
+	"github.com/m-mizutani/hatchery/pkg/infra"
+	"github.com/m-mizutani/hatchery/pkg/infra/cs"
+	"github.com/m-mizutani/hatchery/pkg/utils"
+)
+
+func TestBox(t *testing.T) {
+	var queries []string
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST api.box.com/oauth2/token", func(w http.ResponseWriter, r *http.Request) {
+		gt.NoError(t, r.ParseForm())
+		gt.Equal(t, r.PostForm.Get("grant_type"), "client_credentials")
+		gt.Equal(t, r.PostForm.Get("client_id"), "test-client")
+		gt.Equal(t, r.PostForm.Get("client_secret"), "test-secret")
+		gt.Equal(t, r.PostForm.Get("box_subject_type"), "enterprise")
+		gt.Equal(t, r.PostForm.Get("box_subject_id"), "12345")
+		_, _ = w.Write([]byte(`{"access_token":"test-token","token_type":"bearer","expires_in":3600}`))
+	})
+	mux.HandleFunc("GET api.box.com/2.0/events", func(w http.ResponseWriter, r *http.Request) {
+		gt.Equal(t, r.Header.Get("Authorization"), "Bearer test-token")
+		queries = append(queries, r.URL.RawQuery)
+		if r.URL.Query().Get("stream_position") == "" {
+			_, _ = w.Write([]byte(`{"chunk_size":2,"next_stream_position":"pos-1","entries":[{"event_id":"1"},{"event_id":"2"}]}`))
+		} else {
+			_, _ = w.Write([]byte(`{"chunk_size":1,"next_stream_position":"pos-2","entries":[{"event_id":"3"}]}`))
+		}
+	})
+
+	mock := cs.NewMock()
+	clients := infra.New(infra.WithCloudStorage(mock), infra.WithHTTPClient(utils.NewFakeHTTPClient(mux)))
+
+	ctx := context.Background()
+	_, ctx = utils.CtxRequestID(ctx)
+	now := time.Date(2024, 4, 1, 10, 0, 0, 0, time.UTC)
+	ctx = utils.CtxWithNow(ctx, func() time.Time { return now })
+
+	req := &config.BoxImpl{
+		ClientId:     "test-client",
+		ClientSecret: "test-secret",
+		EnterpriseId: "12345",
+		Duration:     &pkl.Duration{Value: 1, Unit: pkl.Hour},
+		Limit:        2,
+		Bucket:       "test-bucket",
+	}
+	gt.NoError(t, box.Exec(ctx, clients, req)).Must()
+
+	gt.A(t, queries).Equal([]string{
+		"created_after=2024-04-01T09%3A00%3A00Z&created_before=2024-04-01T10%3A00%3A00Z&limit=2&stream_type=admin_logs",
+		"created_after=2024-04-01T09%3A00%3A00Z&created_before=2024-04-01T10%3A00%3A00Z&limit=2&stream_position=pos-1&stream_type=admin_logs",
+	})
+	gt.A(t, mock.Results).Length(2).
+		At(0, func(t testing.TB, v *cs.MockResult) {
+			gt.Equal(t, v.Object, model.DefaultLogObjectName(ctx, req, now, 0))
+		}).
+		At(1, func(t testing.TB, v *cs.MockResult) {
+			gt.Equal(t, v.Object, model.DefaultLogObjectName(ctx, req, now, 1))
+		})
+}
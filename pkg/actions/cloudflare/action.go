@@ -0,0 +1,206 @@
+package cloudflare
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/m-mizutani/goerr"
+	"github.com/m-mizutani/hatchery/pkg/domain/config"
+	"github.com/m-mizutani/hatchery/pkg/domain/interfaces"
+	"github.com/m-mizutani/hatchery/pkg/domain/model"
+	"github.com/m-mizutani/hatchery/pkg/domain/types"
+	"github.com/m-mizutani/hatchery/pkg/infra"
+	"github.com/m-mizutani/hatchery/pkg/infra/oauth"
+	"github.com/m-mizutani/hatchery/pkg/infra/output"
+	"github.com/m-mizutani/hatchery/pkg/utils"
+)
+
+const (
+	baseURL = "https://api.cloudflare.com/client/v4"
+
+	// Logpull limitations.
+	// See https://developers.cloudflare.com/logs/logpull/requesting-logs/
+	logpullMaxWindow = time.Hour
+	logpullLookback  = 7 * 24 * time.Hour
+	// end of Logpull request must be at least 1 minute earlier than now
+	logpullDelay = time.Minute
+)
+
+func Exec(ctx context.Context, clients *infra.Clients, req *config.CloudflareImpl) error {
+	httpClient := oauth.NewClient(clients.HTTPClient(), oauth.StaticToken(req.GetApiToken()))
+	now := utils.CtxNow(ctx)
+
+	for seq := 0; req.MaxPages == nil || seq < *req.MaxPages; seq++ {
+		more, err := crawlAuditLogs(ctx, clients, httpClient, req, now, seq)
+		if err != nil {
+			return goerr.Wrap(err, "failed to crawl Cloudflare audit logs").With("seq", seq).With("req", req)
+		}
+		if !more {
+			break
+		}
+	}
+
+	if req.ZoneIds == nil {
+		return nil
+	}
+
+	windows := logpullWindows(now.Add(-req.GetDuration().GoDuration()), now)
+	for _, zoneID := range *req.ZoneIds {
+		for seq, w := range windows {
+			if err := pullLogs(ctx, clients, httpClient, req, zoneID, w, now, seq); err != nil {
+				return goerr.Wrap(err, "failed to pull Cloudflare HTTP request logs").With("zone", zoneID).With("window", w).With("req", req)
+			}
+		}
+	}
+
+	return nil
+}
+
+// crawlAuditLogs writes one page of account audit logs. seq is 0-origin and page of API is 1-origin.
+func crawlAuditLogs(ctx context.Context, clients *infra.Clients, httpClient interfaces.HTTPClient, req *config.CloudflareImpl, end time.Time, seq int) (bool, error) {
+	start := end.Add(-req.GetDuration().GoDuration())
+
+	qv := url.Values{}
+	qv.Add("since", start.UTC().Format(time.RFC3339))
+	qv.Add("before", end.UTC().Format(time.RFC3339))
+	qv.Add("per_page", fmt.Sprintf("%d", req.GetLimit()))
+	qv.Add("page", fmt.Sprintf("%d", seq+1))
+	qv.Add("direction", "asc")
+
+	apiURL := baseURL + "/accounts/" + url.PathEscape(req.GetAccountId()) + "/audit_logs?" + qv.Encode()
+	httpResp, err := get(ctx, httpClient, apiURL)
+	if err != nil {
+		return false, err
+	}
+	defer utils.SafeClose(httpResp.Body)
+
+	// A page is bounded by per_page, then it is buffered to validate the response before the object is written
+	body, err := io.ReadAll(httpResp.Body)
+	if err != nil {
+		return false, goerr.Wrap(err, "failed to read response body")
+	}
+	var resp auditLogsResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return false, goerr.Wrap(err, "failed to decode response body")
+	}
+	if !resp.Success {
+		return false, goerr.New("Cloudflare API returned error").With("errors", resp.Errors)
+	}
+
+	objName := model.DatasetLogObjectName(ctx, req, "audit_logs", end, seq)
+	w, err := output.NewLogObjectWriter(ctx, clients.CloudStorage(), req, objName, output.WithWindow(start, end))
+	if err != nil {
+		return false, err
+	}
+	// The writer is not closed on failure not to commit an incomplete object
+	if _, err := w.Write(body); err != nil {
+		w.Abort()
+		return false, goerr.Wrap(err, "failed to write response to object writer")
+	}
+	if err := w.Close(); err != nil {
+		return false, goerr.Wrap(err, "failed to close object writer").With("object", objName)
+	}
+
+	utils.CtxLogger(ctx).Info("harvested Cloudflare audit logs", "logs", resp.ResultInfo.Count, "object", w.Object())
+
+	info := resp.ResultInfo
+	return info.Count >= req.GetLimit() && info.Page*info.PerPage < info.TotalCount, nil
+}
+
+type window struct {
+	Start time.Time
+	End   time.Time
+}
+
+// logpullWindows splits [start, end) shifted back by the delay of log availability into windows acceptable for Logpull API. The whole range is shifted, not clipped, so that consecutive executions with the same duration leave no gap. The range is clipped by the lookback limit.
+func logpullWindows(start, end time.Time) []window {
+	oldest := end.Add(-logpullLookback).Add(logpullDelay)
+	start, end = start.Add(-logpullDelay), end.Add(-logpullDelay)
+	if start.Before(oldest) {
+		start = oldest
+	}
+
+	var windows []window
+	for s := start; s.Before(end); s = s.Add(logpullMaxWindow) {
+		e := s.Add(logpullMaxWindow)
+		if e.After(end) {
+			e = end
+		}
+		windows = append(windows, window{Start: s, End: e})
+	}
+	return windows
+}
+
+// pullLogs streams NDJSON of HTTP request logs into the object. Logpull does not paginate and a response can be large, then it must not be buffered.
+func pullLogs(ctx context.Context, clients *infra.Clients, httpClient interfaces.HTTPClient, req *config.CloudflareImpl, zoneID string, win window, now time.Time, seq int) error {
+	qv := url.Values{}
+	qv.Add("start", win.Start.UTC().Format(time.RFC3339))
+	qv.Add("end", win.End.UTC().Format(time.RFC3339))
+	qv.Add("timestamps", "rfc3339")
+	if req.LogpullFields != nil && len(*req.LogpullFields) > 0 {
+		qv.Add("fields", strings.Join(*req.LogpullFields, ","))
+	}
+
+	apiURL := baseURL + "/zones/" + url.PathEscape(zoneID) + "/logs/received?" + qv.Encode()
+	httpResp, err := get(ctx, httpClient, apiURL)
+	if err != nil {
+		return err
+	}
+	defer utils.SafeClose(httpResp.Body)
+
+	objName := model.DatasetLogObjectName(ctx, req, "http_requests/"+zoneID, now, seq)
+	w, err := output.NewLogObjectWriter(ctx, clients.CloudStorage(), req, objName, output.WithWindow(win.Start, win.End), types.WithContentType(output.ContentTypeNDJSON))
+	if err != nil {
+		return err
+	}
+
+	n, err := io.Copy(w, httpResp.Body)
+	if err != nil {
+		w.Abort()
+		return goerr.Wrap(err, "failed to write response to object writer").With("bytes", n)
+	}
+	if err := w.Close(); err != nil {
+		return goerr.Wrap(err, "failed to close object writer").With("object", objName)
+	}
+
+	utils.CtxLogger(ctx).Info("harvested Cloudflare HTTP request logs", "zone", zoneID, "start", win.Start, "end", win.End, "bytes", n, "object", w.Object())
+
+	return nil
+}
+
+func get(ctx context.Context, httpClient interfaces.HTTPClient, apiURL string) (*http.Response, error) {
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, apiURL, nil)
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to create HTTP request")
+	}
+
+	httpResp, err := httpClient.Do(httpReq)
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to send HTTP request")
+	}
+
+	if httpResp.StatusCode != http.StatusOK {
+		defer utils.SafeClose(httpResp.Body)
+		data, _ := io.ReadAll(httpResp.Body)
+		return nil, goerr.New("unexpected status code").With("status", httpResp.Status).With("body", string(data)).With("url", apiURL)
+	}
+
+	return httpResp, nil
+}
+
+type auditLogsResponse struct {
+	Success    bool              `json:"success"`
+	Errors     []json.RawMessage `json:"errors"`
+	ResultInfo struct {
+		Page       int `json:"page"`
+		PerPage    int `json:"per_page"`
+		Count      int `json:"count"`
+		TotalCount int `json:"total_count"`
+	} `json:"result_info"`
+}
@@ -0,0 +1,172 @@
+package cloudflare_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/apple/pkl-go/pkl"
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/hatchery/pkg/actions/cloudflare"
+	"github.com/m-mizutani/hatchery/pkg/domain/config"
+	"github.com/m-mizutani/hatchery/pkg/domain/model"
+	"github.com/m-mizutani/hatchery/pkg/infra"
+	"github.com/m-mizutani/hatchery/pkg/infra/cs"
+	"github.com/m-mizutani/hatchery/pkg/utils"
+)
+
+const (
+	accountID = "0123456789abcdef0123456789abcdef"
+	zoneID    = "fedcba9876543210fedcba9876543210"
+)
+
+func TestCloudflare(t *testing.T) {
+	var pages []string
+	var windows [][2]string
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET api.cloudflare.com/client/v4/accounts/"+accountID+"/audit_logs", func(w http.ResponseWriter, r *http.Request) {
+		gt.Equal(t, r.Header.Get("Authorization"), "Bearer test-token")
+		gt.Equal(t, r.URL.Query().Get("since"), "2024-04-01T07:30:00Z")
+		gt.Equal(t, r.URL.Query().Get("before"), "2024-04-01T10:00:00Z")
+		page := r.URL.Query().Get("page")
+		pages = append(pages, page)
+		if page == "1" {
+			_, _ = w.Write([]byte(`{"success":true,"errors":[],"result":[{"id":"1"},{"id":"2"}],"result_info":{"page":1,"per_page":2,"count":2,"total_count":3}}`))
+		} else {
+			_, _ = w.Write([]byte(`{"success":true,"errors":[],"result":[{"id":"3"}],"result_info":{"page":2,"per_page":2,"count":1,"total_count":3}}`))
+		}
+	})
+	mux.HandleFunc("GET api.cloudflare.com/client/v4/zones/"+zoneID+"/logs/received", func(w http.ResponseWriter, r *http.Request) {
+		gt.Equal(t, r.Header.Get("Authorization"), "Bearer test-token")
+		gt.Equal(t, r.URL.Query().Get("fields"), "ClientIP,EdgeStartTimestamp")
+		windows = append(windows, [2]string{r.URL.Query().Get("start"), r.URL.Query().Get("end")})
+		_, _ = w.Write([]byte(`{"ClientIP":"192.0.2.1","EdgeStartTimestamp":"` + r.URL.Query().Get("start") + `"}` + "\n"))
+	})
+
+	mock := cs.NewMock()
+	clients := infra.New(infra.WithCloudStorage(mock), infra.WithHTTPClient(utils.NewFakeHTTPClient(mux)))
+
+	ctx := context.Background()
+	_, ctx = utils.CtxRequestID(ctx)
+	now := time.Date(2024, 4, 1, 10, 0, 0, 0, time.UTC)
+	ctx = utils.CtxWithNow(ctx, func() time.Time { return now })
+
+	req := &config.CloudflareImpl{
+		ApiToken:      "test-token",
+		AccountId:     accountID,
+		ZoneIds:       &[]string{zoneID},
+		LogpullFields: &[]string{"ClientIP", "EdgeStartTimestamp"},
+		Duration:      &pkl.Duration{Value: 150, Unit: pkl.Minute},
+		Limit:         2,
+		Bucket:        "test-bucket",
+	}
+	gt.NoError(t, cloudflare.Exec(ctx, clients, req)).Must()
+
+	gt.A(t, pages).Equal([]string{"1", "2"})
+	// 1 hour window shifted by 1 minute delay
+	gt.A(t, windows).Equal([][2]string{
+		{"2024-04-01T07:29:00Z", "2024-04-01T08:29:00Z"},
+		{"2024-04-01T08:29:00Z", "2024-04-01T09:29:00Z"},
+		{"2024-04-01T09:29:00Z", "2024-04-01T09:59:00Z"},
+	})
+
+	gt.A(t, mock.Results).Length(5).
+		At(0, func(t testing.TB, v *cs.MockResult) {
+			gt.Equal(t, v.Object, model.DatasetLogObjectName(ctx, req, "audit_logs", now, 0))
+			r := gt.R1(gzip.NewReader(bytes.NewReader(v.Body.Bytes()))).NoError(t)
+			gt.S(t, string(gt.R1(io.ReadAll(r)).NoError(t))).Contains(`"result":[{"id":"1"},{"id":"2"}]`)
+		}).
+		At(2, func(t testing.TB, v *cs.MockResult) {
+			gt.Equal(t, v.Object, model.DatasetLogObjectName(ctx, req, "http_requests/"+zoneID, now, 0))
+			r := gt.R1(gzip.NewReader(bytes.NewReader(v.Body.Bytes()))).NoError(t)
+			gt.Equal(t, string(gt.R1(io.ReadAll(r)).NoError(t)), `{"ClientIP":"192.0.2.1","EdgeStartTimestamp":"2024-04-01T07:29:00Z"}`+"\n")
+		})
+}
+
+func TestCloudflareLogpullConsecutiveRuns(t *testing.T) {
+	var windows [][2]string
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET api.cloudflare.com/client/v4/accounts/"+accountID+"/audit_logs", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"success":true,"errors":[],"result":[],"result_info":{"page":1,"per_page":1000,"count":0,"total_count":0}}`))
+	})
+	mux.HandleFunc("GET api.cloudflare.com/client/v4/zones/"+zoneID+"/logs/received", func(w http.ResponseWriter, r *http.Request) {
+		windows = append(windows, [2]string{r.URL.Query().Get("start"), r.URL.Query().Get("end")})
+	})
+
+	clients := infra.New(infra.WithCloudStorage(cs.NewMock()), infra.WithHTTPClient(utils.NewFakeHTTPClient(mux)))
+	req := &config.CloudflareImpl{
+		ApiToken:  "test-token",
+		AccountId: accountID,
+		ZoneIds:   &[]string{zoneID},
+		Duration:  &pkl.Duration{Value: 20, Unit: pkl.Minute},
+		Limit:     1000,
+		Bucket:    "test-bucket",
+	}
+
+	// Executions every 20 minutes with 20 minutes duration
+	for _, now := range []time.Time{
+		time.Date(2024, 4, 1, 10, 0, 0, 0, time.UTC),
+		time.Date(2024, 4, 1, 10, 20, 0, 0, time.UTC),
+	} {
+		ctx := utils.CtxWithNow(context.Background(), func() time.Time { return now })
+		gt.NoError(t, cloudflare.Exec(ctx, clients, req)).Must()
+	}
+
+	gt.A(t, windows).Equal([][2]string{
+		{"2024-04-01T09:39:00Z", "2024-04-01T09:59:00Z"},
+		{"2024-04-01T09:59:00Z", "2024-04-01T10:19:00Z"},
+	})
+}
+
+func TestCloudflareLogpullLookback(t *testing.T) {
+	var windows int
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET api.cloudflare.com/client/v4/accounts/"+accountID+"/audit_logs", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"success":true,"errors":[],"result":[],"result_info":{"page":1,"per_page":1000,"count":0,"total_count":0}}`))
+	})
+	mux.HandleFunc("GET api.cloudflare.com/client/v4/zones/"+zoneID+"/logs/received", func(w http.ResponseWriter, r *http.Request) {
+		start := gt.R1(time.Parse(time.RFC3339, r.URL.Query().Get("start"))).NoError(t)
+		gt.Equal(t, start.Before(time.Date(2024, 3, 25, 10, 0, 0, 0, time.UTC)), false)
+		windows++
+	})
+
+	clients := infra.New(infra.WithCloudStorage(cs.NewMock()), infra.WithHTTPClient(utils.NewFakeHTTPClient(mux)))
+	now := time.Date(2024, 4, 1, 10, 0, 0, 0, time.UTC)
+	ctx := utils.CtxWithNow(context.Background(), func() time.Time { return now })
+
+	req := &config.CloudflareImpl{
+		ApiToken:  "test-token",
+		AccountId: accountID,
+		ZoneIds:   &[]string{zoneID},
+		Duration:  &pkl.Duration{Value: 10, Unit: pkl.Day},
+		Limit:     1000,
+		Bucket:    "test-bucket",
+	}
+	gt.NoError(t, cloudflare.Exec(ctx, clients, req)).Must()
+
+	// 7 days minus 2 minutes of delay and margin are split into 1 hour windows
+	gt.Equal(t, windows, 7*24)
+}
+
+func TestCloudflareAPIError(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET api.cloudflare.com/client/v4/accounts/"+accountID+"/audit_logs", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"success":false,"errors":[{"code":10000,"message":"Authentication error"}],"result":null}`))
+	})
+
+	mock := cs.NewMock()
+	clients := infra.New(infra.WithCloudStorage(mock), infra.WithHTTPClient(utils.NewFakeHTTPClient(mux)))
+	req := &config.CloudflareImpl{
+		ApiToken:  "test-token",
+		AccountId: accountID,
+		Duration:  &pkl.Duration{Value: 1, Unit: pkl.Hour},
+		Limit:     1000,
+		Bucket:    "test-bucket",
+	}
+	gt.Error(t, cloudflare.Exec(context.Background(), clients, req))
+	gt.A(t, mock.Results).Length(0)
+}
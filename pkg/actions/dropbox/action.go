@@ -0,0 +1,151 @@
+package dropbox
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/m-mizutani/goerr"
+	"github.com/m-mizutani/hatchery/pkg/domain/config"
+	"github.com/m-mizutani/hatchery/pkg/domain/interfaces"
+	"github.com/m-mizutani/hatchery/pkg/domain/model"
+	"github.com/m-mizutani/hatchery/pkg/infra"
+	"github.com/m-mizutani/hatchery/pkg/infra/oauth"
+	"github.com/m-mizutani/hatchery/pkg/infra/output"
+	"github.com/m-mizutani/hatchery/pkg/utils"
+)
+
+const (
+	// Access token is refreshed by long-lived refresh token.
+	// See https://developers.dropbox.com/oauth-guide
+	tokenURL = "https://api.dropboxapi.com/oauth2/token"
+	// See https://www.dropbox.com/developers/documentation/http/teams#team_log-get_events
+	getEventsURL         = "https://api.dropboxapi.com/2/team_log/get_events"
+	getEventsContinueURL = "https://api.dropboxapi.com/2/team_log/get_events/continue"
+)
+
+func Exec(ctx context.Context, clients *infra.Clients, req *config.DropboxImpl) error {
+	ts := oauth.NewTokenSource(clients.HTTPClient(), oauth.Config{
+		TokenURL:     tokenURL,
+		ClientID:     req.GetClientId(),
+		ClientSecret: req.GetClientSecret(),
+		Params: url.Values{
+			"grant_type":    {"refresh_token"},
+			"refresh_token": {req.GetRefreshToken()},
+		},
+	})
+	httpClient := oauth.NewClient(clients.HTTPClient(), ts)
+
+	now := utils.CtxNow(ctx)
+	var cursor string
+	for seq := 0; req.MaxPages == nil || seq < *req.MaxPages; seq++ {
+		next, err := crawl(ctx, clients, httpClient, req, now, seq, cursor)
+		if err != nil {
+			return goerr.Wrap(err, "failed to crawl Dropbox team events").With("seq", seq).With("req", req)
+		}
+		if next == nil {
+			break
+		}
+		cursor = *next
+	}
+
+	return nil
+}
+
+type timeRange struct {
+	StartTime string `json:"start_time"`
+	EndTime   string `json:"end_time"`
+}
+
+type getEventsInput struct {
+	Limit    int       `json:"limit"`
+	Time     timeRange `json:"time"`
+	Category string    `json:"category,omitempty"`
+}
+
+type getEventsContinueInput struct {
+	Cursor string `json:"cursor"`
+}
+
+func crawl(ctx context.Context, clients *infra.Clients, httpClient interfaces.HTTPClient, req *config.DropboxImpl, end time.Time, seq int, cursor string) (*string, error) {
+	apiURL := getEventsContinueURL
+	var input any = getEventsContinueInput{Cursor: cursor}
+	if cursor == "" {
+		start := end.Add(-req.GetDuration().GoDuration())
+		in := getEventsInput{
+			Limit: req.GetLimit(),
+			Time: timeRange{
+				StartTime: start.UTC().Format(time.RFC3339),
+				EndTime:   end.UTC().Format(time.RFC3339),
+			},
+		}
+		if req.Category != nil {
+			in.Category = *req.Category
+		}
+		apiURL, input = getEventsURL, in
+	}
+
+	reqBody, err := json.Marshal(input)
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to marshal request body")
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, apiURL, bytes.NewReader(reqBody))
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to create HTTP request")
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	httpResp, err := httpClient.Do(httpReq)
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to send HTTP request")
+	}
+	defer utils.SafeClose(httpResp.Body)
+
+	if httpResp.StatusCode != http.StatusOK {
+		data, _ := io.ReadAll(httpResp.Body)
+		return nil, goerr.New("unexpected status code").With("status", httpResp.Status).With("body", string(data))
+	}
+
+	body, err := io.ReadAll(httpResp.Body)
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to read response body")
+	}
+
+	var resp apiResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, goerr.Wrap(err, "failed to unmarshal response body")
+	}
+
+	objName := model.DefaultLogObjectName(ctx, req, end, seq)
+	w, err := output.NewLogObjectWriter(ctx, clients.CloudStorage(), req, objName, output.WithWindow(end.Add(-req.GetDuration().GoDuration()), end))
+	if err != nil {
+		return nil, err
+	}
+
+	n, err := io.Copy(w, bytes.NewReader(body))
+	if err != nil {
+		w.Abort()
+		return nil, goerr.Wrap(err, "failed to write response to object writer").With("bytes", n)
+	}
+	if err := w.Close(); err != nil {
+		return nil, goerr.Wrap(err, "failed to close object writer").With("object", objName)
+	}
+
+	utils.CtxLogger(ctx).Info("harvested Dropbox team events", "events", len(resp.Events), "bytes", n, "object", w.Object())
+
+	if resp.HasMore && resp.Cursor != "" {
+		return &resp.Cursor, nil
+	}
+	return nil, nil
+}
+
+type apiResponse struct {
+	Events  []json.RawMessage `json:"events"`
+	Cursor  string            `json:"cursor"`
+	HasMore bool              `json:"has_more"`
+}
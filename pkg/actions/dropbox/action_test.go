@@ -0,0 +1,78 @@
+package dropbox_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/apple/pkl-go/pkl"
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/hatchery/pkg/actions/dropbox"
+	"github.com/m-mizutani/hatchery/pkg/domain/config"
+	"github.com/m-mizutani/hatchery/pkg/domain/model"
+	"github.com/m-mizutani/hatchery/pkg/infra"
+	"github.com/m-mizutani/hatchery/pkg/infra/cs"
+	"github.com/m-mizutani/hatchery/pkg/utils"
+)
+
+func TestDropbox(t *testing.T) {
+	var firstBody map[string]any
+	var cursors []string
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST api.dropboxapi.com/oauth2/token", func(w http.ResponseWriter, r *http.Request) {
+		gt.NoError(t, r.ParseForm())
+		gt.Equal(t, r.PostForm.Get("grant_type"), "refresh_token")
+		gt.Equal(t, r.PostForm.Get("refresh_token"), "test-refresh")
+		_, _ = w.Write([]byte(`{"access_token":"test-token","token_type":"bearer","expires_in":14400}`))
+	})
+	mux.HandleFunc("POST api.dropboxapi.com/2/team_log/get_events", func(w http.ResponseWriter, r *http.Request) {
+		gt.Equal(t, r.Header.Get("Authorization"), "Bearer test-token")
+		gt.NoError(t, json.NewDecoder(r.Body).Decode(&firstBody))
+		_, _ = w.Write([]byte(`{"events":[{"timestamp":"2024-04-01T09:30:00Z"}],"cursor":"cursor-1","has_more":true}`))
+	})
+	mux.HandleFunc("POST api.dropboxapi.com/2/team_log/get_events/continue", func(w http.ResponseWriter, r *http.Request) {
+		gt.Equal(t, r.Header.Get("Authorization"), "Bearer test-token")
+		var body struct {
+			Cursor string `json:"cursor"`
+		}
+		gt.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		cursors = append(cursors, body.Cursor)
+		_, _ = w.Write([]byte(`{"events":[{"timestamp":"2024-04-01T09:40:00Z"}],"cursor":"cursor-2","has_more":false}`))
+	})
+
+	mock := cs.NewMock()
+	clients := infra.New(infra.WithCloudStorage(mock), infra.WithHTTPClient(utils.NewFakeHTTPClient(mux)))
+
+	ctx := context.Background()
+	_, ctx = utils.CtxRequestID(ctx)
+	now := time.Date(2024, 4, 1, 10, 0, 0, 0, time.UTC)
+	ctx = utils.CtxWithNow(ctx, func() time.Time { return now })
+
+	category := "logins"
+	req := &config.DropboxImpl{
+		ClientId:     "test-client",
+		ClientSecret: "test-secret",
+		RefreshToken: "test-refresh",
+		Category:     &category,
+		Duration:     &pkl.Duration{Value: 1, Unit: pkl.Hour},
+		Limit:        1000,
+		Bucket:       "test-bucket",
+	}
+	gt.NoError(t, dropbox.Exec(ctx, clients, req)).Must()
+
+	gt.Equal(t, firstBody["category"], any("logins"))
+	gt.Equal(t, firstBody["time"], any(map[string]any{
+		"start_time": "2024-04-01T09:00:00Z",
+		"end_time":   "2024-04-01T10:00:00Z",
+	}))
+	gt.A(t, cursors).Equal([]string{"cursor-1"})
+	gt.A(t, mock.Results).Length(2).
+		At(0, func(t testing.TB, v *cs.MockResult) {
+			gt.Equal(t, v.Object, model.DefaultLogObjectName(ctx, req, now, 0))
+		}).
+		At(1, func(t testing.TB, v *cs.MockResult) {
+			gt.Equal(t, v.Object, model.DefaultLogObjectName(ctx, req, now, 1))
+		})
+}
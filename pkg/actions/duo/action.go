@@ -0,0 +1,185 @@
+package duo
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/m-mizutani/goerr"
+	"github.com/m-mizutani/hatchery/pkg/domain/config"
+	"github.com/m-mizutani/hatchery/pkg/domain/interfaces"
+	"github.com/m-mizutani/hatchery/pkg/domain/model"
+	"github.com/m-mizutani/hatchery/pkg/domain/types"
+	"github.com/m-mizutani/hatchery/pkg/infra"
+	"github.com/m-mizutani/hatchery/pkg/infra/output"
+	"github.com/m-mizutani/hatchery/pkg/infra/signer"
+	"github.com/m-mizutani/hatchery/pkg/utils"
+)
+
+// v1 log endpoints return up to 1000 records without pagination parameters
+const v1PageSize = 1000
+
+type dataset struct {
+	path string
+	// v2 endpoint accepts mintime/maxtime in milliseconds and paginates with next_offset. v1 endpoint accepts only mintime in seconds.
+	v2 bool
+}
+
+// See https://duo.com/docs/adminapi#logs
+var datasets = map[string]dataset{
+	"authentication": {path: "/admin/v2/logs/authentication", v2: true},
+	"administrator":  {path: "/admin/v1/logs/administrator"},
+	"telephony":      {path: "/admin/v1/logs/telephony"},
+}
+
+func Exec(ctx context.Context, clients *infra.Clients, req *config.DuoImpl) error {
+	httpClient := signer.NewClient(clients.HTTPClient(), &signer.Duo{
+		IntegrationKey: req.GetIntegrationKey(),
+		SecretKey:      req.GetSecretKey(),
+	})
+
+	now := utils.CtxNow(ctx)
+	for _, name := range req.GetDatasets() {
+		ds, ok := datasets[name]
+		if !ok {
+			return goerr.Wrap(types.ErrInvalidOption, "unsupported Duo dataset").With("dataset", name)
+		}
+
+		var offset string
+		for seq := 0; req.MaxPages == nil || seq < *req.MaxPages; seq++ {
+			next, err := crawl(ctx, clients, httpClient, req, name, ds, now, seq, offset)
+			if err != nil {
+				return goerr.Wrap(err, "failed to crawl Duo logs").With("dataset", name).With("seq", seq).With("req", req)
+			}
+			if next == nil {
+				break
+			}
+			offset = *next
+		}
+	}
+
+	return nil
+}
+
+// crawl writes one page of the dataset. offset is next_offset for v2 endpoint, and mintime for v1 endpoint.
+func crawl(ctx context.Context, clients *infra.Clients, httpClient interfaces.HTTPClient, req *config.DuoImpl, name string, ds dataset, end time.Time, seq int, offset string) (*string, error) {
+	start := end.Add(-req.GetDuration().GoDuration())
+
+	qv := url.Values{}
+	if ds.v2 {
+		qv.Add("mintime", fmt.Sprintf("%d", start.UnixMilli()))
+		qv.Add("maxtime", fmt.Sprintf("%d", end.UnixMilli()))
+		qv.Add("limit", fmt.Sprintf("%d", req.GetLimit()))
+		if offset != "" {
+			qv.Add("next_offset", offset)
+		}
+	} else {
+		mintime := fmt.Sprintf("%d", start.Unix())
+		if offset != "" {
+			mintime = offset
+		}
+		qv.Add("mintime", mintime)
+	}
+
+	apiURL := "https://" + req.GetApiHostname() + ds.path + "?" + qv.Encode()
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, apiURL, nil)
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to create HTTP request")
+	}
+
+	httpResp, err := httpClient.Do(httpReq)
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to send HTTP request")
+	}
+	defer utils.SafeClose(httpResp.Body)
+
+	if httpResp.StatusCode != http.StatusOK {
+		data, _ := io.ReadAll(httpResp.Body)
+		return nil, goerr.New("unexpected status code").With("status", httpResp.Status).With("body", string(data))
+	}
+
+	body, err := io.ReadAll(httpResp.Body)
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to read response body")
+	}
+
+	var resp apiResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, goerr.Wrap(err, "failed to unmarshal response body")
+	}
+	if resp.Stat != "OK" {
+		return nil, goerr.New("Duo API returned error").With("stat", resp.Stat).With("body", string(body))
+	}
+
+	var logs int
+	var next *string
+	if ds.v2 {
+		var v2 v2Response
+		if err := json.Unmarshal(resp.Response, &v2); err != nil {
+			return nil, goerr.Wrap(err, "failed to unmarshal v2 response")
+		}
+		logs = len(v2.AuthLogs)
+		if len(v2.Metadata.NextOffset) > 0 {
+			s := strings.Join(v2.Metadata.NextOffset, ",")
+			next = &s
+		}
+	} else {
+		var v1 []v1Log
+		if err := json.Unmarshal(resp.Response, &v1); err != nil {
+			return nil, goerr.Wrap(err, "failed to unmarshal v1 response")
+		}
+		logs = len(v1)
+		// Records of the same second as the last one may be returned again, but it's safer than dropping them
+		if len(v1) >= v1PageSize {
+			s := fmt.Sprintf("%d", v1[len(v1)-1].Timestamp)
+			if s != offset {
+				next = &s
+			}
+		}
+	}
+
+	if logs == 0 {
+		return nil, nil
+	}
+
+	objName := model.DatasetLogObjectName(ctx, req, name, end, seq)
+	w, err := output.NewLogObjectWriter(ctx, clients.CloudStorage(), req, objName, output.WithWindow(start, end))
+	if err != nil {
+		return nil, err
+	}
+
+	n, err := io.Copy(w, bytes.NewReader(body))
+	if err != nil {
+		w.Abort()
+		return nil, goerr.Wrap(err, "failed to write response to object writer").With("bytes", n)
+	}
+	if err := w.Close(); err != nil {
+		return nil, goerr.Wrap(err, "failed to close object writer").With("object", objName)
+	}
+
+	utils.CtxLogger(ctx).Info("harvested Duo logs", "dataset", name, "logs", logs, "bytes", n, "object", w.Object())
+
+	return next, nil
+}
+
+type apiResponse struct {
+	Stat     string          `json:"stat"`
+	Response json.RawMessage `json:"response"`
+}
+
+type v2Response struct {
+	AuthLogs []json.RawMessage `json:"authlogs"`
+	Metadata struct {
+		NextOffset []string `json:"next_offset"`
+	} `json:"metadata"`
+}
+
+type v1Log struct {
+	Timestamp int64 `json:"timestamp"`
+}
@@ -0,0 +1,85 @@
+package duo_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/apple/pkl-go/pkl"
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/hatchery/pkg/actions/duo"
+	"github.com/m-mizutani/hatchery/pkg/domain/config"
+	"github.com/m-mizutani/hatchery/pkg/domain/model"
+	"github.com/m-mizutani/hatchery/pkg/infra"
+	"github.com/m-mizutani/hatchery/pkg/infra/cs"
+	"github.com/m-mizutani/hatchery/pkg/utils"
+)
+
+func TestDuo(t *testing.T) {
+	checkAuth := func(t *testing.T, r *http.Request) {
+		user, _, ok := r.BasicAuth()
+		gt.Equal(t, ok, true)
+		gt.Equal(t, user, "DIXXXXXXXXXXXXXXXXXX")
+		gt.Equal(t, r.Header.Get("Date"), "Mon, 01 Apr 2024 10:00:00 +0000")
+	}
+
+	var authQueries []string
+	var adminQueries []string
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET api-test.duosecurity.com/admin/v2/logs/authentication", func(w http.ResponseWriter, r *http.Request) {
+		checkAuth(t, r)
+		authQueries = append(authQueries, r.URL.RawQuery)
+		if r.URL.Query().Get("next_offset") == "" {
+			_, _ = w.Write([]byte(`{"stat":"OK","response":{"authlogs":[{"txid":"a"}],"metadata":{"next_offset":["1711962000000","a"],"total_objects":2}}}`))
+		} else {
+			_, _ = w.Write([]byte(`{"stat":"OK","response":{"authlogs":[{"txid":"b"}],"metadata":{}}}`))
+		}
+	})
+	mux.HandleFunc("GET api-test.duosecurity.com/admin/v1/logs/administrator", func(w http.ResponseWriter, r *http.Request) {
+		checkAuth(t, r)
+		adminQueries = append(adminQueries, r.URL.RawQuery)
+		_, _ = w.Write([]byte(`{"stat":"OK","response":[{"action":"admin_login","timestamp":1711962100}]}`))
+	})
+	mux.HandleFunc("GET api-test.duosecurity.com/admin/v1/logs/telephony", func(w http.ResponseWriter, r *http.Request) {
+		checkAuth(t, r)
+		_, _ = w.Write([]byte(`{"stat":"OK","response":[]}`))
+	})
+
+	mock := cs.NewMock()
+	clients := infra.New(infra.WithCloudStorage(mock), infra.WithHTTPClient(utils.NewFakeHTTPClient(mux)))
+
+	ctx := context.Background()
+	_, ctx = utils.CtxRequestID(ctx)
+	now := time.Date(2024, 4, 1, 10, 0, 0, 0, time.UTC)
+	ctx = utils.CtxWithNow(ctx, func() time.Time { return now })
+
+	req := &config.DuoImpl{
+		IntegrationKey: "DIXXXXXXXXXXXXXXXXXX",
+		SecretKey:      "test-secret",
+		ApiHostname:    "api-test.duosecurity.com",
+		Datasets:       []string{"authentication", "administrator", "telephony"},
+		Duration:       &pkl.Duration{Value: 1, Unit: pkl.Hour},
+		Limit:          1000,
+		Bucket:         "test-bucket",
+	}
+	gt.NoError(t, duo.Exec(ctx, clients, req)).Must()
+
+	gt.A(t, authQueries).Equal([]string{
+		"limit=1000&maxtime=1711965600000&mintime=1711962000000",
+		"limit=1000&maxtime=1711965600000&mintime=1711962000000&next_offset=1711962000000%2Ca",
+	})
+	gt.A(t, adminQueries).Equal([]string{"mintime=1711962000"})
+
+	// Empty telephony logs are not written
+	gt.A(t, mock.Results).Length(3).
+		At(0, func(t testing.TB, v *cs.MockResult) {
+			gt.Equal(t, v.Object, model.DatasetLogObjectName(ctx, req, "authentication", now, 0))
+		}).
+		At(1, func(t testing.TB, v *cs.MockResult) {
+			gt.Equal(t, v.Object, model.DatasetLogObjectName(ctx, req, "authentication", now, 1))
+		}).
+		At(2, func(t testing.TB, v *cs.MockResult) {
+			gt.Equal(t, v.Object, model.DatasetLogObjectName(ctx, req, "administrator", now, 0))
+		})
+}
@@ -0,0 +1,158 @@
+package entra_id
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/m-mizutani/goerr"
+	"github.com/m-mizutani/hatchery/pkg/domain/config"
+	"github.com/m-mizutani/hatchery/pkg/domain/interfaces"
+	"github.com/m-mizutani/hatchery/pkg/domain/model"
+	"github.com/m-mizutani/hatchery/pkg/domain/types"
+	"github.com/m-mizutani/hatchery/pkg/infra"
+	"github.com/m-mizutani/hatchery/pkg/infra/entra"
+	"github.com/m-mizutani/hatchery/pkg/infra/oauth"
+	"github.com/m-mizutani/hatchery/pkg/infra/output"
+	"github.com/m-mizutani/hatchery/pkg/utils"
+)
+
+const (
+	// Microsoft Graph API endpoint
+	baseURL    = "https://graph.microsoft.com/v1.0"
+	tokenScope = "https://graph.microsoft.com/.default"
+
+	// Time format for $filter of Microsoft Graph (UTC)
+	timeFormat = "2006-01-02T15:04:05Z"
+)
+
+// dataset is a Microsoft Graph audit log resource. timeField is the property to filter by the window; directoryAudits has activityDateTime instead of createdDateTime.
+type dataset struct {
+	path      string
+	timeField string
+}
+
+var datasets = map[string]dataset{
+	"sign_ins": {
+		path:      "/auditLogs/signIns",
+		timeField: "createdDateTime",
+	},
+	"directory_audits": {
+		path:      "/auditLogs/directoryAudits",
+		timeField: "activityDateTime",
+	},
+}
+
+func Exec(ctx context.Context, clients *infra.Clients, req *config.EntraIDImpl) error {
+	now := utils.CtxNow(ctx).UTC()
+
+	cred, err := newCredential(clients, req)
+	if err != nil {
+		return goerr.Wrap(err, "failed to create Entra ID credential").With("req", req)
+	}
+	httpClient := oauth.NewClient(clients.HTTPClient(), cred.TokenSource(tokenScope))
+
+	for _, name := range req.GetDatasets() {
+		ds, ok := datasets[name]
+		if !ok {
+			return goerr.Wrap(types.ErrInvalidOption, "unknown dataset of EntraID").With("dataset", name)
+		}
+
+		nextURL := firstPageURL(req, ds, now)
+		for seq := 0; req.MaxPages == nil || seq < *req.MaxPages; seq++ {
+			next, err := crawl(ctx, clients, req, httpClient, name, now, seq, nextURL)
+			if err != nil {
+				return goerr.Wrap(err, "failed to crawl Entra ID audit logs").With("dataset", name).With("seq", seq).With("req", req)
+			}
+			if next == nil {
+				break
+			}
+			nextURL = *next
+		}
+	}
+
+	return nil
+}
+
+func newCredential(clients *infra.Clients, req *config.EntraIDImpl) (*entra.Credential, error) {
+	switch {
+	case req.ClientCertificate != nil && req.ClientPrivateKey != nil:
+		return entra.NewCertificateCredential(clients.HTTPClient(), req.TenantId, req.ClientId,
+			[]byte(*req.ClientCertificate), []byte(*req.ClientPrivateKey))
+	case req.ClientSecret != nil:
+		return entra.NewSecretCredential(clients.HTTPClient(), req.TenantId, req.ClientId, *req.ClientSecret), nil
+	default:
+		return nil, goerr.Wrap(types.ErrInvalidOption, "either client_secret or client_certificate and client_private_key is required")
+	}
+}
+
+func firstPageURL(req *config.EntraIDImpl, ds dataset, end time.Time) string {
+	start := end.Add(-req.GetDuration().GoDuration())
+
+	qv := url.Values{}
+	qv.Add("$filter", fmt.Sprintf("%s ge %s and %s lt %s",
+		ds.timeField, start.Format(timeFormat),
+		ds.timeField, end.Format(timeFormat),
+	))
+	qv.Add("$top", fmt.Sprintf("%d", req.GetLimit()))
+	return baseURL + ds.path + "?" + qv.Encode()
+}
+
+func crawl(ctx context.Context, clients *infra.Clients, req *config.EntraIDImpl, httpClient interfaces.HTTPClient, name string, end time.Time, seq int, pageURL string) (*string, error) {
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, pageURL, nil)
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to create HTTP request")
+	}
+
+	httpResp, err := httpClient.Do(httpReq)
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to send HTTP request")
+	}
+	defer utils.SafeClose(httpResp.Body)
+
+	if httpResp.StatusCode != http.StatusOK {
+		data, _ := io.ReadAll(httpResp.Body)
+		return nil, goerr.New("unexpected status code").With("status", httpResp.Status).With("body", string(data))
+	}
+
+	body, err := io.ReadAll(httpResp.Body)
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to read response body")
+	}
+
+	var resp apiResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, goerr.Wrap(err, "failed to unmarshal response body")
+	}
+
+	objName := model.DatasetLogObjectName(ctx, req, name, end, seq)
+	w, err := output.NewLogObjectWriter(ctx, clients.CloudStorage(), req, objName, output.WithWindow(end.Add(-req.GetDuration().GoDuration()), end))
+	if err != nil {
+		return nil, err
+	}
+
+	n, err := io.Copy(w, bytes.NewReader(body))
+	if err != nil {
+		w.Abort()
+		return nil, goerr.Wrap(err, "failed to write response to object writer").With("bytes", n)
+	}
+	if err := w.Close(); err != nil {
+		return nil, goerr.Wrap(err, "failed to close object writer").With("object", objName)
+	}
+
+	utils.CtxLogger(ctx).Info("harvested Entra ID audit logs", "dataset", name, "bytes", n, "object", w.Object())
+
+	if resp.NextLink != "" {
+		return &resp.NextLink, nil
+	}
+	return nil, nil
+}
+
+type apiResponse struct {
+	NextLink string `json:"@odata.nextLink"`
+}
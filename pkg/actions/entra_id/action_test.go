@@ -0,0 +1,89 @@
+package entra_id_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/apple/pkl-go/pkl"
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/hatchery/pkg/actions/entra_id"
+	"github.com/m-mizutani/hatchery/pkg/domain/config"
+	"github.com/m-mizutani/hatchery/pkg/domain/model"
+	"github.com/m-mizutani/hatchery/pkg/domain/types"
+	"github.com/m-mizutani/hatchery/pkg/infra"
+	"github.com/m-mizutani/hatchery/pkg/infra/cs"
+	"github.com/m-mizutani/hatchery/pkg/utils"
+)
+
+func TestEntraID(t *testing.T) {
+	var tokenCalled int
+	var filters []string
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST login.microsoftonline.com/{tenant}/oauth2/v2.0/token", func(w http.ResponseWriter, r *http.Request) {
+		tokenCalled++
+		gt.NoError(t, r.ParseForm())
+		gt.Equal(t, r.PostForm.Get("scope"), "https://graph.microsoft.com/.default")
+		_, _ = w.Write([]byte(`{"access_token":"test-token","expires_in":3600}`))
+	})
+	mux.HandleFunc("GET graph.microsoft.com/v1.0/auditLogs/signIns", func(w http.ResponseWriter, r *http.Request) {
+		gt.Equal(t, r.Header.Get("Authorization"), "Bearer test-token")
+		if r.URL.Query().Get("$skiptoken") == "" {
+			filters = append(filters, r.URL.Query().Get("$filter"))
+			_, _ = w.Write([]byte(`{"value":[{"id":"1"}],"@odata.nextLink":"https://graph.microsoft.com/v1.0/auditLogs/signIns?$skiptoken=abc"}`))
+		} else {
+			_, _ = w.Write([]byte(`{"value":[{"id":"2"}]}`))
+		}
+	})
+	mux.HandleFunc("GET graph.microsoft.com/v1.0/auditLogs/directoryAudits", func(w http.ResponseWriter, r *http.Request) {
+		filters = append(filters, r.URL.Query().Get("$filter"))
+		_, _ = w.Write([]byte(`{"value":[{"id":"3"}]}`))
+	})
+
+	mock := cs.NewMock()
+	clients := infra.New(infra.WithCloudStorage(mock), infra.WithHTTPClient(utils.NewFakeHTTPClient(mux)))
+
+	ctx := context.Background()
+	_, ctx = utils.CtxRequestID(ctx)
+	now := time.Date(2024, 4, 1, 10, 0, 0, 0, time.UTC)
+	ctx = utils.CtxWithNow(ctx, func() time.Time { return now })
+
+	secret := "test-secret"
+	req := &config.EntraIDImpl{
+		TenantId:     "00000000-0000-0000-0000-000000000001",
+		ClientId:     "00000000-0000-0000-0000-000000000002",
+		ClientSecret: &secret,
+		Datasets:     []string{"sign_ins", "directory_audits"},
+		Duration:     &pkl.Duration{Value: 1, Unit: pkl.Hour},
+		Limit:        100,
+		Bucket:       "test-bucket",
+	}
+	gt.NoError(t, entra_id.Exec(ctx, clients, req)).Must()
+
+	gt.Equal(t, tokenCalled, 1)
+	gt.A(t, filters).Equal([]string{
+		"createdDateTime ge 2024-04-01T09:00:00Z and createdDateTime lt 2024-04-01T10:00:00Z",
+		"activityDateTime ge 2024-04-01T09:00:00Z and activityDateTime lt 2024-04-01T10:00:00Z",
+	})
+	gt.A(t, mock.Results).Length(3).
+		At(0, func(t testing.TB, v *cs.MockResult) {
+			gt.Equal(t, v.Object, model.DatasetLogObjectName(ctx, req, "sign_ins", now, 0))
+		}).
+		At(1, func(t testing.TB, v *cs.MockResult) {
+			gt.Equal(t, v.Object, model.DatasetLogObjectName(ctx, req, "sign_ins", now, 1))
+		}).
+		At(2, func(t testing.TB, v *cs.MockResult) {
+			gt.Equal(t, v.Object, model.DatasetLogObjectName(ctx, req, "directory_audits", now, 0))
+		})
+}
+
+func TestEntraIDNoCredential(t *testing.T) {
+	req := &config.EntraIDImpl{
+		Datasets: []string{"sign_ins"},
+		Duration: &pkl.Duration{Value: 1, Unit: pkl.Hour},
+	}
+	err := entra_id.Exec(context.Background(), infra.New(), req)
+	gt.Error(t, err).Is(types.ErrInvalidOption)
+}
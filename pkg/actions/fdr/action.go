@@ -1,22 +1,24 @@
 package fdr
 
 import (
+	"compress/gzip"
 	"context"
 	"encoding/json"
 	"errors"
 	"io"
+	"strings"
 
-	"github.com/aws/aws-sdk-go/aws"
-	"github.com/aws/aws-sdk-go/aws/credentials"
-	"github.com/aws/aws-sdk-go/aws/session"
-	"github.com/aws/aws-sdk-go/service/s3"
-	"github.com/aws/aws-sdk-go/service/sqs"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
 	"github.com/m-mizutani/goerr"
 	"github.com/m-mizutani/hatchery/pkg/domain/config"
 	"github.com/m-mizutani/hatchery/pkg/domain/interfaces"
 	"github.com/m-mizutani/hatchery/pkg/domain/model"
 	"github.com/m-mizutani/hatchery/pkg/domain/types"
 	"github.com/m-mizutani/hatchery/pkg/infra"
+	"github.com/m-mizutani/hatchery/pkg/infra/awscred"
+	"github.com/m-mizutani/hatchery/pkg/infra/output"
 	"github.com/m-mizutani/hatchery/pkg/utils"
 )
 
@@ -43,23 +45,24 @@ type fdrClients struct {
 }
 
 func Exec(ctx context.Context, clients *infra.Clients, req *config.FalconDataReplicatorImpl) error {
-	// Create an AWS session
-	awsSession, err := session.NewSession(&aws.Config{
-		Region: aws.String(req.AwsRegion),
-		Credentials: credentials.NewCredentials(&credentials.StaticProvider{
-			Value: credentials.Value{
-				AccessKeyID:     req.AwsAccessKeyId,
-				SecretAccessKey: req.AwsSecretAccessKey,
-			},
-		}),
-	})
+	// Create an AWS config
+	awsCfg, err := awscred.LoadConfig(ctx, req.AwsRegion, req.AwsCredential)
 	if err != nil {
-		return goerr.Wrap(err, "failed to create AWS session").With("req", req)
+		return goerr.Wrap(err, "failed to create AWS config").With("req", req)
 	}
 
 	// Create AWS service clients
-	sqsClient := clients.NewSQS(awsSession)
-	s3Client := clients.NewS3(awsSession)
+	sqsClient := clients.NewSQS(awsCfg, func(o *sqs.Options) {
+		if endpoint := serviceEndpoint(req.SqsEndpoint, req.AwsEndpoint); endpoint != nil {
+			o.BaseEndpoint = endpoint
+		}
+	})
+	s3Client := clients.NewS3(awsCfg, func(o *s3.Options) {
+		if endpoint := serviceEndpoint(req.S3Endpoint, req.AwsEndpoint); endpoint != nil {
+			o.BaseEndpoint = endpoint
+		}
+		o.UsePathStyle = req.AwsUsePathStyle
+	})
 	prefix := model.LogObjNamePrefix(req, utils.CtxNow(ctx))
 
 	// Receive messages from SQS queue
@@ -67,7 +70,7 @@ func Exec(ctx context.Context, clients *infra.Clients, req *config.FalconDataRep
 		QueueUrl: aws.String(req.SqsUrl),
 	}
 	if req.MaxMessages != nil {
-		input.MaxNumberOfMessages = aws.Int64(int64(*req.MaxMessages))
+		input.MaxNumberOfMessages = int32(*req.MaxMessages)
 	}
 
 	for i := 0; ; i++ {
@@ -76,7 +79,7 @@ func Exec(ctx context.Context, clients *infra.Clients, req *config.FalconDataRep
 		}
 
 		c := &fdrClients{infra: clients, sqs: sqsClient, s3: s3Client}
-		if err := copy(ctx, c, input, types.CSBucket(req.Bucket), prefix); err != nil {
+		if err := copy(ctx, c, input, req, prefix); err != nil {
 			if err == errNoMoreMessage {
 				break
 			}
@@ -87,12 +90,22 @@ func Exec(ctx context.Context, clients *infra.Clients, req *config.FalconDataRep
 	return nil
 }
 
+// serviceEndpoint returns service specific endpoint if specified, otherwise common AWS endpoint. nil means default AWS endpoint.
+func serviceEndpoint(service, common *string) *string {
+	if service != nil {
+		return service
+	}
+	return common
+}
+
 var (
 	errNoMoreMessage = errors.New("no more message")
 )
 
-func copy(ctx context.Context, clients *fdrClients, input *sqs.ReceiveMessageInput, bucket types.CSBucket, prefix types.CSObjectName) error {
-	result, err := clients.sqs.ReceiveMessageWithContext(ctx, input)
+func copy(ctx context.Context, clients *fdrClients, input *sqs.ReceiveMessageInput, req *config.FalconDataReplicatorImpl, prefix types.CSObjectName) error {
+	bucket := types.CSBucket(req.Bucket)
+
+	result, err := clients.sqs.ReceiveMessage(ctx, input)
 	if err != nil {
 		return goerr.Wrap(err, "failed to receive messages from SQS").With("input", input)
 	}
@@ -114,27 +127,42 @@ func copy(ctx context.Context, clients *fdrClients, input *sqs.ReceiveMessageInp
 				Bucket: aws.String(msg.Bucket),
 				Key:    aws.String(file.Path),
 			}
-			s3Obj, err := clients.s3.GetObjectWithContext(ctx, s3Input)
+			s3Obj, err := clients.s3.GetObject(ctx, s3Input)
 			if err != nil {
 				return goerr.Wrap(err, "failed to download object from S3").With("msg", msg)
 			}
 			defer utils.SafeClose(s3Obj.Body)
 
-			csObj := prefix + types.CSObjectName(file.Path)
-			w := clients.infra.CloudStorage().NewObjectWriter(ctx, bucket, csObj)
-
-			if _, err := io.Copy(w, s3Obj.Body); err != nil {
-				return goerr.Wrap(err, "failed to write object to GCS").With("msg", msg)
-			}
-			if err := w.Close(); err != nil {
-				return goerr.Wrap(err, "failed to close object writer").With("msg", msg)
+			csObj := prefix + types.CSObjectName(file.Path+model.EncryptionExt(req.Encryption))
+			if req.Transcode {
+				csObj = prefix + types.CSObjectName(strings.TrimSuffix(file.Path, ".gz")+model.ObjectExt(req))
+				if err := transcode(ctx, clients.infra.CloudStorage(), req, csObj, s3Obj.Body); err != nil {
+					return goerr.Wrap(err, "failed to transcode object").With("msg", msg)
+				}
+			} else {
+				opts := append(output.ObjectOptions(ctx, req),
+					types.WithContentType(output.ContentTypeNDJSON),
+					types.WithContentEncoding("gzip"),
+				)
+				w := clients.infra.CloudStorage().NewObjectWriter(ctx, bucket, csObj, opts...)
+				if _, err := io.Copy(w, s3Obj.Body); err != nil {
+					utils.SafeAbort(w)
+					return goerr.Wrap(err, "failed to write object to GCS").With("msg", msg)
+				}
+				if err := w.Close(); err != nil {
+					if !errors.Is(err, types.ErrObjectExists) {
+						return goerr.Wrap(err, "failed to close object writer").With("msg", msg)
+					}
+					utils.CtxLogger(ctx).Info("FDR: object already exists, skipped", "gcsObj", csObj)
+					continue
+				}
 			}
 
 			utils.CtxLogger(ctx).Info("FDR: object forwarded from S3 to GCS", "s3", s3Input, "gcsObj", csObj)
 		}
 
 		// Delete the message from SQS
-		_, err = clients.sqs.DeleteMessageWithContext(ctx, &sqs.DeleteMessageInput{
+		_, err = clients.sqs.DeleteMessage(ctx, &sqs.DeleteMessageInput{
 			QueueUrl:      input.QueueUrl,
 			ReceiptHandle: message.ReceiptHandle,
 		})
@@ -145,3 +173,25 @@ func copy(ctx context.Context, clients *fdrClients, input *sqs.ReceiveMessageInp
 
 	return nil
 }
+
+// transcode decompresses gzip data of FDR and writes it into the object with compression of the action.
+func transcode(ctx context.Context, storage interfaces.CloudStorage, req *config.FalconDataReplicatorImpl, csObj types.CSObjectName, src io.Reader) error {
+	r, err := gzip.NewReader(src)
+	if err != nil {
+		return goerr.Wrap(err, "failed to create gzip reader")
+	}
+	defer utils.SafeClose(r)
+
+	w, err := output.NewObjectWriter(ctx, storage, req, csObj, types.WithContentType(output.ContentTypeNDJSON))
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(w, r); err != nil {
+		w.Abort()
+		return goerr.Wrap(err, "failed to write object").With("object", csObj)
+	}
+	if err := w.Close(); err != nil {
+		return goerr.Wrap(err, "failed to close object writer").With("object", csObj)
+	}
+	return nil
+}
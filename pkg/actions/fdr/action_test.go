@@ -2,47 +2,51 @@ package fdr_test
 
 import (
 	"bytes"
+	"compress/gzip"
 	"context"
 	_ "embed"
 	"errors"
+	"fmt"
 	"io"
 	"testing"
 	"time"
 
-	"github.com/aws/aws-sdk-go/aws"
-	"github.com/aws/aws-sdk-go/aws/request"
-	"github.com/aws/aws-sdk-go/aws/session"
-	"github.com/aws/aws-sdk-go/service/s3"
-	"github.com/aws/aws-sdk-go/service/sqs"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	sqsTypes "github.com/aws/aws-sdk-go-v2/service/sqs/types"
+	"github.com/google/uuid"
 	"github.com/m-mizutani/gt"
 	"github.com/m-mizutani/hatchery/pkg/actions/fdr"
 	"github.com/m-mizutani/hatchery/pkg/domain/config"
 	"github.com/m-mizutani/hatchery/pkg/domain/interfaces"
 	"github.com/m-mizutani/hatchery/pkg/infra"
+	"github.com/m-mizutani/hatchery/pkg/infra/awscred"
 	"github.com/m-mizutani/hatchery/pkg/infra/cs"
+	"github.com/m-mizutani/hatchery/pkg/infra/output"
 	"github.com/m-mizutani/hatchery/pkg/utils"
 )
 
 type mockSQS struct {
-	FnDeleteMessage  func(ctx context.Context, input *sqs.DeleteMessageInput, opts ...request.Option) (*sqs.DeleteMessageOutput, error)
-	FnReceiveMessage func(ctx context.Context, input *sqs.ReceiveMessageInput, opts ...request.Option) (*sqs.ReceiveMessageOutput, error)
+	FnDeleteMessage  func(ctx context.Context, input *sqs.DeleteMessageInput, optFns ...func(*sqs.Options)) (*sqs.DeleteMessageOutput, error)
+	FnReceiveMessage func(ctx context.Context, input *sqs.ReceiveMessageInput, optFns ...func(*sqs.Options)) (*sqs.ReceiveMessageOutput, error)
 	messages         []*sqs.ReceiveMessageOutput
 }
 
-// DeleteMessageWithContext implements interfaces.SQS.
-func (m *mockSQS) DeleteMessageWithContext(ctx context.Context, input *sqs.DeleteMessageInput, opts ...request.Option) (*sqs.DeleteMessageOutput, error) {
-	return m.FnDeleteMessage(ctx, input, opts...)
+// DeleteMessage implements interfaces.SQS.
+func (m *mockSQS) DeleteMessage(ctx context.Context, input *sqs.DeleteMessageInput, optFns ...func(*sqs.Options)) (*sqs.DeleteMessageOutput, error) {
+	return m.FnDeleteMessage(ctx, input, optFns...)
 }
 
-// ReceiveMessageWithContext implements interfaces.SQS.
-func (m *mockSQS) ReceiveMessageWithContext(ctx context.Context, input *sqs.ReceiveMessageInput, opts ...request.Option) (*sqs.ReceiveMessageOutput, error) {
+// ReceiveMessage implements interfaces.SQS.
+func (m *mockSQS) ReceiveMessage(ctx context.Context, input *sqs.ReceiveMessageInput, optFns ...func(*sqs.Options)) (*sqs.ReceiveMessageOutput, error) {
 	if m.FnReceiveMessage != nil {
-		return m.FnReceiveMessage(ctx, input, opts...)
+		return m.FnReceiveMessage(ctx, input, optFns...)
 	}
 
 	if len(m.messages) == 0 {
 		return &sqs.ReceiveMessageOutput{
-			Messages: []*sqs.Message{},
+			Messages: []sqsTypes.Message{},
 		}, nil
 	}
 
@@ -57,8 +61,8 @@ type mockS3 struct {
 	DataSet [][]byte
 }
 
-// GetObjectWithContext implements interfaces.S3.
-func (m *mockS3) GetObjectWithContext(ctx context.Context, input *s3.GetObjectInput, opts ...request.Option) (*s3.GetObjectOutput, error) {
+// GetObject implements interfaces.S3.
+func (m *mockS3) GetObject(ctx context.Context, input *s3.GetObjectInput, optFns ...func(*s3.Options)) (*s3.GetObjectOutput, error) {
 	if len(m.DataSet) == 0 {
 		return nil, errors.New("no data")
 	}
@@ -70,6 +74,11 @@ func (m *mockS3) GetObjectWithContext(ctx context.Context, input *s3.GetObjectIn
 	}, nil
 }
 
+// PutObject implements interfaces.S3.
+func (m *mockS3) PutObject(ctx context.Context, input *s3.PutObjectInput, optFns ...func(*s3.Options)) (*s3.PutObjectOutput, error) {
+	return nil, errors.New("not implemented")
+}
+
 var _ interfaces.S3 = &mockS3{}
 
 //go:embed testdata/body.json
@@ -80,23 +89,23 @@ func TestFalconDataReplicator(t *testing.T) {
 
 	mockCS := cs.NewMock()
 	mockSQS := &mockSQS{
-		FnDeleteMessage: func(ctx context.Context, input *sqs.DeleteMessageInput, opts ...request.Option) (*sqs.DeleteMessageOutput, error) {
+		FnDeleteMessage: func(ctx context.Context, input *sqs.DeleteMessageInput, optFns ...func(*sqs.Options)) (*sqs.DeleteMessageOutput, error) {
 			calledDelete++
 			gt.Equal(t, "test-sqs-url", *input.QueueUrl)
 			gt.Equal(t, "test-receipt-handle", *input.ReceiptHandle)
 			return nil, nil
 		},
-		FnReceiveMessage: func(ctx context.Context, input *sqs.ReceiveMessageInput, opts ...request.Option) (*sqs.ReceiveMessageOutput, error) {
+		FnReceiveMessage: func(ctx context.Context, input *sqs.ReceiveMessageInput, optFns ...func(*sqs.Options)) (*sqs.ReceiveMessageOutput, error) {
 			calledRecv++
 			gt.Equal(t, "test-sqs-url", *input.QueueUrl)
 
 			if calledRecv > 1 {
 				return &sqs.ReceiveMessageOutput{
-					Messages: []*sqs.Message{},
+					Messages: []sqsTypes.Message{},
 				}, nil
 			}
 			return &sqs.ReceiveMessageOutput{
-				Messages: []*sqs.Message{
+				Messages: []sqsTypes.Message{
 					{
 						Body:          &bodyJSON,
 						ReceiptHandle: aws.String("test-receipt-handle"),
@@ -113,18 +122,20 @@ func TestFalconDataReplicator(t *testing.T) {
 	}
 	clients := infra.New(
 		infra.WithCloudStorage(mockCS),
-		infra.WithNewSQS(func(s *session.Session) interfaces.SQS { return mockSQS }),
-		infra.WithNewS3(func(s *session.Session) interfaces.S3 { return mockS3 }),
+		infra.WithNewSQS(func(cfg aws.Config, optFns ...func(*sqs.Options)) interfaces.SQS { return mockSQS }),
+		infra.WithNewS3(func(cfg aws.Config, optFns ...func(*s3.Options)) interfaces.S3 { return mockS3 }),
 	)
 
 	now := time.Date(2021, 9, 1, 2, 3, 0, 0, time.UTC)
 	ctx := utils.CtxWithNow(context.Background(), func() time.Time { return now })
 	gt.NoError(t, fdr.Exec(ctx, clients, &config.FalconDataReplicatorImpl{
-		AwsRegion:          "us-west-2",
-		Bucket:             "test-bucket",
-		AwsAccessKeyId:     "test-access-key",
-		AwsSecretAccessKey: "test-secret",
-		SqsUrl:             "test-sqs-url",
+		AwsRegion: "us-west-2",
+		Bucket:    "test-bucket",
+		AwsCredential: &config.AWSStaticCredentialImpl{
+			AccessKeyId:     "test-access-key",
+			SecretAccessKey: "test-secret",
+		},
+		SqsUrl: "test-sqs-url",
 	}))
 	gt.V(t, calledDelete).Equal(1)
 	gt.V(t, calledRecv).Equal(2)
@@ -138,3 +149,111 @@ func TestFalconDataReplicator(t *testing.T) {
 			gt.Equal(t, v.Object, "logs/2021/09/01/02/dAnpZeYcYD1J1B00-9f25c8f9/data/C246521D-D19E-43DD-9EB9-4EEE07F53D5A/part-00001.gz")
 		})
 }
+
+func TestFalconDataReplicatorTranscode(t *testing.T) {
+	var src bytes.Buffer
+	gw := gzip.NewWriter(&src)
+	_ = gt.R1(gw.Write([]byte("test-data"))).NoError(t)
+	gt.NoError(t, gw.Close())
+
+	mockCS := cs.NewMock()
+	mockSQS := &mockSQS{
+		FnDeleteMessage: func(ctx context.Context, input *sqs.DeleteMessageInput, optFns ...func(*sqs.Options)) (*sqs.DeleteMessageOutput, error) {
+			return nil, nil
+		},
+		messages: []*sqs.ReceiveMessageOutput{
+			{
+				Messages: []sqsTypes.Message{
+					{
+						Body:          aws.String(`{"bucket":"src-bucket","files":[{"path":"data/part-00000.gz"}]}`),
+						ReceiptHandle: aws.String("test-receipt-handle"),
+					},
+				},
+			},
+		},
+	}
+	mockS3 := &mockS3{DataSet: [][]byte{src.Bytes()}}
+	clients := infra.New(
+		infra.WithCloudStorage(mockCS),
+		infra.WithNewSQS(func(cfg aws.Config, optFns ...func(*sqs.Options)) interfaces.SQS { return mockSQS }),
+		infra.WithNewS3(func(cfg aws.Config, optFns ...func(*s3.Options)) interfaces.S3 { return mockS3 }),
+	)
+
+	now := time.Date(2021, 9, 1, 2, 3, 0, 0, time.UTC)
+	ctx := utils.CtxWithNow(context.Background(), func() time.Time { return now })
+	req := &config.FalconDataReplicatorImpl{
+		AwsRegion: "us-west-2",
+		Bucket:    "test-bucket",
+		AwsCredential: &config.AWSStaticCredentialImpl{
+			AccessKeyId:     "test-access-key",
+			SecretAccessKey: "test-secret",
+		},
+		SqsUrl:      "test-sqs-url",
+		Transcode:   true,
+		Compression: &config.ZstdImpl{Level: "default"},
+	}
+	gt.NoError(t, fdr.Exec(ctx, clients, req))
+
+	gt.A(t, mockCS.Results).Length(1).At(0, func(t testing.TB, v *cs.MockResult) {
+		gt.S(t, string(v.Object)).HasSuffix("/data/part-00000.zst")
+		r := gt.R1(output.NewDecoder(bytes.NewReader(v.Body.Bytes()), req.Compression)).NoError(t)
+		gt.Equal(t, string(gt.R1(io.ReadAll(r)).NoError(t)), "test-data")
+	})
+}
+
+// TestIntegration runs FDR flow end to end against AWS compatible services such as LocalStack. TEST_FDR_AWS_ENDPOINT is an endpoint of both SQS and S3 (e.g. http://localhost:4566).
+func TestIntegration(t *testing.T) {
+	endpoint := utils.LoadEnv(t, "TEST_FDR_AWS_ENDPOINT")
+	ctx := context.Background()
+
+	cred := &config.AWSStaticCredentialImpl{
+		AccessKeyId:     "TESTACCESSKEYID0",
+		SecretAccessKey: "test-secret",
+	}
+	awsCfg := gt.R1(awscred.LoadConfig(ctx, "us-east-1", cred)).NoError(t)
+	sqsClient := sqs.NewFromConfig(awsCfg, func(o *sqs.Options) {
+		o.BaseEndpoint = aws.String(endpoint)
+	})
+	s3Client := s3.NewFromConfig(awsCfg, func(o *s3.Options) {
+		o.BaseEndpoint = aws.String(endpoint)
+		o.UsePathStyle = true
+	})
+
+	// Prepare source bucket, object and queue
+	id := uuid.NewString()
+	srcBucket := "hatchery-test-" + id
+	srcKey := "data/" + id + "/part-00000.gz"
+	gt.R1(s3Client.CreateBucket(ctx, &s3.CreateBucketInput{Bucket: aws.String(srcBucket)})).NoError(t)
+	gt.R1(s3Client.PutObject(ctx, &s3.PutObjectInput{
+		Bucket: aws.String(srcBucket),
+		Key:    aws.String(srcKey),
+		Body:   bytes.NewReader([]byte("test-data")),
+	})).NoError(t)
+
+	queue := gt.R1(sqsClient.CreateQueue(ctx, &sqs.CreateQueueInput{
+		QueueName: aws.String("hatchery-test-" + id),
+	})).NoError(t)
+	body := fmt.Sprintf(`{"bucket":%q,"files":[{"path":%q}]}`, srcBucket, srcKey)
+	gt.R1(sqsClient.SendMessage(ctx, &sqs.SendMessageInput{
+		QueueUrl:    queue.QueueUrl,
+		MessageBody: aws.String(body),
+	})).NoError(t)
+
+	mockCS := cs.NewMock()
+	clients := infra.New(infra.WithCloudStorage(mockCS))
+	maxPulls := 2
+	gt.NoError(t, fdr.Exec(ctx, clients, &config.FalconDataReplicatorImpl{
+		AwsRegion:       "us-east-1",
+		AwsCredential:   cred,
+		AwsEndpoint:     aws.String(endpoint),
+		AwsUsePathStyle: true,
+		Bucket:          "test-bucket",
+		SqsUrl:          *queue.QueueUrl,
+		MaxPulls:        &maxPulls,
+	}))
+
+	gt.A(t, mockCS.Results).Length(1).At(0, func(t testing.TB, v *cs.MockResult) {
+		gt.S(t, string(v.Object)).HasSuffix(srcKey)
+		gt.Equal(t, v.Body.String(), "test-data")
+	})
+}
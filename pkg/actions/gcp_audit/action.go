@@ -0,0 +1,168 @@
+package gcp_audit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/m-mizutani/goerr"
+	"github.com/m-mizutani/hatchery/pkg/domain/config"
+	"github.com/m-mizutani/hatchery/pkg/domain/interfaces"
+	"github.com/m-mizutani/hatchery/pkg/domain/model"
+	"github.com/m-mizutani/hatchery/pkg/domain/types"
+	"github.com/m-mizutani/hatchery/pkg/infra"
+	"github.com/m-mizutani/hatchery/pkg/infra/oauth"
+	"github.com/m-mizutani/hatchery/pkg/infra/output"
+	"github.com/m-mizutani/hatchery/pkg/utils"
+)
+
+const (
+	// See https://cloud.google.com/logging/docs/reference/v2/rest/v2/entries/list
+	entriesListURL = "https://logging.googleapis.com/v2/entries:list"
+	loggingScope   = "https://www.googleapis.com/auth/logging.read"
+)
+
+func Exec(ctx context.Context, clients *infra.Clients, req *config.GCPAuditLogsImpl) error {
+	ts, err := clients.GoogleTokenSource(ctx, loggingScope)
+	if err != nil {
+		return goerr.Wrap(err, "failed to get Google token source").With("req", req)
+	}
+	httpClient := oauth.NewClient(clients.HTTPClient(), oauth.FromOAuth2(ts))
+
+	now := utils.CtxNow(ctx)
+	filter := buildFilter(req, now)
+
+	var pageToken string
+	for seq := 0; req.MaxPages == nil || seq < *req.MaxPages; seq++ {
+		next, err := crawl(ctx, clients, httpClient, req, filter, now, seq, pageToken)
+		if err != nil {
+			return goerr.Wrap(err, "failed to crawl GCP audit logs").With("seq", seq).With("filter", filter).With("req", req)
+		}
+		if next == nil {
+			break
+		}
+		pageToken = *next
+	}
+
+	return nil
+}
+
+// buildFilter returns query of Logging query language for audit logs of the window.
+// See https://cloud.google.com/logging/docs/audit#log_types
+func buildFilter(req *config.GCPAuditLogsImpl, end time.Time) string {
+	start := end.Add(-req.GetDuration().GoDuration())
+
+	logIDs := make([]string, len(req.GetLogTypes()))
+	for i, t := range req.GetLogTypes() {
+		logIDs[i] = fmt.Sprintf(`log_id("cloudaudit.googleapis.com/%s")`, t)
+	}
+
+	conds := []string{
+		"(" + strings.Join(logIDs, " OR ") + ")",
+		fmt.Sprintf(`timestamp >= "%s"`, start.UTC().Format(time.RFC3339)),
+		fmt.Sprintf(`timestamp < "%s"`, end.UTC().Format(time.RFC3339)),
+	}
+	if req.Filter != nil && *req.Filter != "" {
+		conds = append(conds, "("+*req.Filter+")")
+	}
+
+	return strings.Join(conds, " AND ")
+}
+
+type listRequest struct {
+	ResourceNames []string `json:"resourceNames"`
+	Filter        string   `json:"filter"`
+	OrderBy       string   `json:"orderBy"`
+	PageSize      int      `json:"pageSize"`
+	PageToken     string   `json:"pageToken,omitempty"`
+}
+
+type listResponse struct {
+	Entries       []json.RawMessage `json:"entries"`
+	NextPageToken string            `json:"nextPageToken"`
+}
+
+func crawl(ctx context.Context, clients *infra.Clients, httpClient interfaces.HTTPClient, req *config.GCPAuditLogsImpl, filter string, end time.Time, seq int, pageToken string) (*string, error) {
+	body, err := json.Marshal(listRequest{
+		ResourceNames: req.GetResourceNames(),
+		Filter:        filter,
+		OrderBy:       "timestamp asc",
+		PageSize:      req.GetLimit(),
+		PageToken:     pageToken,
+	})
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to marshal request body")
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, entriesListURL, bytes.NewReader(body))
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to create HTTP request")
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	httpResp, err := httpClient.Do(httpReq)
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to send HTTP request")
+	}
+	defer utils.SafeClose(httpResp.Body)
+
+	if httpResp.StatusCode != http.StatusOK {
+		data, _ := io.ReadAll(httpResp.Body)
+		return nil, goerr.New("unexpected status code").With("status", httpResp.Status).With("body", string(data))
+	}
+
+	var resp listResponse
+	if err := json.NewDecoder(httpResp.Body).Decode(&resp); err != nil {
+		return nil, goerr.Wrap(err, "failed to unmarshal response body")
+	}
+
+	// Logging API may return an empty page with nextPageToken while searching
+	if len(resp.Entries) > 0 {
+		if err := writeEntries(ctx, clients, req, resp.Entries, end, seq); err != nil {
+			return nil, err
+		}
+	}
+
+	if resp.NextPageToken != "" {
+		return &resp.NextPageToken, nil
+	}
+	return nil, nil
+}
+
+// writeEntries writes log entries as NDJSON.
+func writeEntries(ctx context.Context, clients *infra.Clients, req *config.GCPAuditLogsImpl, entries []json.RawMessage, end time.Time, seq int) error {
+	objName := model.DefaultLogObjectName(ctx, req, end, seq)
+	w, err := output.NewLogObjectWriter(ctx, clients.CloudStorage(), req, objName, output.WithWindow(end.Add(-req.GetDuration().GoDuration()), end), types.WithContentType(output.ContentTypeNDJSON))
+	if err != nil {
+		return err
+	}
+
+	var n int
+	for _, entry := range entries {
+		var buf bytes.Buffer
+		if err := json.Compact(&buf, entry); err != nil {
+			w.Abort()
+			return goerr.Wrap(err, "failed to compact log entry")
+		}
+		buf.WriteByte('\n')
+
+		written, err := w.Write(buf.Bytes())
+		if err != nil {
+			w.Abort()
+			return goerr.Wrap(err, "failed to write log entry to object writer").With("object", objName)
+		}
+		n += written
+	}
+
+	if err := w.Close(); err != nil {
+		return goerr.Wrap(err, "failed to close object writer").With("object", objName)
+	}
+
+	utils.CtxLogger(ctx).Info("harvested GCP audit logs", "entries", len(entries), "bytes", n, "object", w.Object())
+	return nil
+}
@@ -0,0 +1,103 @@
+package gcp_audit_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/apple/pkl-go/pkl"
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/hatchery/pkg/actions/gcp_audit"
+	"github.com/m-mizutani/hatchery/pkg/domain/config"
+	"github.com/m-mizutani/hatchery/pkg/domain/model"
+	"github.com/m-mizutani/hatchery/pkg/infra"
+	"github.com/m-mizutani/hatchery/pkg/infra/cs"
+	"github.com/m-mizutani/hatchery/pkg/utils"
+	"golang.org/x/oauth2"
+)
+
+func TestGCPAuditLogs(t *testing.T) {
+	type listRequest struct {
+		ResourceNames []string `json:"resourceNames"`
+		Filter        string   `json:"filter"`
+		OrderBy       string   `json:"orderBy"`
+		PageSize      int      `json:"pageSize"`
+		PageToken     string   `json:"pageToken"`
+	}
+
+	var reqs []listRequest
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST logging.googleapis.com/v2/entries:list", func(w http.ResponseWriter, r *http.Request) {
+		gt.Equal(t, r.Header.Get("Authorization"), "Bearer gcp-token")
+		var body listRequest
+		gt.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		reqs = append(reqs, body)
+
+		switch body.PageToken {
+		case "":
+			_, _ = w.Write([]byte(`{"entries":[{"insertId":"1",
+				"logName":"projects/my-project/logs/cloudaudit.googleapis.com%2Factivity"},{"insertId":"2"}],"nextPageToken":"t1"}`))
+		case "t1":
+			// Empty page while searching
+			_, _ = w.Write([]byte(`{"nextPageToken":"t2"}`))
+		default:
+			_, _ = w.Write([]byte(`{"entries":[{"insertId":"3"}]}`))
+		}
+	})
+
+	var scopes []string
+	mock := cs.NewMock()
+	clients := infra.New(
+		infra.WithCloudStorage(mock),
+		infra.WithHTTPClient(utils.NewFakeHTTPClient(mux)),
+		infra.WithNewGoogleTokenSource(func(ctx context.Context, s ...string) (oauth2.TokenSource, error) {
+			scopes = s
+			return oauth2.StaticTokenSource(&oauth2.Token{AccessToken: "gcp-token"}), nil
+		}),
+	)
+
+	ctx := context.Background()
+	_, ctx = utils.CtxRequestID(ctx)
+	now := time.Date(2024, 4, 1, 10, 0, 0, 0, time.UTC)
+	ctx = utils.CtxWithNow(ctx, func() time.Time { return now })
+
+	filter := `protoPayload.methodName:"SetIamPolicy"`
+	req := &config.GCPAuditLogsImpl{
+		ResourceNames: []string{"projects/my-project"},
+		LogTypes:      []string{"activity", "policy"},
+		Filter:        &filter,
+		Duration:      &pkl.Duration{Value: 20, Unit: pkl.Minute},
+		Limit:         1000,
+		Bucket:        "test-bucket",
+	}
+	gt.NoError(t, gcp_audit.Exec(ctx, clients, req)).Must()
+
+	gt.A(t, scopes).Equal([]string{"https://www.googleapis.com/auth/logging.read"})
+	gt.A(t, reqs).Length(3).At(0, func(t testing.TB, v listRequest) {
+		gt.A(t, v.ResourceNames).Equal([]string{"projects/my-project"})
+		gt.Equal(t, v.Filter, `(log_id("cloudaudit.googleapis.com/activity") OR log_id("cloudaudit.googleapis.com/policy")) AND timestamp >= "2024-04-01T09:40:00Z" AND timestamp < "2024-04-01T10:00:00Z" AND (protoPayload.methodName:"SetIamPolicy")`)
+		gt.Equal(t, v.OrderBy, "timestamp asc")
+		gt.Equal(t, v.PageSize, 1000)
+	})
+
+	// Empty page is not written
+	gt.A(t, mock.Results).Length(2).
+		At(0, func(t testing.TB, v *cs.MockResult) {
+			gt.Equal(t, v.Object, model.DefaultLogObjectName(ctx, req, now, 0))
+			gt.Equal(t, v.Attrs.ContentType, "application/x-ndjson")
+			gt.Equal(t, v.Attrs.ContentEncoding, "gzip")
+			gt.Equal(t, v.Attrs.Metadata["hatchery-window-start"], "2024-04-01T09:40:00Z")
+			gt.Equal(t, v.Attrs.Metadata["hatchery-window-end"], "2024-04-01T10:00:00Z")
+			r := gt.R1(gzip.NewReader(bytes.NewReader(v.Body.Bytes()))).NoError(t)
+			gt.Equal(t, string(gt.R1(io.ReadAll(r)).NoError(t)),
+				`{"insertId":"1","logName":"projects/my-project/logs/cloudaudit.googleapis.com%2Factivity"}`+"\n"+`{"insertId":"2"}`+"\n")
+		}).
+		At(1, func(t testing.TB, v *cs.MockResult) {
+			gt.Equal(t, v.Object, model.DefaultLogObjectName(ctx, req, now, 2))
+		})
+}
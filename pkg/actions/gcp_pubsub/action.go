@@ -0,0 +1,174 @@
+package gcp_pubsub
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"cloud.google.com/go/pubsub"
+	"github.com/m-mizutani/goerr"
+	"github.com/m-mizutani/hatchery/pkg/domain/config"
+	"github.com/m-mizutani/hatchery/pkg/infra"
+	"github.com/m-mizutani/hatchery/pkg/infra/batch"
+	"github.com/m-mizutani/hatchery/pkg/utils"
+)
+
+// record is stored as a line of NDJSON object
+type record struct {
+	ID          string            `json:"id"`
+	PublishTime time.Time         `json:"publish_time"`
+	OrderingKey string            `json:"ordering_key,omitempty"`
+	Attributes  map[string]string `json:"attributes,omitempty"`
+	// Data is stored as JSON if possible, otherwise as string
+	Data any `json:"data"`
+}
+
+// Exec receives messages of the subscription and writes them into objects. Messages are acknowledged after each object is closed, and negatively acknowledged if writing fails. Without daemon mode, it returns when max_duration has passed or max_messages have been received.
+func Exec(ctx context.Context, clients *infra.Clients, req *config.PubSubSubscriptionImpl) error {
+	client, err := clients.NewPubSub(ctx, req.ProjectId)
+	if err != nil {
+		return goerr.Wrap(err, "failed to create Pub/Sub client").With("project_id", req.ProjectId)
+	}
+	defer utils.SafeClose(client)
+
+	sub := client.Subscription(req.Subscription)
+	// Buffered messages are outstanding until acknowledged
+	sub.ReceiveSettings.MaxOutstandingMessages = req.MaxRecords
+
+	var recvCtx context.Context
+	var cancel context.CancelFunc
+	if req.Daemon {
+		recvCtx, cancel = context.WithCancel(ctx)
+	} else {
+		recvCtx, cancel = context.WithTimeout(ctx, req.MaxDuration.GoDuration())
+	}
+	defer cancel()
+
+	// Buffered messages are written even after ctx is canceled in daemon mode, within the grace period
+	flushCtx, cancelFlush := batch.FlushContext(ctx)
+	defer cancelFlush()
+
+	r := &receiver{
+		req:      req,
+		flush:    batch.NewObjectFlusher(clients.CloudStorage(), req),
+		flushCtx: flushCtx,
+		cancel:   cancel,
+	}
+
+	// Receive does not return until all received messages are acknowledged, so that the final flush runs in another goroutine.
+	flushDone := make(chan struct{})
+	go func() {
+		defer close(flushDone)
+		r.flushLoop(recvCtx)
+	}()
+
+	recvErr := sub.Receive(recvCtx, r.receive)
+	cancel()
+	<-flushDone
+
+	if recvErr != nil {
+		return goerr.Wrap(recvErr, "failed to receive Pub/Sub messages").With("subscription", req.Subscription)
+	}
+	return r.err
+}
+
+type receiver struct {
+	req      *config.PubSubSubscriptionImpl
+	flush    batch.Flusher
+	flushCtx context.Context
+	cancel   context.CancelFunc
+
+	mutex    sync.Mutex
+	records  [][]byte
+	pending  []*pubsub.Message
+	size     int
+	received int
+	closed   bool
+	err      error
+}
+
+func (x *receiver) receive(ctx context.Context, msg *pubsub.Message) {
+	rec := record{
+		ID:          msg.ID,
+		PublishTime: msg.PublishTime.UTC(),
+		OrderingKey: msg.OrderingKey,
+		Attributes:  msg.Attributes,
+		Data:        string(msg.Data),
+	}
+	if json.Valid(msg.Data) {
+		rec.Data = json.RawMessage(msg.Data)
+	}
+
+	raw, err := json.Marshal(rec)
+	if err != nil {
+		utils.HandleError(ctx, "failed to marshal Pub/Sub message", goerr.Wrap(err, "failed to marshal Pub/Sub message").With("id", msg.ID))
+		msg.Nack()
+		return
+	}
+
+	x.mutex.Lock()
+	defer x.mutex.Unlock()
+
+	// Messages after the final flush or beyond max_messages are delivered again later
+	if x.closed || (!x.req.Daemon && x.req.MaxMessages != nil && x.received >= *x.req.MaxMessages) {
+		msg.Nack()
+		return
+	}
+
+	x.records = append(x.records, raw)
+	x.pending = append(x.pending, msg)
+	x.size += len(raw)
+	x.received++
+
+	if !x.req.Daemon && x.req.MaxMessages != nil && x.received >= *x.req.MaxMessages {
+		x.cancel()
+	}
+	if len(x.records) >= x.req.MaxRecords || x.size >= x.req.MaxBytes {
+		x.commit(x.flushCtx)
+	}
+}
+
+func (x *receiver) flushLoop(recvCtx context.Context) {
+	ticker := time.NewTicker(x.req.FlushInterval.GoDuration())
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			x.mutex.Lock()
+			x.commit(x.flushCtx)
+			x.mutex.Unlock()
+
+		case <-recvCtx.Done():
+			x.mutex.Lock()
+			x.commit(x.flushCtx)
+			x.closed = true
+			x.mutex.Unlock()
+			return
+		}
+	}
+}
+
+// commit writes buffered records into an object, then acknowledges the messages. If writing fails, the messages are negatively acknowledged to be delivered again and receiving is stopped. mutex must be held.
+func (x *receiver) commit(ctx context.Context) {
+	if len(x.records) == 0 {
+		return
+	}
+
+	if err := x.flush(ctx, x.records); err != nil {
+		for _, msg := range x.pending {
+			msg.Nack()
+		}
+		x.err = goerr.Wrap(err, "failed to write Pub/Sub messages").With("subscription", x.req.Subscription).With("records", len(x.records))
+		x.closed = true
+		x.cancel()
+	} else {
+		for _, msg := range x.pending {
+			msg.Ack()
+		}
+		utils.CtxLogger(ctx).Info("acknowledged Pub/Sub messages", "id", x.req.Id, "subscription", x.req.Subscription, "records", len(x.records))
+	}
+
+	x.records, x.pending, x.size = nil, nil, 0
+}
@@ -0,0 +1,147 @@
+package gcp_pubsub_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/pubsub"
+	"cloud.google.com/go/pubsub/pstest"
+	"github.com/apple/pkl-go/pkl"
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/hatchery/pkg/actions/gcp_pubsub"
+	"github.com/m-mizutani/hatchery/pkg/domain/config"
+	"github.com/m-mizutani/hatchery/pkg/domain/types"
+	"github.com/m-mizutani/hatchery/pkg/infra"
+	"github.com/m-mizutani/hatchery/pkg/infra/cs"
+	"github.com/m-mizutani/hatchery/pkg/utils"
+	"google.golang.org/api/option"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+type failWriter struct {
+	bytes.Buffer
+}
+
+func (x *failWriter) Close() error {
+	return errors.New("destination unavailable")
+}
+
+func readRecords(t testing.TB, result *cs.MockResult) []map[string]any {
+	r := gt.R1(gzip.NewReader(bytes.NewReader(result.Body.Bytes()))).NoError(t)
+	data := gt.R1(io.ReadAll(r)).NoError(t)
+
+	var records []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		var rec map[string]any
+		gt.NoError(t, json.Unmarshal([]byte(line), &rec))
+		records = append(records, rec)
+	}
+	return records
+}
+
+func setup(t *testing.T) (*pstest.Server, infra.Option) {
+	srv := pstest.NewServer()
+	t.Cleanup(func() { _ = srv.Close() })
+
+	newPubSub := func(ctx context.Context, projectID string) (*pubsub.Client, error) {
+		conn, err := grpc.NewClient(srv.Addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+		if err != nil {
+			return nil, err
+		}
+		return pubsub.NewClient(ctx, projectID, option.WithGRPCConn(conn))
+	}
+
+	ctx := context.Background()
+	client := gt.R1(newPubSub(ctx, "my-project")).NoError(t)
+	defer utils.SafeClose(client)
+	topic := gt.R1(client.CreateTopic(ctx, "audit")).NoError(t)
+	gt.R1(client.CreateSubscription(ctx, "hatchery", pubsub.SubscriptionConfig{
+		Topic:       topic,
+		AckDeadline: 10 * time.Second,
+	})).NoError(t)
+
+	return srv, infra.WithNewPubSub(newPubSub)
+}
+
+func newRequest() *config.PubSubSubscriptionImpl {
+	return &config.PubSubSubscriptionImpl{
+		Id:            "pubsub",
+		Bucket:        "test-bucket",
+		ProjectId:     "my-project",
+		Subscription:  "hatchery",
+		MaxDuration:   &pkl.Duration{Value: 10, Unit: pkl.Second},
+		MaxMessages:   ptr(3),
+		MaxRecords:    2,
+		MaxBytes:      1024 * 1024,
+		FlushInterval: &pkl.Duration{Value: 1, Unit: pkl.Minute},
+	}
+}
+
+func TestPubSubSubscription(t *testing.T) {
+	srv, opt := setup(t)
+	srv.Publish("projects/my-project/topics/audit", []byte(`{"seq":0}`), map[string]string{"source": "test"})
+	srv.Publish("projects/my-project/topics/audit", []byte(`{"seq":1}`), nil)
+	srv.Publish("projects/my-project/topics/audit", []byte(`not json`), nil)
+
+	mock := cs.NewMock()
+	clients := infra.New(infra.WithCloudStorage(mock), opt)
+
+	ctx := context.Background()
+	_, ctx = utils.CtxRequestID(ctx)
+	gt.NoError(t, gcp_pubsub.Exec(ctx, clients, newRequest()))
+
+	gt.A(t, mock.Results).Length(2)
+	var records []map[string]any
+	for _, result := range mock.Results {
+		records = append(records, readRecords(t, result)...)
+	}
+	gt.A(t, records).Length(3)
+
+	data := map[string]any{}
+	for _, rec := range records {
+		if attrs, ok := rec["attributes"]; ok {
+			gt.Equal(t, attrs, any(map[string]any{"source": "test"}))
+		}
+		raw := gt.R1(json.Marshal(rec["data"])).NoError(t)
+		data[string(raw)] = rec["data"]
+	}
+	gt.Equal(t, data[`{"seq":0}`], any(map[string]any{"seq": float64(0)}))
+	gt.Equal(t, data[`"not json"`], any("not json"))
+
+	for _, msg := range srv.Messages() {
+		gt.Equal(t, msg.Acks, 1)
+	}
+}
+
+func TestPubSubSubscriptionWriteFailure(t *testing.T) {
+	srv, opt := setup(t)
+	srv.Publish("projects/my-project/topics/audit", []byte(`{"seq":0}`), nil)
+
+	mock := &cs.Mock{
+		NewObjectWriterFn: func(ctx context.Context, bucket types.CSBucket, object types.CSObjectName, opts ...types.WriteOption) io.WriteCloser {
+			return &failWriter{}
+		},
+	}
+	clients := infra.New(infra.WithCloudStorage(mock), opt)
+
+	req := newRequest()
+	req.MaxMessages = ptr(1)
+	gt.Error(t, gcp_pubsub.Exec(context.Background(), clients, req))
+
+	// Message is not acknowledged to be delivered again
+	for _, msg := range srv.Messages() {
+		gt.Equal(t, msg.Acks, 0)
+	}
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
@@ -0,0 +1,203 @@
+package jamf
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/m-mizutani/goerr"
+	"github.com/m-mizutani/hatchery/pkg/domain/config"
+	"github.com/m-mizutani/hatchery/pkg/domain/interfaces"
+	"github.com/m-mizutani/hatchery/pkg/domain/model"
+	"github.com/m-mizutani/hatchery/pkg/domain/types"
+	"github.com/m-mizutani/hatchery/pkg/infra"
+	"github.com/m-mizutani/hatchery/pkg/infra/oauth"
+	"github.com/m-mizutani/hatchery/pkg/infra/output"
+	"github.com/m-mizutani/hatchery/pkg/utils"
+)
+
+const (
+	// Token is refreshed this period before its expiry. Jamf Pro token expires in 20 minutes by default.
+	expiryMargin = time.Minute
+)
+
+func Exec(ctx context.Context, clients *infra.Clients, req *config.JamfProImpl) error {
+	httpClient := oauth.NewClient(clients.HTTPClient(), &tokenSource{
+		client: clients.HTTPClient(),
+		req:    req,
+	})
+
+	now := utils.CtxNow(ctx)
+	for _, name := range req.GetDatasets() {
+		switch name {
+		case "history":
+			for _, endpoint := range req.GetHistoryEndpoints() {
+				dataset := "history/" + strings.TrimSuffix(endpoint, "/history")
+				if err := crawlPages(ctx, clients, httpClient, req, dataset, historyURL(req, endpoint, now), now); err != nil {
+					return goerr.Wrap(err, "failed to crawl Jamf Pro object history").With("endpoint", endpoint).With("req", req)
+				}
+			}
+
+		case "computers_inventory":
+			if err := crawlPages(ctx, clients, httpClient, req, name, inventoryURL(req), now); err != nil {
+				return goerr.Wrap(err, "failed to crawl Jamf Pro computers inventory").With("req", req)
+			}
+
+		default:
+			return goerr.Wrap(types.ErrInvalidOption, "unsupported Jamf Pro dataset").With("dataset", name)
+		}
+	}
+
+	return nil
+}
+
+// historyURL returns URL of object history in the window. Filter is RSQL of Jamf Pro API.
+func historyURL(req *config.JamfProImpl, endpoint string, end time.Time) string {
+	start := end.Add(-req.GetDuration().GoDuration())
+
+	qv := url.Values{}
+	qv.Add("sort", "date:asc")
+	qv.Add("filter", fmt.Sprintf(`date>="%s";date<"%s"`,
+		start.UTC().Format(time.RFC3339), end.UTC().Format(time.RFC3339)))
+	return req.GetBaseUrl() + "/api/" + endpoint + "?" + qv.Encode()
+}
+
+func inventoryURL(req *config.JamfProImpl) string {
+	qv := url.Values{}
+	qv.Add("sort", "id:asc")
+	for _, section := range req.GetInventorySections() {
+		qv.Add("section", section)
+	}
+	return req.GetBaseUrl() + "/api/v1/computers-inventory?" + qv.Encode()
+}
+
+func crawlPages(ctx context.Context, clients *infra.Clients, httpClient interfaces.HTTPClient, req *config.JamfProImpl, dataset, baseURL string, now time.Time) error {
+	for seq := 0; req.MaxPages == nil || seq < *req.MaxPages; seq++ {
+		more, err := crawl(ctx, clients, httpClient, req, dataset, baseURL, now, seq)
+		if err != nil {
+			return goerr.Wrap(err, "failed to crawl page").With("seq", seq).With("dataset", dataset)
+		}
+		if !more {
+			break
+		}
+	}
+	return nil
+}
+
+// crawl writes one page of paginated Jamf Pro API. seq is used as page number that is 0-origin.
+func crawl(ctx context.Context, clients *infra.Clients, httpClient interfaces.HTTPClient, req *config.JamfProImpl, dataset, baseURL string, now time.Time, seq int) (bool, error) {
+	pageURL := fmt.Sprintf("%s&page=%d&page-size=%d", baseURL, seq, req.GetLimit())
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, pageURL, nil)
+	if err != nil {
+		return false, goerr.Wrap(err, "failed to create HTTP request")
+	}
+	httpReq.Header.Set("Accept", "application/json")
+
+	httpResp, err := httpClient.Do(httpReq)
+	if err != nil {
+		return false, goerr.Wrap(err, "failed to send HTTP request")
+	}
+	defer utils.SafeClose(httpResp.Body)
+
+	if httpResp.StatusCode != http.StatusOK {
+		data, _ := io.ReadAll(httpResp.Body)
+		return false, goerr.New("unexpected status code").With("status", httpResp.Status).With("body", string(data)).With("url", pageURL)
+	}
+
+	body, err := io.ReadAll(httpResp.Body)
+	if err != nil {
+		return false, goerr.Wrap(err, "failed to read response body")
+	}
+
+	var resp apiResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return false, goerr.Wrap(err, "failed to unmarshal response body")
+	}
+	if len(resp.Results) == 0 {
+		return false, nil
+	}
+
+	objName := model.DatasetLogObjectName(ctx, req, dataset, now, seq)
+	w, err := output.NewLogObjectWriter(ctx, clients.CloudStorage(), req, objName)
+	if err != nil {
+		return false, err
+	}
+
+	n, err := io.Copy(w, bytes.NewReader(body))
+	if err != nil {
+		w.Abort()
+		return false, goerr.Wrap(err, "failed to write response to object writer").With("bytes", n)
+	}
+	if err := w.Close(); err != nil {
+		return false, goerr.Wrap(err, "failed to close object writer").With("object", objName)
+	}
+
+	utils.CtxLogger(ctx).Info("harvested Jamf Pro data", "dataset", dataset, "results", len(resp.Results), "bytes", n, "object", w.Object())
+
+	return (seq+1)*req.GetLimit() < resp.TotalCount, nil
+}
+
+type apiResponse struct {
+	TotalCount int               `json:"totalCount"`
+	Results    []json.RawMessage `json:"results"`
+}
+
+// tokenSource issues bearer token of Jamf Pro API by basic auth of the API user.
+// See https://developer.jamf.com/jamf-pro/docs/jamf-pro-api-overview#authentication-and-authorization
+type tokenSource struct {
+	client interfaces.HTTPClient
+	req    *config.JamfProImpl
+
+	mutex  sync.Mutex
+	token  string
+	expiry time.Time
+}
+
+func (x *tokenSource) Token(ctx context.Context) (string, error) {
+	x.mutex.Lock()
+	defer x.mutex.Unlock()
+
+	if x.token != "" && utils.CtxNow(ctx).Add(expiryMargin).Before(x.expiry) {
+		return x.token, nil
+	}
+
+	tokenURL := x.req.GetBaseUrl() + "/api/v1/auth/token"
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, tokenURL, nil)
+	if err != nil {
+		return "", goerr.Wrap(err, "failed to create token request")
+	}
+	httpReq.Header.Set("Accept", "application/json")
+	httpReq.SetBasicAuth(x.req.GetUsername(), x.req.GetPassword())
+
+	httpResp, err := x.client.Do(httpReq)
+	if err != nil {
+		return "", goerr.Wrap(err, "failed to send token request")
+	}
+	defer utils.SafeClose(httpResp.Body)
+
+	if httpResp.StatusCode != http.StatusOK {
+		data, _ := io.ReadAll(httpResp.Body)
+		return "", goerr.New("unexpected status code of token endpoint").With("status", httpResp.Status).With("body", string(data))
+	}
+
+	var resp struct {
+		Token   string    `json:"token"`
+		Expires time.Time `json:"expires"`
+	}
+	if err := json.NewDecoder(httpResp.Body).Decode(&resp); err != nil {
+		return "", goerr.Wrap(err, "failed to unmarshal token response")
+	}
+	if resp.Token == "" {
+		return "", goerr.New("empty token of Jamf Pro API")
+	}
+
+	x.token, x.expiry = resp.Token, resp.Expires
+	return x.token, nil
+}
@@ -0,0 +1,80 @@
+package jamf_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/apple/pkl-go/pkl"
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/hatchery/pkg/actions/jamf"
+	"github.com/m-mizutani/hatchery/pkg/domain/config"
+	"github.com/m-mizutani/hatchery/pkg/domain/model"
+	"github.com/m-mizutani/hatchery/pkg/infra"
+	"github.com/m-mizutani/hatchery/pkg/infra/cs"
+	"github.com/m-mizutani/hatchery/pkg/utils"
+)
+
+func TestJamfPro(t *testing.T) {
+	var tokenCalls int
+	var historyQueries []string
+	var inventoryPages []string
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST example.jamfcloud.com/api/v1/auth/token", func(w http.ResponseWriter, r *http.Request) {
+		tokenCalls++
+		user, pass, ok := r.BasicAuth()
+		gt.Equal(t, ok, true)
+		gt.Equal(t, user, "api-user")
+		gt.Equal(t, pass, "api-pass")
+		_, _ = w.Write([]byte(`{"token":"jamf-token","expires":"2024-04-01T10:20:00.000Z"}`))
+	})
+	mux.HandleFunc("GET example.jamfcloud.com/api/v1/jamf-pro-server-url/history", func(w http.ResponseWriter, r *http.Request) {
+		gt.Equal(t, r.Header.Get("Authorization"), "Bearer jamf-token")
+		historyQueries = append(historyQueries, r.URL.Query().Get("filter"))
+		_, _ = w.Write([]byte(`{"totalCount":1,"results":[{"id":1,"username":"admin","date":"2024-04-01T09:50:00Z","note":"changed"}]}`))
+	})
+	mux.HandleFunc("GET example.jamfcloud.com/api/v1/computers-inventory", func(w http.ResponseWriter, r *http.Request) {
+		gt.Equal(t, r.Header.Get("Authorization"), "Bearer jamf-token")
+		gt.A(t, r.URL.Query()["section"]).Equal([]string{"GENERAL", "HARDWARE"})
+		gt.Equal(t, r.URL.Query().Get("page-size"), "2")
+		inventoryPages = append(inventoryPages, r.URL.Query().Get("page"))
+		if r.URL.Query().Get("page") == "0" {
+			_, _ = w.Write([]byte(`{"totalCount":3,"results":[{"id":"1"},{"id":"2"}]}`))
+		} else {
+			_, _ = w.Write([]byte(`{"totalCount":3,"results":[{"id":"3"}]}`))
+		}
+	})
+
+	mock := cs.NewMock()
+	clients := infra.New(infra.WithCloudStorage(mock), infra.WithHTTPClient(utils.NewFakeHTTPClient(mux)))
+
+	ctx := context.Background()
+	_, ctx = utils.CtxRequestID(ctx)
+	now := time.Date(2024, 4, 1, 10, 0, 0, 0, time.UTC)
+	ctx = utils.CtxWithNow(ctx, func() time.Time { return now })
+
+	req := &config.JamfProImpl{
+		BaseUrl:           "https://example.jamfcloud.com",
+		Username:          "api-user",
+		Password:          "api-pass",
+		Datasets:          []string{"history", "computers_inventory"},
+		HistoryEndpoints:  []string{"v1/jamf-pro-server-url/history"},
+		InventorySections: []string{"GENERAL", "HARDWARE"},
+		Duration:          &pkl.Duration{Value: 20, Unit: pkl.Minute},
+		Limit:             2,
+		Bucket:            "test-bucket",
+	}
+	gt.NoError(t, jamf.Exec(ctx, clients, req)).Must()
+
+	gt.Equal(t, tokenCalls, 1)
+	gt.A(t, historyQueries).Equal([]string{`date>="2024-04-01T09:40:00Z";date<"2024-04-01T10:00:00Z"`})
+	gt.A(t, inventoryPages).Equal([]string{"0", "1"})
+	gt.A(t, mock.Results).Length(3).
+		At(0, func(t testing.TB, v *cs.MockResult) {
+			gt.Equal(t, v.Object, model.DatasetLogObjectName(ctx, req, "history/v1/jamf-pro-server-url", now, 0))
+		}).
+		At(2, func(t testing.TB, v *cs.MockResult) {
+			gt.Equal(t, v.Object, model.DatasetLogObjectName(ctx, req, "computers_inventory", now, 1))
+		})
+}
@@ -0,0 +1,206 @@
+package kafka
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/m-mizutani/goerr"
+	"github.com/m-mizutani/hatchery/pkg/domain/config"
+	"github.com/m-mizutani/hatchery/pkg/domain/types"
+	"github.com/m-mizutani/hatchery/pkg/infra"
+	"github.com/m-mizutani/hatchery/pkg/infra/batch"
+	"github.com/m-mizutani/hatchery/pkg/utils"
+	"github.com/twmb/franz-go/pkg/kgo"
+	"github.com/twmb/franz-go/pkg/sasl"
+	"github.com/twmb/franz-go/pkg/sasl/plain"
+	"github.com/twmb/franz-go/pkg/sasl/scram"
+)
+
+// record is stored as a line of NDJSON object
+type record struct {
+	Topic     string            `json:"topic"`
+	Partition int32             `json:"partition"`
+	Offset    int64             `json:"offset"`
+	Timestamp time.Time         `json:"timestamp"`
+	Key       string            `json:"key,omitempty"`
+	Headers   map[string]string `json:"headers,omitempty"`
+	// Value is stored as JSON if possible, otherwise as string
+	Value any `json:"value"`
+}
+
+// Exec consumes messages of the topic and writes them into objects. Offsets of the messages are committed after each object is closed. Without daemon mode, it returns when max_duration has passed or max_messages have been consumed.
+func Exec(ctx context.Context, clients *infra.Clients, req *config.KafkaTopicImpl) error {
+	opts, err := clientOptions(req)
+	if err != nil {
+		return err
+	}
+
+	client, err := kgo.NewClient(opts...)
+	if err != nil {
+		return goerr.Wrap(err, "failed to create Kafka client").With("brokers", req.Brokers)
+	}
+	defer client.Close()
+
+	c := &consumer{
+		client: client,
+		req:    req,
+		flush:  batch.NewObjectFlusher(clients.CloudStorage(), req),
+	}
+	return c.run(ctx)
+}
+
+func clientOptions(req *config.KafkaTopicImpl) ([]kgo.Opt, error) {
+	opts := []kgo.Opt{
+		kgo.SeedBrokers(req.Brokers...),
+		kgo.ConsumerGroup(req.Group),
+		kgo.ConsumeTopics(req.Topic),
+		kgo.DisableAutoCommit(),
+	}
+
+	if req.Tls {
+		opts = append(opts, kgo.DialTLSConfig(&tls.Config{MinVersion: tls.VersionTLS12}))
+	}
+
+	if cfg := req.Sasl; cfg != nil {
+		var mechanism sasl.Mechanism
+		switch cfg.Mechanism {
+		case "PLAIN":
+			mechanism = plain.Auth{User: cfg.Username, Pass: cfg.Password}.AsMechanism()
+		case "SCRAM-SHA-256":
+			mechanism = scram.Auth{User: cfg.Username, Pass: cfg.Password}.AsSha256Mechanism()
+		case "SCRAM-SHA-512":
+			mechanism = scram.Auth{User: cfg.Username, Pass: cfg.Password}.AsSha512Mechanism()
+		default:
+			return nil, goerr.Wrap(types.ErrInvalidOption, "unsupported SASL mechanism").With("mechanism", cfg.Mechanism)
+		}
+		opts = append(opts, kgo.SASL(mechanism))
+	}
+
+	return opts, nil
+}
+
+type consumer struct {
+	client *kgo.Client
+	req    *config.KafkaTopicImpl
+	flush  batch.Flusher
+
+	records  [][]byte
+	pending  []*kgo.Record
+	size     int
+	consumed int
+}
+
+func (x *consumer) run(ctx context.Context) error {
+	runCtx := ctx
+	if !x.req.Daemon {
+		var cancel context.CancelFunc
+		runCtx, cancel = context.WithTimeout(ctx, x.req.MaxDuration.GoDuration())
+		defer cancel()
+	}
+
+	// Buffered records are written even after ctx is canceled in daemon mode, within the grace period
+	flushCtx, cancelFlush := batch.FlushContext(ctx)
+	defer cancelFlush()
+	interval := x.req.FlushInterval.GoDuration()
+	lastFlush := time.Now()
+
+	for runCtx.Err() == nil && !x.reachedMaxMessages() {
+		pollCtx, cancel := context.WithDeadline(runCtx, lastFlush.Add(interval))
+		fetches := x.client.PollRecords(pollCtx, x.pollSize())
+		cancel()
+
+		if fetches.IsClientClosed() {
+			break
+		}
+		for _, fetchErr := range fetches.Errors() {
+			if errors.Is(fetchErr.Err, context.DeadlineExceeded) || errors.Is(fetchErr.Err, context.Canceled) {
+				continue
+			}
+			return goerr.Wrap(fetchErr.Err, "failed to fetch Kafka records").With("topic", fetchErr.Topic).With("partition", fetchErr.Partition)
+		}
+
+		var addErr error
+		fetches.EachRecord(func(r *kgo.Record) {
+			if addErr == nil {
+				addErr = x.add(r)
+			}
+		})
+		if addErr != nil {
+			return addErr
+		}
+
+		if len(x.records) >= x.req.MaxRecords || x.size >= x.req.MaxBytes || time.Since(lastFlush) >= interval {
+			if err := x.commit(flushCtx); err != nil {
+				return err
+			}
+			lastFlush = time.Now()
+		}
+	}
+
+	return x.commit(flushCtx)
+}
+
+func (x *consumer) reachedMaxMessages() bool {
+	return !x.req.Daemon && x.req.MaxMessages != nil && x.consumed >= *x.req.MaxMessages
+}
+
+// pollSize limits number of records of a poll not to exceed max_records and max_messages
+func (x *consumer) pollSize() int {
+	n := x.req.MaxRecords - len(x.records)
+	if !x.req.Daemon && x.req.MaxMessages != nil {
+		n = min(n, *x.req.MaxMessages-x.consumed)
+	}
+	return max(n, 1)
+}
+
+func (x *consumer) add(r *kgo.Record) error {
+	rec := record{
+		Topic:     r.Topic,
+		Partition: r.Partition,
+		Offset:    r.Offset,
+		Timestamp: r.Timestamp.UTC(),
+		Key:       string(r.Key),
+		Value:     string(r.Value),
+	}
+	if json.Valid(r.Value) {
+		rec.Value = json.RawMessage(r.Value)
+	}
+	for _, h := range r.Headers {
+		if rec.Headers == nil {
+			rec.Headers = map[string]string{}
+		}
+		rec.Headers[h.Key] = string(h.Value)
+	}
+
+	raw, err := json.Marshal(rec)
+	if err != nil {
+		return goerr.Wrap(err, "failed to marshal Kafka record").With("topic", r.Topic).With("offset", r.Offset)
+	}
+
+	x.records = append(x.records, raw)
+	x.pending = append(x.pending, r)
+	x.size += len(raw)
+	x.consumed++
+	return nil
+}
+
+// commit writes buffered records into an object, then commits offsets of them. Offsets are not committed if writing fails, so that the records are consumed again.
+func (x *consumer) commit(ctx context.Context) error {
+	if len(x.records) == 0 {
+		return nil
+	}
+
+	if err := x.flush(ctx, x.records); err != nil {
+		return goerr.Wrap(err, "failed to write Kafka records").With("topic", x.req.Topic).With("records", len(x.records))
+	}
+	if err := x.client.CommitRecords(ctx, x.pending...); err != nil {
+		return goerr.Wrap(err, "failed to commit Kafka offsets").With("topic", x.req.Topic).With("group", x.req.Group)
+	}
+
+	utils.CtxLogger(ctx).Info("committed Kafka records", "id", x.req.Id, "topic", x.req.Topic, "records", len(x.records))
+	x.records, x.pending, x.size = nil, nil, 0
+	return nil
+}
@@ -0,0 +1,154 @@
+package kafka_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/apple/pkl-go/pkl"
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/hatchery/pkg/actions/kafka"
+	"github.com/m-mizutani/hatchery/pkg/domain/config"
+	"github.com/m-mizutani/hatchery/pkg/domain/types"
+	"github.com/m-mizutani/hatchery/pkg/infra"
+	"github.com/m-mizutani/hatchery/pkg/infra/cs"
+	"github.com/m-mizutani/hatchery/pkg/utils"
+	"github.com/twmb/franz-go/pkg/kfake"
+	"github.com/twmb/franz-go/pkg/kgo"
+)
+
+func readRecords(t testing.TB, result *cs.MockResult) []map[string]any {
+	r := gt.R1(gzip.NewReader(bytes.NewReader(result.Body.Bytes()))).NoError(t)
+	data := gt.R1(io.ReadAll(r)).NoError(t)
+
+	var records []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		var rec map[string]any
+		gt.NoError(t, json.Unmarshal([]byte(line), &rec))
+		records = append(records, rec)
+	}
+	return records
+}
+
+func TestKafkaTopic(t *testing.T) {
+	cluster := gt.R1(kfake.NewCluster(kfake.NumBrokers(1), kfake.SeedTopics(1, "audit"))).NoError(t)
+	defer cluster.Close()
+
+	ctx := context.Background()
+	producer := gt.R1(kgo.NewClient(kgo.SeedBrokers(cluster.ListenAddrs()...))).NoError(t)
+	defer producer.Close()
+	for i := 0; i < 5; i++ {
+		rec := &kgo.Record{
+			Topic:   "audit",
+			Key:     []byte(fmt.Sprintf("k%d", i)),
+			Value:   []byte(fmt.Sprintf(`{"seq":%d}`, i)),
+			Headers: []kgo.RecordHeader{{Key: "source", Value: []byte("test")}},
+		}
+		if i == 4 {
+			rec.Value = []byte("not json")
+		}
+		gt.NoError(t, producer.ProduceSync(ctx, rec).FirstErr())
+	}
+
+	mock := cs.NewMock()
+	clients := infra.New(infra.WithCloudStorage(mock))
+	req := &config.KafkaTopicImpl{
+		Id:            "kafka",
+		Bucket:        "test-bucket",
+		Brokers:       cluster.ListenAddrs(),
+		Topic:         "audit",
+		Group:         "hatchery",
+		MaxDuration:   &pkl.Duration{Value: 10, Unit: pkl.Second},
+		MaxMessages:   ptr(3),
+		MaxRecords:    2,
+		MaxBytes:      1024 * 1024,
+		FlushInterval: &pkl.Duration{Value: 1, Unit: pkl.Minute},
+	}
+
+	_, runCtx := utils.CtxRequestID(ctx)
+	gt.NoError(t, kafka.Exec(runCtx, clients, req))
+
+	gt.A(t, mock.Results).Length(2).
+		At(0, func(t testing.TB, v *cs.MockResult) {
+			records := readRecords(t, v)
+			gt.A(t, records).Length(2)
+			gt.Equal(t, records[0]["offset"], any(float64(0)))
+			gt.Equal(t, records[0]["key"], any("k0"))
+			gt.Equal(t, records[0]["value"], any(map[string]any{"seq": float64(0)}))
+			gt.Equal(t, records[0]["headers"], any(map[string]any{"source": "test"}))
+		}).
+		At(1, func(t testing.TB, v *cs.MockResult) {
+			gt.A(t, readRecords(t, v)).Length(1)
+		})
+
+	// Committed offsets are resumed by the next run
+	req.MaxMessages = ptr(2)
+	_, runCtx = utils.CtxRequestID(ctx)
+	gt.NoError(t, kafka.Exec(runCtx, clients, req))
+
+	gt.A(t, mock.Results).Length(3).
+		At(2, func(t testing.TB, v *cs.MockResult) {
+			records := readRecords(t, v)
+			gt.A(t, records).Length(2)
+			gt.Equal(t, records[0]["offset"], any(float64(3)))
+			gt.Equal(t, records[1]["value"], any("not json"))
+		})
+}
+
+type failWriter struct {
+	bytes.Buffer
+}
+
+func (x *failWriter) Close() error {
+	return errors.New("destination unavailable")
+}
+
+func TestKafkaTopicWriteFailure(t *testing.T) {
+	cluster := gt.R1(kfake.NewCluster(kfake.NumBrokers(1), kfake.SeedTopics(1, "audit"))).NoError(t)
+	defer cluster.Close()
+
+	ctx := context.Background()
+	producer := gt.R1(kgo.NewClient(kgo.SeedBrokers(cluster.ListenAddrs()...))).NoError(t)
+	defer producer.Close()
+	gt.NoError(t, producer.ProduceSync(ctx, &kgo.Record{Topic: "audit", Value: []byte(`{"seq":0}`)}).FirstErr())
+
+	req := &config.KafkaTopicImpl{
+		Id:            "kafka",
+		Bucket:        "test-bucket",
+		Brokers:       cluster.ListenAddrs(),
+		Topic:         "audit",
+		Group:         "hatchery",
+		MaxDuration:   &pkl.Duration{Value: 10, Unit: pkl.Second},
+		MaxMessages:   ptr(1),
+		MaxRecords:    10,
+		MaxBytes:      1024 * 1024,
+		FlushInterval: &pkl.Duration{Value: 1, Unit: pkl.Minute},
+	}
+
+	failing := &cs.Mock{
+		NewObjectWriterFn: func(ctx context.Context, bucket types.CSBucket, object types.CSObjectName, opts ...types.WriteOption) io.WriteCloser {
+			return &failWriter{}
+		},
+	}
+	gt.Error(t, kafka.Exec(ctx, infra.New(infra.WithCloudStorage(failing)), req))
+
+	// Offset is not committed, then the record is consumed again
+	mock := cs.NewMock()
+	_, runCtx := utils.CtxRequestID(ctx)
+	gt.NoError(t, kafka.Exec(runCtx, infra.New(infra.WithCloudStorage(mock)), req))
+	gt.A(t, mock.Results).Length(1).At(0, func(t testing.TB, v *cs.MockResult) {
+		records := readRecords(t, v)
+		gt.A(t, records).Length(1)
+		gt.Equal(t, records[0]["offset"], any(float64(0)))
+	})
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
@@ -0,0 +1,110 @@
+package kandji
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/m-mizutani/goerr"
+	"github.com/m-mizutani/hatchery/pkg/domain/config"
+	"github.com/m-mizutani/hatchery/pkg/domain/interfaces"
+	"github.com/m-mizutani/hatchery/pkg/domain/model"
+	"github.com/m-mizutani/hatchery/pkg/infra"
+	"github.com/m-mizutani/hatchery/pkg/infra/oauth"
+	"github.com/m-mizutani/hatchery/pkg/infra/output"
+	"github.com/m-mizutani/hatchery/pkg/utils"
+)
+
+func Exec(ctx context.Context, clients *infra.Clients, req *config.KandjiImpl) error {
+	httpClient := oauth.NewClient(clients.HTTPClient(), oauth.StaticToken(req.GetApiToken()))
+
+	now := utils.CtxNow(ctx)
+	nextURL := firstPageURL(req, now)
+
+	for seq := 0; req.MaxPages == nil || seq < *req.MaxPages; seq++ {
+		next, err := crawl(ctx, clients, httpClient, req, now, seq, nextURL)
+		if err != nil {
+			return goerr.Wrap(err, "failed to crawl Kandji audit events").With("seq", seq).With("url", nextURL).With("req", req)
+		}
+		if next == nil {
+			break
+		}
+		nextURL = *next
+	}
+
+	return nil
+}
+
+// See https://api-docs.kandji.io/#audit-log
+func firstPageURL(req *config.KandjiImpl, end time.Time) string {
+	start := end.Add(-req.GetDuration().GoDuration())
+
+	qv := url.Values{}
+	qv.Add("start_date", start.UTC().Format(time.RFC3339))
+	qv.Add("end_date", end.UTC().Format(time.RFC3339))
+	qv.Add("sort_by", "occurred_at")
+	qv.Add("limit", fmt.Sprintf("%d", req.GetLimit()))
+	return req.GetApiUrl() + "/api/v1/audit/events?" + qv.Encode()
+}
+
+func crawl(ctx context.Context, clients *infra.Clients, httpClient interfaces.HTTPClient, req *config.KandjiImpl, end time.Time, seq int, pageURL string) (*string, error) {
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, pageURL, nil)
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to create HTTP request")
+	}
+	httpReq.Header.Set("Accept", "application/json")
+
+	httpResp, err := httpClient.Do(httpReq)
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to send HTTP request")
+	}
+	defer utils.SafeClose(httpResp.Body)
+
+	if httpResp.StatusCode != http.StatusOK {
+		data, _ := io.ReadAll(httpResp.Body)
+		return nil, goerr.New("unexpected status code").With("status", httpResp.Status).With("body", string(data))
+	}
+
+	body, err := io.ReadAll(httpResp.Body)
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to read response body")
+	}
+
+	var resp apiResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, goerr.Wrap(err, "failed to unmarshal response body")
+	}
+
+	objName := model.DefaultLogObjectName(ctx, req, end, seq)
+	w, err := output.NewLogObjectWriter(ctx, clients.CloudStorage(), req, objName, output.WithWindow(end.Add(-req.GetDuration().GoDuration()), end))
+	if err != nil {
+		return nil, err
+	}
+
+	n, err := io.Copy(w, bytes.NewReader(body))
+	if err != nil {
+		w.Abort()
+		return nil, goerr.Wrap(err, "failed to write response to object writer").With("bytes", n)
+	}
+	if err := w.Close(); err != nil {
+		return nil, goerr.Wrap(err, "failed to close object writer").With("object", objName)
+	}
+
+	utils.CtxLogger(ctx).Info("harvested Kandji audit events", "events", len(resp.Results), "bytes", n, "object", w.Object())
+
+	if resp.Next != nil && *resp.Next != "" {
+		return resp.Next, nil
+	}
+	return nil, nil
+}
+
+type apiResponse struct {
+	Results  []json.RawMessage `json:"results"`
+	Next     *string           `json:"next"`
+	Previous *string           `json:"previous"`
+}
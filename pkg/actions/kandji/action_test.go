@@ -0,0 +1,57 @@
+package kandji_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/apple/pkl-go/pkl"
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/hatchery/pkg/actions/kandji"
+	"github.com/m-mizutani/hatchery/pkg/domain/config"
+	"github.com/m-mizutani/hatchery/pkg/domain/model"
+	"github.com/m-mizutani/hatchery/pkg/infra"
+	"github.com/m-mizutani/hatchery/pkg/infra/cs"
+	"github.com/m-mizutani/hatchery/pkg/utils"
+)
+
+func TestKandji(t *testing.T) {
+	var queries []string
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET example.api.kandji.io/api/v1/audit/events", func(w http.ResponseWriter, r *http.Request) {
+		gt.Equal(t, r.Header.Get("Authorization"), "Bearer test-token")
+		queries = append(queries, r.URL.RawQuery)
+		if r.URL.Query().Get("cursor") == "" {
+			_, _ = w.Write([]byte(`{"results":[{"id":"1"}],"next":"https://example.api.kandji.io/api/v1/audit/events?cursor=c1&limit=500","previous":null}`))
+		} else {
+			_, _ = w.Write([]byte(`{"results":[{"id":"2"}],"next":null,"previous":null}`))
+		}
+	})
+
+	mock := cs.NewMock()
+	clients := infra.New(infra.WithCloudStorage(mock), infra.WithHTTPClient(utils.NewFakeHTTPClient(mux)))
+
+	ctx := context.Background()
+	_, ctx = utils.CtxRequestID(ctx)
+	now := time.Date(2024, 4, 1, 10, 0, 0, 0, time.UTC)
+	ctx = utils.CtxWithNow(ctx, func() time.Time { return now })
+
+	req := &config.KandjiImpl{
+		ApiUrl:   "https://example.api.kandji.io",
+		ApiToken: "test-token",
+		Duration: &pkl.Duration{Value: 20, Unit: pkl.Minute},
+		Limit:    500,
+		Bucket:   "test-bucket",
+	}
+	gt.NoError(t, kandji.Exec(ctx, clients, req)).Must()
+
+	gt.A(t, queries).Equal([]string{
+		"end_date=2024-04-01T10%3A00%3A00Z&limit=500&sort_by=occurred_at&start_date=2024-04-01T09%3A40%3A00Z",
+		"cursor=c1&limit=500",
+	})
+	gt.A(t, mock.Results).Length(2).
+		At(1, func(t testing.TB, v *cs.MockResult) {
+			gt.Equal(t, v.Object, model.DefaultLogObjectName(ctx, req, now, 1))
+		})
+}
@@ -0,0 +1,240 @@
+package office365
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/m-mizutani/goerr"
+	"github.com/m-mizutani/hatchery/pkg/domain/config"
+	"github.com/m-mizutani/hatchery/pkg/domain/interfaces"
+	"github.com/m-mizutani/hatchery/pkg/domain/model"
+	"github.com/m-mizutani/hatchery/pkg/domain/types"
+	"github.com/m-mizutani/hatchery/pkg/infra"
+	"github.com/m-mizutani/hatchery/pkg/infra/entra"
+	"github.com/m-mizutani/hatchery/pkg/infra/oauth"
+	"github.com/m-mizutani/hatchery/pkg/infra/output"
+	"github.com/m-mizutani/hatchery/pkg/utils"
+)
+
+const (
+	tokenScope = "https://manage.office.com/.default"
+
+	// Office 365 Management Activity API endpoint. Tenant ID is appended.
+	// See https://learn.microsoft.com/en-us/office/office-365-management-api/office-365-management-activity-api-reference
+	baseURL = "https://manage.office.com/api/v1.0/"
+
+	// Time format for Management Activity API (UTC)
+	timeFormat = "2006-01-02T15:04:05"
+)
+
+func Exec(ctx context.Context, clients *infra.Clients, req *config.Office365Impl) error {
+	now := utils.CtxNow(ctx).UTC()
+
+	cred := entra.NewSecretCredential(clients.HTTPClient(), req.GetTenantId(), req.GetClientId(), req.GetClientSecret())
+	httpClient := oauth.NewClient(clients.HTTPClient(), cred.TokenSource(tokenScope))
+
+	if err := startSubscriptions(ctx, httpClient, req); err != nil {
+		return goerr.Wrap(err, "failed to start subscriptions of Office 365").With("req", req)
+	}
+
+	for _, contentType := range req.GetContentTypes() {
+		nextURL := contentListURL(req, contentType, now)
+		var seq int
+		for page := 0; req.MaxPages == nil || page < *req.MaxPages; page++ {
+			next, n, err := crawl(ctx, clients, req, httpClient, contentType, now, seq, nextURL)
+			if err != nil {
+				return goerr.Wrap(err, "failed to crawl Office 365 audit logs").With("contentType", contentType).With("page", page).With("req", req)
+			}
+			seq += n
+			if next == nil {
+				break
+			}
+			nextURL = *next
+		}
+	}
+
+	return nil
+}
+
+func feedURL(req *config.Office365Impl) string {
+	return baseURL + req.GetTenantId() + "/activity/feed"
+}
+
+func publisherID(req *config.Office365Impl) string {
+	if req.GetPublisherId() != nil {
+		return *req.GetPublisherId()
+	}
+	return req.GetTenantId()
+}
+
+func contentListURL(req *config.Office365Impl, contentType string, end time.Time) string {
+	start := end.Add(-req.GetDuration().GoDuration())
+
+	qv := url.Values{}
+	qv.Add("contentType", contentType)
+	qv.Add("startTime", start.Format(timeFormat))
+	qv.Add("endTime", end.Format(timeFormat))
+	qv.Add("PublisherIdentifier", publisherID(req))
+	return feedURL(req) + "/subscriptions/content?" + qv.Encode()
+}
+
+func startSubscriptions(ctx context.Context, httpClient interfaces.HTTPClient, req *config.Office365Impl) error {
+	qv := url.Values{}
+	qv.Add("PublisherIdentifier", publisherID(req))
+
+	listReq, err := newAPIRequest(ctx, http.MethodGet, feedURL(req)+"/subscriptions/list?"+qv.Encode())
+	if err != nil {
+		return err
+	}
+	var subscriptions []subscription
+	if err := doJSON(httpClient, listReq, &subscriptions); err != nil {
+		return err
+	}
+
+	enabled := map[string]bool{}
+	for _, s := range subscriptions {
+		enabled[s.ContentType] = s.Status == "enabled"
+	}
+
+	for _, contentType := range req.GetContentTypes() {
+		if enabled[contentType] {
+			continue
+		}
+
+		startQV := url.Values{}
+		startQV.Add("contentType", contentType)
+		startQV.Add("PublisherIdentifier", publisherID(req))
+		startReq, err := newAPIRequest(ctx, http.MethodPost, feedURL(req)+"/subscriptions/start?"+startQV.Encode())
+		if err != nil {
+			return err
+		}
+
+		var started subscription
+		if err := doJSON(httpClient, startReq, &started); err != nil {
+			return goerr.Wrap(err, "failed to start subscription").With("contentType", contentType)
+		}
+		utils.CtxLogger(ctx).Info("started Office 365 subscription", "contentType", contentType, "status", started.Status)
+	}
+
+	return nil
+}
+
+// crawl lists available content blobs in a page and downloads each of them into an object. It returns URL of the next page (nil if no more page) and the number of written objects.
+func crawl(ctx context.Context, clients *infra.Clients, req *config.Office365Impl, httpClient interfaces.HTTPClient, contentType string, end time.Time, seq int, pageURL string) (*string, int, error) {
+	listReq, err := newAPIRequest(ctx, http.MethodGet, pageURL)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	httpResp, err := httpClient.Do(listReq)
+	if err != nil {
+		return nil, 0, goerr.Wrap(err, "failed to send HTTP request")
+	}
+	defer utils.SafeClose(httpResp.Body)
+
+	if httpResp.StatusCode != http.StatusOK {
+		data, _ := io.ReadAll(httpResp.Body)
+		return nil, 0, goerr.New("unexpected status code").With("status", httpResp.Status).With("body", string(data))
+	}
+
+	var blobs []contentBlob
+	if err := json.NewDecoder(httpResp.Body).Decode(&blobs); err != nil {
+		return nil, 0, goerr.Wrap(err, "failed to unmarshal content list")
+	}
+
+	for i, blob := range blobs {
+		objName := model.DatasetLogObjectName(ctx, req, contentType, end, seq+i)
+		if err := download(ctx, clients, req, httpClient, blob, objName, end); err != nil {
+			return nil, i, goerr.Wrap(err, "failed to download content blob").With("contentId", blob.ContentID)
+		}
+	}
+
+	if next := httpResp.Header.Get("NextPageUri"); next != "" {
+		return &next, len(blobs), nil
+	}
+	return nil, len(blobs), nil
+}
+
+func download(ctx context.Context, clients *infra.Clients, req *config.Office365Impl, httpClient interfaces.HTTPClient, blob contentBlob, objName types.CSObjectName, end time.Time) error {
+	blobURL, err := url.Parse(blob.ContentURI)
+	if err != nil {
+		return goerr.Wrap(err, "failed to parse contentUri").With("contentUri", blob.ContentURI)
+	}
+	qv := blobURL.Query()
+	qv.Set("PublisherIdentifier", publisherID(req))
+	blobURL.RawQuery = qv.Encode()
+
+	httpReq, err := newAPIRequest(ctx, http.MethodGet, blobURL.String())
+	if err != nil {
+		return err
+	}
+
+	httpResp, err := httpClient.Do(httpReq)
+	if err != nil {
+		return goerr.Wrap(err, "failed to send HTTP request")
+	}
+	defer utils.SafeClose(httpResp.Body)
+
+	if httpResp.StatusCode != http.StatusOK {
+		data, _ := io.ReadAll(httpResp.Body)
+		return goerr.New("unexpected status code").With("status", httpResp.Status).With("body", string(data))
+	}
+
+	w, err := output.NewLogObjectWriter(ctx, clients.CloudStorage(), req, objName, output.WithWindow(end.Add(-req.GetDuration().GoDuration()), end))
+	if err != nil {
+		return err
+	}
+
+	n, err := io.Copy(w, httpResp.Body)
+	if err != nil {
+		w.Abort()
+		return goerr.Wrap(err, "failed to write response to object writer").With("bytes", n)
+	}
+	if err := w.Close(); err != nil {
+		return goerr.Wrap(err, "failed to close object writer").With("object", objName)
+	}
+
+	utils.CtxLogger(ctx).Info("harvested Office 365 audit logs", "contentType", blob.ContentType, "contentId", blob.ContentID, "bytes", n, "object", w.Object())
+	return nil
+}
+
+func newAPIRequest(ctx context.Context, method, apiURL string) (*http.Request, error) {
+	httpReq, err := http.NewRequestWithContext(ctx, method, apiURL, nil)
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to create HTTP request").With("url", apiURL)
+	}
+	return httpReq, nil
+}
+
+func doJSON(httpClient interfaces.HTTPClient, httpReq *http.Request, out any) error {
+	httpResp, err := httpClient.Do(httpReq)
+	if err != nil {
+		return goerr.Wrap(err, "failed to send HTTP request").With("url", httpReq.URL.String())
+	}
+	defer utils.SafeClose(httpResp.Body)
+
+	if httpResp.StatusCode != http.StatusOK {
+		data, _ := io.ReadAll(httpResp.Body)
+		return goerr.New("unexpected status code").With("status", httpResp.Status).With("body", string(data)).With("url", httpReq.URL.String())
+	}
+
+	if err := json.NewDecoder(httpResp.Body).Decode(out); err != nil {
+		return goerr.Wrap(err, "failed to unmarshal response body").With("url", httpReq.URL.String())
+	}
+	return nil
+}
+
+type subscription struct {
+	ContentType string `json:"contentType"`
+	Status      string `json:"status"`
+}
+
+type contentBlob struct {
+	ContentType string `json:"contentType"`
+	ContentID   string `json:"contentId"`
+	ContentURI  string `json:"contentUri"`
+}
@@ -0,0 +1,100 @@
+package office365_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/apple/pkl-go/pkl"
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/hatchery/pkg/actions/office365"
+	"github.com/m-mizutani/hatchery/pkg/domain/config"
+	"github.com/m-mizutani/hatchery/pkg/domain/model"
+	"github.com/m-mizutani/hatchery/pkg/infra"
+	"github.com/m-mizutani/hatchery/pkg/infra/cs"
+	"github.com/m-mizutani/hatchery/pkg/utils"
+)
+
+const tenantID = "00000000-0000-0000-0000-000000000001"
+
+func TestOffice365(t *testing.T) {
+	var started []string
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST login.microsoftonline.com/"+tenantID+"/oauth2/v2.0/token", func(w http.ResponseWriter, r *http.Request) {
+		gt.NoError(t, r.ParseForm())
+		gt.Equal(t, r.PostForm.Get("grant_type"), "client_credentials")
+		gt.Equal(t, r.PostForm.Get("client_secret"), "test-secret")
+		gt.Equal(t, r.PostForm.Get("scope"), "https://manage.office.com/.default")
+		_, _ = w.Write([]byte(`{"access_token":"test-token","expires_in":3600,"token_type":"Bearer"}`))
+	})
+
+	feed := "manage.office.com/api/v1.0/" + tenantID + "/activity/feed"
+	mux.HandleFunc("GET "+feed+"/subscriptions/list", func(w http.ResponseWriter, r *http.Request) {
+		gt.Equal(t, r.Header.Get("Authorization"), "Bearer test-token")
+		_, _ = w.Write([]byte(`[{"contentType":"Audit.General","status":"enabled"},{"contentType":"Audit.Exchange","status":"disabled"}]`))
+	})
+	mux.HandleFunc("POST "+feed+"/subscriptions/start", func(w http.ResponseWriter, r *http.Request) {
+		started = append(started, r.URL.Query().Get("contentType"))
+		_, _ = w.Write([]byte(`{"contentType":"Audit.Exchange","status":"enabled"}`))
+	})
+	mux.HandleFunc("GET "+feed+"/subscriptions/content", func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		gt.Equal(t, q.Get("startTime"), "2024-04-01T09:00:00")
+		gt.Equal(t, q.Get("endTime"), "2024-04-01T10:00:00")
+		gt.Equal(t, q.Get("PublisherIdentifier"), tenantID)
+
+		contentType := q.Get("contentType")
+		if q.Get("nextPage") == "" {
+			w.Header().Set("NextPageUri", "https://"+feed+"/subscriptions/content?contentType="+contentType+"&startTime=2024-04-01T09:00:00&endTime=2024-04-01T10:00:00&PublisherIdentifier="+tenantID+"&nextPage=2")
+			_, _ = w.Write([]byte(`[{"contentType":"` + contentType + `","contentId":"c1","contentUri":"https://` + feed + `/audit/c1"}]`))
+		} else {
+			_, _ = w.Write([]byte(`[{"contentType":"` + contentType + `","contentId":"c2","contentUri":"https://` + feed + `/audit/c2"}]`))
+		}
+	})
+	mux.HandleFunc("GET "+feed+"/audit/{id}", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`[{"Id":"` + r.PathValue("id") + `","Operation":"UserLoggedIn"}]`))
+	})
+
+	mock := cs.NewMock()
+	clients := infra.New(infra.WithCloudStorage(mock), infra.WithHTTPClient(utils.NewFakeHTTPClient(mux)))
+
+	ctx := context.Background()
+	_, ctx = utils.CtxRequestID(ctx)
+	now := time.Date(2024, 4, 1, 10, 0, 0, 0, time.UTC)
+	ctx = utils.CtxWithNow(ctx, func() time.Time { return now })
+
+	req := &config.Office365Impl{
+		TenantId:     tenantID,
+		ClientId:     "00000000-0000-0000-0000-000000000002",
+		ClientSecret: "test-secret",
+		ContentTypes: []string{"Audit.General", "Audit.Exchange"},
+		Duration:     &pkl.Duration{Value: 1, Unit: pkl.Hour},
+		Bucket:       "test-bucket",
+	}
+	gt.NoError(t, office365.Exec(ctx, clients, req)).Must()
+
+	gt.A(t, started).Equal([]string{"Audit.Exchange"})
+	gt.A(t, mock.Results).Length(4).
+		At(0, func(t testing.TB, v *cs.MockResult) {
+			gt.Equal(t, v.Object, model.DatasetLogObjectName(ctx, req, "Audit.General", now, 0))
+			gt.Equal(t, v.Body.Closed, true)
+
+			var events []map[string]any
+			r := gt.R1(gzip.NewReader(bytes.NewReader(v.Body.Bytes()))).NoError(t)
+			raw := gt.R1(io.ReadAll(r)).NoError(t)
+			gt.NoError(t, json.Unmarshal(raw, &events))
+			gt.A(t, events).Length(1)
+			gt.Equal(t, events[0]["Id"], "c1")
+		}).
+		At(1, func(t testing.TB, v *cs.MockResult) {
+			gt.Equal(t, v.Object, model.DatasetLogObjectName(ctx, req, "Audit.General", now, 1))
+		}).
+		At(3, func(t testing.TB, v *cs.MockResult) {
+			gt.Equal(t, v.Object, model.DatasetLogObjectName(ctx, req, "Audit.Exchange", now, 1))
+		})
+}
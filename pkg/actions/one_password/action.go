@@ -2,7 +2,6 @@ package one_password
 
 import (
 	"bytes"
-	"compress/gzip"
 	"context"
 	"encoding/json"
 	"io"
@@ -11,16 +10,20 @@ import (
 
 	"github.com/m-mizutani/goerr"
 	"github.com/m-mizutani/hatchery/pkg/domain/config"
+	"github.com/m-mizutani/hatchery/pkg/domain/interfaces"
 	"github.com/m-mizutani/hatchery/pkg/domain/model"
 	"github.com/m-mizutani/hatchery/pkg/domain/types"
 	"github.com/m-mizutani/hatchery/pkg/infra"
+	"github.com/m-mizutani/hatchery/pkg/infra/dedup"
+	"github.com/m-mizutani/hatchery/pkg/infra/oauth"
+	"github.com/m-mizutani/hatchery/pkg/infra/output"
 	"github.com/m-mizutani/hatchery/pkg/utils"
 )
 
 const (
-	// 1Password API endpoint for Business Plan
+	// Default 1Password Events API base URL for Business Plan. It can be changed by base_url for other regions.
 	// See https://developer.1password.com/docs/events-api/reference/
-	APIEndpoint = "https://events.1password.com/api/v1/auditevents"
+	DefaultBaseURL = "https://events.1password.com"
 
 	// Time format for 1Password API
 	// 2023-03-15T16:32:50-03:00
@@ -28,32 +31,45 @@ const (
 )
 
 func Exec(ctx context.Context, clients *infra.Clients, req *config.OnePasswordImpl) error {
-	var nextCursor string
+	httpClient := oauth.NewClient(clients.HTTPClient(), oauth.StaticToken(req.GetApiToken()))
 	now := utils.CtxNow(ctx)
 
-	for seq := 0; req.MaxPages == nil || seq < *req.MaxPages; seq++ {
-		cursor, err := crawl(ctx, clients, req, now, seq, nextCursor)
-		if err != nil {
-			return goerr.Wrap(err, "failed to crawl 1Password logs").With("seq", seq).With("cursor", nextCursor).With("req", req)
-		}
-		if cursor == nil {
-			break
+	seen, err := dedup.Load(ctx, clients.StateStorage(), req, req.GetDedup())
+	if err != nil {
+		return goerr.Wrap(err, "failed to load dedup partitions").With("req", req)
+	}
+
+	for _, event := range req.GetEvents() {
+		var nextCursor string
+		for seq := 0; req.MaxPages == nil || seq < *req.MaxPages; seq++ {
+			cursor, err := crawl(ctx, clients, httpClient, req, seen, event, now, seq, nextCursor)
+			if err != nil {
+				return goerr.Wrap(err, "failed to crawl 1Password logs").With("event", event).With("seq", seq).With("cursor", nextCursor).With("req", req)
+			}
+			if cursor == nil {
+				break
+			}
+			nextCursor = *cursor
 		}
-		nextCursor = *cursor
+	}
+
+	if err := seen.Save(ctx); err != nil {
+		return goerr.Wrap(err, "failed to save dedup partition").With("req", req)
 	}
 
 	return nil
 }
 
-func crawl(ctx context.Context, clients *infra.Clients, req *config.OnePasswordImpl, end time.Time, seq int, cursor string) (*string, error) {
-	d := req.GetDuration().GoDuration()
+// objectName returns object name of the event stream. auditevents keeps the object name before other streams were supported, so that existing consumers of the bucket are not affected.
+func objectName(ctx context.Context, req *config.OnePasswordImpl, event string, end time.Time, seq int) types.CSObjectName {
+	if event == "auditevents" {
+		return model.DefaultLogObjectName(ctx, req, end, seq)
+	}
+	return model.DatasetLogObjectName(ctx, req, event, end, seq)
+}
 
-	objName := model.DefaultLogObjectName(ctx, req, end, seq)
-	objWriter := clients.CloudStorage().NewObjectWriter(ctx,
-		types.CSBucket(req.GetBucket()),
-		objName,
-	)
-	w := gzip.NewWriter(objWriter)
+func crawl(ctx context.Context, clients *infra.Clients, httpClient interfaces.HTTPClient, req *config.OnePasswordImpl, seen *dedup.Set, event string, end time.Time, seq int, cursor string) (*string, error) {
+	d := req.GetDuration().GoDuration()
 
 	startTime := end.Add(-d)
 	var body []byte
@@ -76,15 +92,20 @@ func crawl(ctx context.Context, clients *infra.Clients, req *config.OnePasswordI
 	}
 	reader := bytes.NewReader(body)
 
-	httpReq, err := http.NewRequest(http.MethodPost, APIEndpoint, reader)
+	baseURL := req.GetBaseUrl()
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
+	apiURL := baseURL + "/api/v1/" + event
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, apiURL, reader)
 	if err != nil {
 		return nil, goerr.Wrap(err, "failed to create HTTP request")
 	}
 
 	httpReq.Header.Set("Content-Type", "application/json")
-	httpReq.Header.Set("Authorization", "Bearer "+req.GetApiToken())
 
-	httpResp, err := clients.HTTPClient().Do(httpReq)
+	httpResp, err := httpClient.Do(httpReq)
 	if err != nil {
 		return nil, goerr.Wrap(err, "failed to send HTTP request")
 	}
@@ -104,20 +125,29 @@ func crawl(ctx context.Context, clients *infra.Clients, req *config.OnePasswordI
 		return nil, goerr.Wrap(err, "failed to unmarshal response body")
 	}
 
+	body, dropped, err := seen.Filter(body, "items", "uuid")
+	if err != nil {
+		return nil, err
+	}
+
+	objName := objectName(ctx, req, event, end, seq)
+	w, err := output.NewLogObjectWriter(ctx, clients.CloudStorage(), req, objName, output.WithWindow(startTime, end))
+	if err != nil {
+		return nil, err
+	}
+
 	n, err := io.Copy(w, bytes.NewReader(body))
 	if err != nil {
+		w.Abort()
 		return nil, goerr.Wrap(err, "failed to write response to object writer").With("bytes", n)
 	}
 
-	utils.CtxLogger(ctx).Info("harvested 1Password logs", "bytes", n, "object", objName, "cursor", resp.Cursor, "hasMore", resp.HasMore)
-
 	if err := w.Close(); err != nil {
-		return nil, goerr.Wrap(err, "failed to close gzip writer").With("object", objName)
-	}
-	if err := objWriter.Close(); err != nil {
 		return nil, goerr.Wrap(err, "failed to close object writer").With("object", objName)
 	}
 
+	utils.CtxLogger(ctx).Info("harvested 1Password logs", "event", event, "bytes", n, "duplicates", dropped, "object", w.Object(), "cursor", resp.Cursor, "hasMore", resp.HasMore)
+
 	if resp.HasMore {
 		return &resp.Cursor, nil
 	}
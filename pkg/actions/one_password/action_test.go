@@ -5,6 +5,9 @@ import (
 	"compress/gzip"
 	"context"
 	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
 	"testing"
 	"time"
 
@@ -38,10 +41,13 @@ func TestOnePassword(t *testing.T) {
 		},
 		Limit:    10,
 		MaxPages: &maxPages,
+		Events:   []string{"auditevents"},
+		BaseUrl:  one_password.DefaultBaseURL,
 	}
 
 	gt.NoError(t, one_password.Exec(ctx, clients, req)).Must()
 
+	// auditevents keeps the legacy object name
 	expectedPath := model.DefaultLogObjectName(ctx, req, now, 0)
 	r0 := mock.Results[0]
 	gt.Equal(t, r0.Bucket, "test-bucket")
@@ -57,6 +63,123 @@ func TestOnePassword(t *testing.T) {
 	})
 }
 
+type mockHTTPClient struct {
+	requests []*http.Request
+	bodies   []string
+}
+
+func (x *mockHTTPClient) Do(req *http.Request) (*http.Response, error) {
+	x.requests = append(x.requests, req)
+	body, _ := io.ReadAll(req.Body)
+	x.bodies = append(x.bodies, string(body))
+
+	var resp string
+	switch {
+	case strings.Contains(string(body), `"cursor"`):
+		resp = `{"cursor":"c2","has_more":false,"items":[{"uuid":"2"}]}`
+	default:
+		resp = `{"cursor":"c1","has_more":true,"items":[{"uuid":"1"}]}`
+	}
+
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Body:       io.NopCloser(strings.NewReader(resp)),
+	}, nil
+}
+
+func TestOnePasswordEvents(t *testing.T) {
+	mock := cs.NewMock()
+	httpClient := &mockHTTPClient{}
+	clients := infra.New(infra.WithCloudStorage(mock), infra.WithHTTPClient(httpClient))
+
+	ctx := context.Background()
+	_, ctx = utils.CtxRequestID(ctx)
+	now := time.Date(2024, 4, 1, 10, 0, 0, 0, time.UTC)
+	ctx = utils.CtxWithNow(ctx, func() time.Time { return now })
+
+	req := &config.OnePasswordImpl{
+		ApiToken: "test-token",
+		Bucket:   "test-bucket",
+		Duration: &pkl.Duration{
+			Value: 1,
+			Unit:  pkl.Hour,
+		},
+		Limit:   10,
+		Events:  []string{"signinattempts", "itemusages"},
+		BaseUrl: "https://events.1password.eu",
+	}
+	gt.NoError(t, one_password.Exec(ctx, clients, req)).Must()
+
+	gt.A(t, httpClient.requests).Length(4).
+		At(0, func(t testing.TB, v *http.Request) {
+			gt.Equal(t, v.URL.String(), "https://events.1password.eu/api/v1/signinattempts")
+			gt.Equal(t, v.Header.Get("Authorization"), "Bearer test-token")
+		}).
+		At(2, func(t testing.TB, v *http.Request) {
+			gt.Equal(t, v.URL.String(), "https://events.1password.eu/api/v1/itemusages")
+		})
+	gt.A(t, httpClient.bodies).Length(4).
+		At(0, func(t testing.TB, v string) {
+			gt.S(t, v).Contains(`"start_time":"2024-04-01T09:00:00+00:00"`)
+		}).
+		At(1, func(t testing.TB, v string) {
+			gt.Equal(t, v, `{"cursor":"c1"}`)
+		})
+
+	gt.A(t, mock.Results).Length(4).
+		At(0, func(t testing.TB, v *cs.MockResult) {
+			gt.Equal(t, v.Object, model.DatasetLogObjectName(ctx, req, "signinattempts", now, 0))
+		}).
+		At(3, func(t testing.TB, v *cs.MockResult) {
+			gt.Equal(t, v.Object, model.DatasetLogObjectName(ctx, req, "itemusages", now, 1))
+		})
+}
+
+func TestOnePasswordDedup(t *testing.T) {
+	mock := cs.NewMock()
+	clients := infra.New(infra.WithCloudStorage(mock), infra.WithHTTPClient(&mockHTTPClient{}))
+
+	req := &config.OnePasswordImpl{
+		Id:       "test",
+		ApiToken: "test-token",
+		Bucket:   "test-bucket",
+		Duration: &pkl.Duration{
+			Value: 1,
+			Unit:  pkl.Hour,
+		},
+		Limit:   10,
+		Events:  []string{"auditevents"},
+		BaseUrl: one_password.DefaultBaseURL,
+		Dedup:   &config.Dedup{Retention: &pkl.Duration{Value: 24, Unit: pkl.Hour}},
+	}
+
+	now := time.Date(2024, 4, 1, 10, 0, 0, 0, time.UTC)
+	exec := func(now time.Time) {
+		_, ctx := utils.CtxRequestID(context.Background())
+		ctx = utils.CtxWithNow(ctx, func() time.Time { return now })
+		gt.NoError(t, one_password.Exec(ctx, clients, req)).Must()
+	}
+	items := func(v *cs.MockResult) []Item {
+		var resp apiResponse
+		r := gt.R1(gzip.NewReader(bytes.NewReader(v.Body.Bytes()))).NoError(t)
+		gt.NoError(t, json.NewDecoder(r).Decode(&resp))
+		return resp.Items
+	}
+
+	// 2 log objects and the dedup partition
+	exec(now)
+	gt.A(t, mock.Results).Length(3).At(2, func(t testing.TB, v *cs.MockResult) {
+		gt.Equal(t, v.Object, "dedup/test/2024/04/01/10.json.gz")
+	})
+	gt.A(t, items(mock.Results[0])).Length(1)
+
+	// Events of the overlapping window are dropped in the next hour
+	exec(now.Add(time.Hour))
+	gt.A(t, mock.Results).Length(5)
+	gt.A(t, items(mock.Results[3])).Length(0)
+	gt.A(t, items(mock.Results[4])).Length(0)
+}
+
 type apiResponse struct {
 	Cursor  string `json:"cursor"`
 	HasMore bool   `json:"has_more"`
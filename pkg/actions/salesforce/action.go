@@ -0,0 +1,240 @@
+package salesforce
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/m-mizutani/goerr"
+	"github.com/m-mizutani/hatchery/pkg/domain/config"
+	"github.com/m-mizutani/hatchery/pkg/domain/interfaces"
+	"github.com/m-mizutani/hatchery/pkg/domain/model"
+	"github.com/m-mizutani/hatchery/pkg/domain/types"
+	"github.com/m-mizutani/hatchery/pkg/infra"
+	"github.com/m-mizutani/hatchery/pkg/infra/oauth"
+	"github.com/m-mizutani/hatchery/pkg/infra/output"
+	"github.com/m-mizutani/hatchery/pkg/utils"
+)
+
+const (
+	// OAuth 2.0 JWT bearer flow.
+	// See https://help.salesforce.com/s/articleView?id=sf.remoteaccess_oauth_jwt_flow.htm
+	jwtBearerGrantType = "urn:ietf:params:oauth:grant-type:jwt-bearer"
+
+	// Lifetime of JWT for authorization grant. Salesforce accepts up to 3 minutes.
+	assertionLifetime = 3 * time.Minute
+)
+
+func Exec(ctx context.Context, clients *infra.Clients, req *config.SalesforceEventLogImpl) error {
+	sess, err := authenticate(ctx, clients.HTTPClient(), req)
+	if err != nil {
+		return goerr.Wrap(err, "failed to authenticate Salesforce").With("req", req)
+	}
+	httpClient := oauth.NewClient(clients.HTTPClient(), oauth.StaticToken(sess.AccessToken))
+
+	now := utils.CtxNow(ctx)
+	nextURL := sess.InstanceURL + "/services/data/" + req.GetApiVersion() + "/query?" + url.Values{"q": {buildQuery(req, now)}}.Encode()
+
+	for seq := 0; req.MaxPages == nil || seq < *req.MaxPages; seq++ {
+		resp, err := query(ctx, httpClient, nextURL)
+		if err != nil {
+			return goerr.Wrap(err, "failed to query EventLogFile").With("seq", seq).With("url", nextURL).With("req", req)
+		}
+
+		for _, record := range resp.Records {
+			if err := download(ctx, clients, httpClient, req, sess.InstanceURL, record); err != nil {
+				return goerr.Wrap(err, "failed to download event log file").With("id", record.ID).With("req", req)
+			}
+		}
+
+		if resp.Done || resp.NextRecordsURL == "" {
+			break
+		}
+		nextURL = sess.InstanceURL + resp.NextRecordsURL
+	}
+
+	return nil
+}
+
+type session struct {
+	AccessToken string `json:"access_token"`
+	InstanceURL string `json:"instance_url"`
+}
+
+// authenticate exchanges JWT signed by the private key of connected app for access token. The token endpoint does not return refresh token nor expiry, then the session is used only within one execution.
+func authenticate(ctx context.Context, client interfaces.HTTPClient, req *config.SalesforceEventLogImpl) (*session, error) {
+	key, err := oauth.ParseRSAPrivateKey([]byte(req.GetPrivateKey()))
+	if err != nil {
+		return nil, err
+	}
+
+	now := utils.CtxNow(ctx)
+	assertion, err := oauth.SignJWT(key, nil, map[string]any{
+		"iss": req.GetClientId(),
+		"sub": req.GetUsername(),
+		"aud": req.GetLoginUrl(),
+		"exp": now.Add(assertionLifetime).Unix(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	form := url.Values{}
+	form.Add("grant_type", jwtBearerGrantType)
+	form.Add("assertion", assertion)
+
+	tokenURL := req.GetLoginUrl() + "/services/oauth2/token"
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to create token request")
+	}
+	httpReq.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	httpResp, err := client.Do(httpReq)
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to send token request")
+	}
+	defer utils.SafeClose(httpResp.Body)
+
+	if httpResp.StatusCode != http.StatusOK {
+		data, _ := io.ReadAll(httpResp.Body)
+		return nil, goerr.New("unexpected status code of token endpoint").With("status", httpResp.Status).With("body", string(data))
+	}
+
+	var sess session
+	if err := json.NewDecoder(httpResp.Body).Decode(&sess); err != nil {
+		return nil, goerr.Wrap(err, "failed to unmarshal token response")
+	}
+	if sess.AccessToken == "" || sess.InstanceURL == "" {
+		return nil, goerr.New("empty access token or instance URL")
+	}
+	sess.InstanceURL = strings.TrimSuffix(sess.InstanceURL, "/")
+
+	return &sess, nil
+}
+
+// buildQuery returns SOQL to list EventLogFile of the window. LogDate is the start time of the log period.
+func buildQuery(req *config.SalesforceEventLogImpl, end time.Time) string {
+	start := end.Add(-req.GetDuration().GoDuration())
+
+	conds := []string{
+		"LogDate >= " + start.UTC().Format(time.RFC3339),
+		"LogDate < " + end.UTC().Format(time.RFC3339),
+		"Interval = '" + req.GetInterval() + "'",
+	}
+	if req.EventTypes != nil && len(*req.EventTypes) > 0 {
+		// event_types are restricted to alphanumeric by config, then quoting is safe
+		conds = append(conds, "EventType IN ('"+strings.Join(*req.EventTypes, "','")+"')")
+	}
+
+	return "SELECT Id, EventType, LogDate, Interval, LogFileLength, LogFile FROM EventLogFile WHERE " +
+		strings.Join(conds, " AND ") + " ORDER BY LogDate"
+}
+
+func query(ctx context.Context, httpClient interfaces.HTTPClient, queryURL string) (*queryResponse, error) {
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, queryURL, nil)
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to create HTTP request")
+	}
+	httpReq.Header.Set("Accept", "application/json")
+
+	httpResp, err := httpClient.Do(httpReq)
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to send HTTP request")
+	}
+	defer utils.SafeClose(httpResp.Body)
+
+	if httpResp.StatusCode != http.StatusOK {
+		data, _ := io.ReadAll(httpResp.Body)
+		return nil, goerr.New("unexpected status code").With("status", httpResp.Status).With("body", string(data))
+	}
+
+	var resp queryResponse
+	if err := json.NewDecoder(httpResp.Body).Decode(&resp); err != nil {
+		return nil, goerr.Wrap(err, "failed to unmarshal query response")
+	}
+	return &resp, nil
+}
+
+// download streams CSV of LogFile into the object without buffering whole of the file.
+func download(ctx context.Context, clients *infra.Clients, httpClient interfaces.HTTPClient, req *config.SalesforceEventLogImpl, instanceURL string, record eventLogFile) error {
+	logDate, err := time.Parse(logDateLayout, record.LogDate)
+	if err != nil {
+		return goerr.Wrap(err, "failed to parse LogDate").With("logDate", record.LogDate)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, instanceURL+record.LogFile, nil)
+	if err != nil {
+		return goerr.Wrap(err, "failed to create HTTP request")
+	}
+
+	httpResp, err := httpClient.Do(httpReq)
+	if err != nil {
+		return goerr.Wrap(err, "failed to send HTTP request")
+	}
+	defer utils.SafeClose(httpResp.Body)
+
+	if httpResp.StatusCode != http.StatusOK {
+		data, _ := io.ReadAll(httpResp.Body)
+		return goerr.New("unexpected status code").With("status", httpResp.Status).With("body", string(data))
+	}
+
+	objName := ObjectName(req, record.EventType, logDate, record.ID)
+	w, err := output.NewObjectWriter(ctx, clients.CloudStorage(), req, objName, output.WithWindow(logDate, logDate.Add(logInterval(record.Interval))), types.WithContentType(output.ContentTypeCSV))
+	if err != nil {
+		return err
+	}
+
+	n, err := io.Copy(w, httpResp.Body)
+	if err != nil {
+		w.Abort()
+		return goerr.Wrap(err, "failed to write log file to object writer").With("bytes", n)
+	}
+	if err := w.Close(); err != nil {
+		return goerr.Wrap(err, "failed to close object writer").With("object", objName)
+	}
+
+	utils.CtxLogger(ctx).Info("harvested Salesforce event log file", "id", record.ID, "eventType", record.EventType, "bytes", n, "object", objName)
+
+	return nil
+}
+
+// ObjectName returns object name derived from EventLogFile ID instead of execution time, so the same log file is always stored into the same object even if windows of executions overlap.
+func ObjectName(req *config.SalesforceEventLogImpl, eventType string, logDate time.Time, id string) types.CSObjectName {
+	name := eventType + "/" + logDate.UTC().Format("logs/2006/01/02/") + id + ".csv" + model.ObjectExt(req)
+	if prefix := req.GetPrefix(); prefix != nil {
+		name = *prefix + name
+	}
+	return types.CSObjectName(name)
+}
+
+// logInterval returns period of a log file by Interval of EventLogFile.
+func logInterval(interval string) time.Duration {
+	if interval == "Hourly" {
+		return time.Hour
+	}
+	return 24 * time.Hour
+}
+
+// LogDate format of Salesforce REST API (e.g. 2024-04-01T09:00:00.000+0000)
+const logDateLayout = "2006-01-02T15:04:05.000-0700"
+
+type eventLogFile struct {
+	ID            string  `json:"Id"`
+	EventType     string  `json:"EventType"`
+	LogDate       string  `json:"LogDate"`
+	Interval      string  `json:"Interval"`
+	LogFileLength float64 `json:"LogFileLength"`
+	LogFile       string  `json:"LogFile"`
+}
+
+type queryResponse struct {
+	TotalSize      int            `json:"totalSize"`
+	Done           bool           `json:"done"`
+	NextRecordsURL string         `json:"nextRecordsUrl"`
+	Records        []eventLogFile `json:"records"`
+}
@@ -0,0 +1,113 @@
+package salesforce_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/apple/pkl-go/pkl"
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/hatchery/pkg/actions/salesforce"
+	"github.com/m-mizutani/hatchery/pkg/domain/config"
+	"github.com/m-mizutani/hatchery/pkg/infra"
+	"github.com/m-mizutani/hatchery/pkg/infra/cs"
+	"github.com/m-mizutani/hatchery/pkg/utils"
+)
+
+func TestSalesforceEventLog(t *testing.T) {
+	key := gt.R1(rsa.GenerateKey(rand.Reader, 2048)).NoError(t)
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+
+	// Fake Salesforce server that works as both of login and instance
+	var srv *httptest.Server
+	var queries []string
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /services/oauth2/token", func(w http.ResponseWriter, r *http.Request) {
+		gt.NoError(t, r.ParseForm())
+		gt.Equal(t, r.PostForm.Get("grant_type"), "urn:ietf:params:oauth:grant-type:jwt-bearer")
+
+		parts := strings.Split(r.PostForm.Get("assertion"), ".")
+		gt.A(t, parts).Length(3)
+		digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+		sig := gt.R1(base64.RawURLEncoding.DecodeString(parts[2])).NoError(t)
+		gt.NoError(t, rsa.VerifyPKCS1v15(&key.PublicKey, crypto.SHA256, digest[:], sig))
+
+		var claims map[string]any
+		gt.NoError(t, json.Unmarshal(gt.R1(base64.RawURLEncoding.DecodeString(parts[1])).NoError(t), &claims))
+		gt.Equal(t, claims["iss"], any("test-client"))
+		gt.Equal(t, claims["sub"], any("user@example.com"))
+		gt.Equal(t, claims["aud"], any(srv.URL))
+
+		_, _ = w.Write([]byte(`{"access_token":"test-token","instance_url":"` + srv.URL + `"}`))
+	})
+	mux.HandleFunc("GET /services/data/v60.0/query", func(w http.ResponseWriter, r *http.Request) {
+		gt.Equal(t, r.Header.Get("Authorization"), "Bearer test-token")
+		queries = append(queries, r.URL.Query().Get("q"))
+		_, _ = w.Write([]byte(`{"totalSize":2,"done":false,"nextRecordsUrl":"/services/data/v60.0/query/01g-2000","records":[
+			{"Id":"0AT000000000001","EventType":"Login","LogDate":"2024-04-01T08:00:00.000+0000","Interval":"Hourly","LogFileLength":20,"LogFile":"/services/data/v60.0/sobjects/EventLogFile/0AT000000000001/LogFile"}
+		]}`))
+	})
+	mux.HandleFunc("GET /services/data/v60.0/query/01g-2000", func(w http.ResponseWriter, r *http.Request) {
+		gt.Equal(t, r.Header.Get("Authorization"), "Bearer test-token")
+		_, _ = w.Write([]byte(`{"totalSize":2,"done":true,"records":[
+			{"Id":"0AT000000000002","EventType":"API","LogDate":"2024-04-01T09:00:00.000+0000","Interval":"Hourly","LogFileLength":20,"LogFile":"/services/data/v60.0/sobjects/EventLogFile/0AT000000000002/LogFile"}
+		]}`))
+	})
+	mux.HandleFunc("GET /services/data/v60.0/sobjects/EventLogFile/{id}/LogFile", func(w http.ResponseWriter, r *http.Request) {
+		gt.Equal(t, r.Header.Get("Authorization"), "Bearer test-token")
+		w.Header().Set("Content-Type", "text/csv")
+		_, _ = w.Write([]byte("\"EVENT_TYPE\",\"ID\"\n\"x\",\"" + r.PathValue("id") + "\"\n"))
+	})
+	srv = httptest.NewTLSServer(mux)
+	defer srv.Close()
+
+	mock := cs.NewMock()
+	clients := infra.New(infra.WithCloudStorage(mock), infra.WithHTTPClient(srv.Client()))
+
+	ctx := context.Background()
+	_, ctx = utils.CtxRequestID(ctx)
+	now := time.Date(2024, 4, 2, 0, 0, 0, 0, time.UTC)
+	ctx = utils.CtxWithNow(ctx, func() time.Time { return now })
+
+	prefix := "sf/"
+	req := &config.SalesforceEventLogImpl{
+		LoginUrl:   srv.URL,
+		ClientId:   "test-client",
+		Username:   "user@example.com",
+		PrivateKey: string(keyPEM),
+		ApiVersion: "v60.0",
+		EventTypes: &[]string{"Login", "API"},
+		Interval:   "Hourly",
+		Duration:   &pkl.Duration{Value: 1, Unit: pkl.Day},
+		Bucket:     "test-bucket",
+		Prefix:     &prefix,
+	}
+	gt.NoError(t, salesforce.Exec(ctx, clients, req)).Must()
+
+	gt.A(t, queries).Equal([]string{
+		"SELECT Id, EventType, LogDate, Interval, LogFileLength, LogFile FROM EventLogFile WHERE LogDate >= 2024-04-01T00:00:00Z AND LogDate < 2024-04-02T00:00:00Z AND Interval = 'Hourly' AND EventType IN ('Login','API') ORDER BY LogDate",
+	})
+	gt.A(t, mock.Results).Length(2).
+		At(0, func(t testing.TB, v *cs.MockResult) {
+			gt.Equal(t, v.Object, "sf/Login/logs/2024/04/01/0AT000000000001.csv.gz")
+			r := gt.R1(gzip.NewReader(bytes.NewReader(v.Body.Bytes()))).NoError(t)
+			gt.Equal(t, string(gt.R1(io.ReadAll(r)).NoError(t)), "\"EVENT_TYPE\",\"ID\"\n\"x\",\"0AT000000000001\"\n")
+		}).
+		At(1, func(t testing.TB, v *cs.MockResult) {
+			gt.Equal(t, v.Object, salesforce.ObjectName(req, "API", time.Date(2024, 4, 1, 9, 0, 0, 0, time.UTC), "0AT000000000002"))
+		})
+}
@@ -2,31 +2,41 @@ package slack
 
 import (
 	"bytes"
-	"compress/gzip"
 	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
+	"strings"
 	"time"
 
 	"github.com/m-mizutani/goerr"
 	"github.com/m-mizutani/hatchery/pkg/domain/config"
+	"github.com/m-mizutani/hatchery/pkg/domain/interfaces"
 	"github.com/m-mizutani/hatchery/pkg/domain/model"
 	"github.com/m-mizutani/hatchery/pkg/domain/types"
 	"github.com/m-mizutani/hatchery/pkg/infra"
+	"github.com/m-mizutani/hatchery/pkg/infra/dedup"
+	"github.com/m-mizutani/hatchery/pkg/infra/oauth"
+	"github.com/m-mizutani/hatchery/pkg/infra/output"
 	"github.com/m-mizutani/hatchery/pkg/utils"
 )
 
 func Exec(ctx context.Context, clients *infra.Clients, req config.Slack) error {
+	httpClient := oauth.NewClient(clients.HTTPClient(), oauth.StaticToken(req.GetAccessToken()))
 	var nextCursor string
 	now := utils.CtxNow(ctx)
 
+	seen, err := dedup.Load(ctx, clients.StateStorage(), req, req.GetDedup())
+	if err != nil {
+		return goerr.Wrap(err, "failed to load dedup partitions").With("req", req)
+	}
+
 	for seq := 0; req.GetMaxPages() == nil || seq < *req.GetMaxPages(); seq++ {
-		cursor, err := crawl(ctx, clients, req, now, seq, nextCursor)
+		cursor, err := crawl(ctx, clients, httpClient, req, seen, now, seq, nextCursor)
 		if err != nil {
-			return goerr.Wrap(err, "failed to crawl 1Password logs").With("seq", seq).With("cursor", nextCursor).With("req", req)
+			return goerr.Wrap(err, "failed to crawl Slack audit logs").With("seq", seq).With("cursor", nextCursor).With("req", req)
 		}
 		if cursor == nil {
 			break
@@ -34,28 +44,50 @@ func Exec(ctx context.Context, clients *infra.Clients, req config.Slack) error {
 		nextCursor = *cursor
 	}
 
+	if err := seen.Save(ctx); err != nil {
+		return goerr.Wrap(err, "failed to save dedup partition").With("req", req)
+	}
+
+	if prefix := req.GetReferencePrefix(); prefix != nil {
+		for _, kind := range referenceKinds {
+			if err := collectReference(ctx, clients, httpClient, req, *prefix, kind, now); err != nil {
+				return goerr.Wrap(err, "failed to collect Slack audit logs reference").With("kind", kind).With("req", req)
+			}
+		}
+	}
+
 	return nil
 }
 
 const (
 	// Slack API endpoint for Business Plan
 	baseURL = "https://api.slack.com/audit/v1/logs"
+
+	// Slack API endpoint of reference data to decode audit logs. kind (schemas or actions) is appended to the URL.
+	referenceBaseURL = "https://api.slack.com/audit/v1/"
 )
 
-func crawl(ctx context.Context, clients *infra.Clients, req config.Slack, end time.Time, seq int, cursor string) (*string, error) {
-	d := req.GetDuration().GoDuration()
+var referenceKinds = []string{"schemas", "actions"}
 
-	objName := model.DefaultLogObjectName(ctx, req, end, seq)
-	objWriter := clients.CloudStorage().NewObjectWriter(ctx,
-		types.CSBucket(req.GetBucket()),
-		objName,
-	)
-	w := gzip.NewWriter(objWriter)
+func crawl(ctx context.Context, clients *infra.Clients, httpClient interfaces.HTTPClient, req config.Slack, seen *dedup.Set, end time.Time, seq int, cursor string) (*string, error) {
+	d := req.GetDuration().GoDuration()
 
 	startTime := end.Add(-d)
 	qv := url.Values{}
 	qv.Add("limit", fmt.Sprintf("%d", req.GetLimit()))
 	qv.Add("oldest", fmt.Sprintf("%d", startTime.Unix()))
+	// Set latest to end of the window to avoid overlap with the next window
+	qv.Add("latest", fmt.Sprintf("%d", end.Unix()))
+
+	if v := req.GetAction(); v != nil && len(*v) > 0 {
+		qv.Add("action", strings.Join(*v, ","))
+	}
+	if v := req.GetActor(); v != nil {
+		qv.Add("actor", *v)
+	}
+	if v := req.GetEntity(); v != nil {
+		qv.Add("entity", *v)
+	}
 
 	if cursor != "" {
 		qv.Add("cursor", cursor)
@@ -74,9 +106,8 @@ func crawl(ctx context.Context, clients *infra.Clients, req config.Slack, end ti
 	}
 
 	httpReq.Header.Set("Content-Type", "application/json")
-	httpReq.Header.Set("Authorization", "Bearer "+req.GetAccessToken())
 
-	httpResp, err := clients.HTTPClient().Do(httpReq)
+	httpResp, err := httpClient.Do(httpReq)
 	if err != nil {
 		return nil, goerr.Wrap(err, "failed to send HTTP request")
 	}
@@ -96,18 +127,29 @@ func crawl(ctx context.Context, clients *infra.Clients, req config.Slack, end ti
 		return nil, goerr.Wrap(err, "failed to unmarshal response body")
 	}
 
+	body, dropped, err := seen.Filter(body, "entries", "id")
+	if err != nil {
+		return nil, err
+	}
+
+	objName := model.DefaultLogObjectName(ctx, req, end, seq)
+	w, err := output.NewLogObjectWriter(ctx, clients.CloudStorage(), req, objName, output.WithWindow(startTime, end))
+	if err != nil {
+		return nil, err
+	}
+
 	n, err := io.Copy(w, bytes.NewReader(body))
 	if err != nil {
+		w.Abort()
 		return nil, goerr.Wrap(err, "failed to write response to object writer").With("bytes", n)
 	}
 
 	if err := w.Close(); err != nil {
-		return nil, goerr.Wrap(err, "failed to close gzip writer").With("object", objName)
-	}
-	if err := objWriter.Close(); err != nil {
 		return nil, goerr.Wrap(err, "failed to close object writer").With("object", objName)
 	}
 
+	utils.CtxLogger(ctx).Info("harvested Slack audit logs", "bytes", n, "duplicates", dropped, "object", w.Object())
+
 	if resp.ResponseMetadata.NextCursor != "" {
 		return &resp.ResponseMetadata.NextCursor, nil
 	}
@@ -115,6 +157,47 @@ func crawl(ctx context.Context, clients *infra.Clients, req config.Slack, end ti
 	return nil, nil
 }
 
+func collectReference(ctx context.Context, clients *infra.Clients, httpClient interfaces.HTTPClient, req config.Slack, prefix, kind string, now time.Time) error {
+	reqID, _ := utils.CtxRequestID(ctx)
+	objName := types.CSObjectName(fmt.Sprintf("%s%s/%s%s-%s.json%s",
+		prefix, kind, now.Format("2006/01/02/15/"), now.Format("20060102T150405"), reqID, model.ObjectExt(req),
+	))
+
+	apiURL := referenceBaseURL + kind
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, apiURL, nil)
+	if err != nil {
+		return goerr.Wrap(err, "failed to create HTTP request")
+	}
+
+	httpResp, err := httpClient.Do(httpReq)
+	if err != nil {
+		return goerr.Wrap(err, "failed to send HTTP request")
+	}
+	defer utils.SafeClose(httpResp.Body)
+
+	if httpResp.StatusCode != http.StatusOK {
+		data, _ := io.ReadAll(httpResp.Body)
+		return goerr.New("unexpected status code").With("status", httpResp.Status).With("body", string(data))
+	}
+
+	w, err := output.NewLogObjectWriter(ctx, clients.CloudStorage(), req, objName)
+	if err != nil {
+		return err
+	}
+
+	n, err := io.Copy(w, httpResp.Body)
+	if err != nil {
+		w.Abort()
+		return goerr.Wrap(err, "failed to write response to object writer").With("bytes", n)
+	}
+	if err := w.Close(); err != nil {
+		return goerr.Wrap(err, "failed to close object writer").With("object", objName)
+	}
+
+	utils.CtxLogger(ctx).Info("harvested Slack audit logs reference", "kind", kind, "bytes", n, "object", w.Object())
+	return nil
+}
+
 type apiResponse struct {
 	ResponseMetadata struct {
 		NextCursor string `json:"next_cursor"`
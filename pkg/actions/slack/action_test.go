@@ -5,6 +5,9 @@ import (
 	"compress/gzip"
 	"context"
 	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
 	"testing"
 	"time"
 
@@ -55,6 +58,90 @@ func TestAction(t *testing.T) {
 	gt.V(t, resp.Entries[0].Action).NotEqual("")
 }
 
+type mockHTTPClient struct {
+	requests []*http.Request
+}
+
+func (x *mockHTTPClient) Do(req *http.Request) (*http.Response, error) {
+	x.requests = append(x.requests, req)
+
+	var body string
+	switch req.URL.Path {
+	case "/audit/v1/logs":
+		body = `{"entries":[{"id":"x","action":"user_login"}],"response_metadata":{"next_cursor":""}}`
+	case "/audit/v1/schemas":
+		body = `{"schemas":[]}`
+	case "/audit/v1/actions":
+		body = `{"actions":{}}`
+	default:
+		return &http.Response{StatusCode: http.StatusNotFound, Status: "404 Not Found", Body: io.NopCloser(strings.NewReader(""))}, nil
+	}
+
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Body:       io.NopCloser(strings.NewReader(body)),
+	}, nil
+}
+
+func TestFiltersAndReference(t *testing.T) {
+	mock := cs.NewMock()
+	httpClient := &mockHTTPClient{}
+	clients := infra.New(infra.WithCloudStorage(mock), infra.WithHTTPClient(httpClient))
+
+	ctx := context.Background()
+	_, ctx = utils.CtxRequestID(ctx)
+	now := time.Date(2024, 4, 1, 10, 0, 0, 0, time.UTC)
+	ctx = utils.CtxWithNow(ctx, func() time.Time { return now })
+
+	actor := "U0001"
+	entity := "T0001"
+	refPrefix := "slack-ref/"
+	req := &config.SlackImpl{
+		AccessToken: "test-token",
+		Bucket:      "test-bucket",
+		Duration: &pkl.Duration{
+			Value: 1,
+			Unit:  pkl.Hour,
+		},
+		Limit:           100,
+		Action:          &[]string{"user_login", "user_logout"},
+		Actor:           &actor,
+		Entity:          &entity,
+		ReferencePrefix: &refPrefix,
+	}
+
+	gt.NoError(t, slack.Exec(ctx, clients, req)).Must()
+
+	gt.A(t, httpClient.requests).Length(3).
+		At(0, func(t testing.TB, v *http.Request) {
+			q := v.URL.Query()
+			gt.Equal(t, q.Get("oldest"), "1711962000")
+			gt.Equal(t, q.Get("latest"), "1711965600")
+			gt.Equal(t, q.Get("action"), "user_login,user_logout")
+			gt.Equal(t, q.Get("actor"), "U0001")
+			gt.Equal(t, q.Get("entity"), "T0001")
+			gt.Equal(t, v.Header.Get("Authorization"), "Bearer test-token")
+		}).
+		At(1, func(t testing.TB, v *http.Request) {
+			gt.Equal(t, v.URL.Path, "/audit/v1/schemas")
+		}).
+		At(2, func(t testing.TB, v *http.Request) {
+			gt.Equal(t, v.URL.Path, "/audit/v1/actions")
+		})
+
+	gt.A(t, mock.Results).Length(3).
+		At(0, func(t testing.TB, v *cs.MockResult) {
+			gt.Equal(t, v.Object, model.DefaultLogObjectName(ctx, req, now, 0))
+		}).
+		At(1, func(t testing.TB, v *cs.MockResult) {
+			gt.S(t, string(v.Object)).HasPrefix("slack-ref/schemas/2024/04/01/10/20240401T100000-")
+			gt.Equal(t, v.Body.Closed, true)
+		}).
+		At(2, func(t testing.TB, v *cs.MockResult) {
+			gt.S(t, string(v.Object)).HasPrefix("slack-ref/actions/2024/04/01/10/")
+		})
+}
+
 type apiResponse struct {
 	Entries []struct {
 		Action string `json:"action"`
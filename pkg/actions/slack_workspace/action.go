@@ -0,0 +1,251 @@
+package slack_workspace
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/m-mizutani/goerr"
+	"github.com/m-mizutani/hatchery/pkg/domain/config"
+	"github.com/m-mizutani/hatchery/pkg/domain/interfaces"
+	"github.com/m-mizutani/hatchery/pkg/domain/model"
+	"github.com/m-mizutani/hatchery/pkg/domain/types"
+	"github.com/m-mizutani/hatchery/pkg/infra"
+	"github.com/m-mizutani/hatchery/pkg/infra/oauth"
+	"github.com/m-mizutani/hatchery/pkg/infra/output"
+	"github.com/m-mizutani/hatchery/pkg/utils"
+)
+
+const (
+	// Slack Web API endpoint. API method name is appended to the URL.
+	baseURL = "https://slack.com/api/"
+)
+
+// dataset describes how to crawl a Slack Web API method. token is page number for page based pagination and cursor for cursor based pagination. Empty token means the first page.
+type dataset struct {
+	method string
+	query  func(req config.SlackWorkspace, end time.Time, token string) url.Values
+	next   func(resp *apiResponse) string
+	// filter drops entries before start from body, and returns true as done if following pages are out of the window. Optional.
+	filter func(body []byte, start time.Time) ([]byte, bool, error)
+}
+
+var datasets = map[string]dataset{
+	"access_logs": {
+		method: "team.accessLogs",
+		query: func(req config.SlackWorkspace, end time.Time, token string) url.Values {
+			qv := pageQuery(req, token)
+			qv.Add("before", fmt.Sprintf("%d", end.Unix()))
+			return qv
+		},
+		next:   nextPage,
+		filter: filterAccessLogs,
+	},
+	"integration_logs": {
+		method: "team.integrationLogs",
+		query: func(req config.SlackWorkspace, end time.Time, token string) url.Values {
+			return pageQuery(req, token)
+		},
+		next: nextPage,
+	},
+	"users": {
+		method: "users.list",
+		query: func(req config.SlackWorkspace, end time.Time, token string) url.Values {
+			return cursorQuery(req, token)
+		},
+		next: nextCursor,
+	},
+	"conversations": {
+		method: "conversations.list",
+		query: func(req config.SlackWorkspace, end time.Time, token string) url.Values {
+			qv := cursorQuery(req, token)
+			qv.Add("types", "public_channel,private_channel,mpim,im")
+			return qv
+		},
+		next: nextCursor,
+	},
+}
+
+func pageQuery(req config.SlackWorkspace, token string) url.Values {
+	qv := url.Values{}
+	qv.Add("count", fmt.Sprintf("%d", req.GetLimit()))
+	if token != "" {
+		qv.Add("page", token)
+	}
+	return qv
+}
+
+func nextPage(resp *apiResponse) string {
+	if resp.Paging.Page < resp.Paging.Pages {
+		return strconv.Itoa(resp.Paging.Page + 1)
+	}
+	return ""
+}
+
+// filterAccessLogs drops logins whose date_last is before start. Logins are returned newest first, then a page that has an older login is the last page in the window. Logins without date_last are kept.
+func filterAccessLogs(body []byte, start time.Time) ([]byte, bool, error) {
+	var obj map[string]json.RawMessage
+	if err := json.Unmarshal(body, &obj); err != nil {
+		return nil, false, goerr.Wrap(err, "failed to unmarshal access logs")
+	}
+	var logins []json.RawMessage
+	if raw, ok := obj["logins"]; ok {
+		if err := json.Unmarshal(raw, &logins); err != nil {
+			return nil, false, goerr.Wrap(err, "failed to unmarshal logins")
+		}
+	}
+
+	kept := make([]json.RawMessage, 0, len(logins))
+	for _, login := range logins {
+		var v struct {
+			DateLast *int64 `json:"date_last"`
+		}
+		if err := json.Unmarshal(login, &v); err != nil {
+			return nil, false, goerr.Wrap(err, "failed to unmarshal login")
+		}
+		if v.DateLast != nil && time.Unix(*v.DateLast, 0).Before(start) {
+			continue
+		}
+		kept = append(kept, login)
+	}
+
+	if len(kept) == len(logins) {
+		return body, false, nil
+	}
+
+	raw, err := json.Marshal(kept)
+	if err != nil {
+		return nil, false, goerr.Wrap(err, "failed to marshal logins")
+	}
+	obj["logins"] = raw
+	filtered, err := json.Marshal(obj)
+	if err != nil {
+		return nil, false, goerr.Wrap(err, "failed to marshal access logs")
+	}
+	return filtered, true, nil
+}
+
+func cursorQuery(req config.SlackWorkspace, token string) url.Values {
+	qv := url.Values{}
+	qv.Add("limit", fmt.Sprintf("%d", req.GetLimit()))
+	if token != "" {
+		qv.Add("cursor", token)
+	}
+	return qv
+}
+
+func nextCursor(resp *apiResponse) string {
+	return resp.ResponseMetadata.NextCursor
+}
+
+func Exec(ctx context.Context, clients *infra.Clients, req config.SlackWorkspace) error {
+	httpClient := oauth.NewClient(clients.HTTPClient(), oauth.StaticToken(req.GetAccessToken()))
+	now := utils.CtxNow(ctx)
+	start := now.Add(-req.GetDuration().GoDuration())
+
+	for _, name := range req.GetDatasets() {
+		ds, ok := datasets[name]
+		if !ok {
+			return goerr.Wrap(types.ErrInvalidOption, "unknown dataset of SlackWorkspace").With("dataset", name)
+		}
+
+		var token string
+		for seq := 0; req.GetMaxPages() == nil || seq < *req.GetMaxPages(); seq++ {
+			next, err := crawl(ctx, clients, httpClient, req, name, ds, start, now, seq, token)
+			if err != nil {
+				return goerr.Wrap(err, "failed to crawl Slack workspace data").With("dataset", name).With("seq", seq).With("token", token).With("req", req)
+			}
+			if next == "" {
+				break
+			}
+			token = next
+		}
+	}
+
+	return nil
+}
+
+func crawl(ctx context.Context, clients *infra.Clients, httpClient interfaces.HTTPClient, req config.SlackWorkspace, name string, ds dataset, start, end time.Time, seq int, token string) (string, error) {
+	endpoint, err := url.Parse(baseURL + ds.method)
+	if err != nil {
+		return "", goerr.Wrap(err, "failed to parse URL").With("method", ds.method)
+	}
+	endpoint.RawQuery = ds.query(req, end, token).Encode()
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint.String(), nil)
+	if err != nil {
+		return "", goerr.Wrap(err, "failed to create HTTP request")
+	}
+
+	httpResp, err := httpClient.Do(httpReq)
+	if err != nil {
+		return "", goerr.Wrap(err, "failed to send HTTP request")
+	}
+	defer utils.SafeClose(httpResp.Body)
+
+	if httpResp.StatusCode != http.StatusOK {
+		data, _ := io.ReadAll(httpResp.Body)
+		return "", goerr.New("unexpected status code").With("status", httpResp.Status).With("body", string(data))
+	}
+
+	body, err := io.ReadAll(httpResp.Body)
+	if err != nil {
+		return "", goerr.Wrap(err, "failed to read response body")
+	}
+
+	var resp apiResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return "", goerr.Wrap(err, "failed to unmarshal response body")
+	}
+	// Slack Web API returns 200 OK even if the request is failed
+	if !resp.Ok {
+		return "", goerr.New("Slack API returned error").With("error", resp.Error).With("method", ds.method)
+	}
+
+	var done bool
+	if ds.filter != nil {
+		if body, done, err = ds.filter(body, start); err != nil {
+			return "", err
+		}
+	}
+
+	objName := model.DatasetLogObjectName(ctx, req, name, end, seq)
+	w, err := output.NewLogObjectWriter(ctx, clients.CloudStorage(), req, objName)
+	if err != nil {
+		return "", err
+	}
+
+	n, err := io.Copy(w, bytes.NewReader(body))
+	if err != nil {
+		w.Abort()
+		return "", goerr.Wrap(err, "failed to write response to object writer").With("bytes", n)
+	}
+	if err := w.Close(); err != nil {
+		return "", goerr.Wrap(err, "failed to close object writer").With("object", objName)
+	}
+
+	utils.CtxLogger(ctx).Info("harvested Slack workspace data", "dataset", name, "bytes", n, "object", w.Object())
+
+	if done {
+		return "", nil
+	}
+	return ds.next(&resp), nil
+}
+
+type apiResponse struct {
+	Ok     bool   `json:"ok"`
+	Error  string `json:"error"`
+	Paging struct {
+		Page  int `json:"page"`
+		Pages int `json:"pages"`
+	} `json:"paging"`
+	ResponseMetadata struct {
+		NextCursor string `json:"next_cursor"`
+	} `json:"response_metadata"`
+}
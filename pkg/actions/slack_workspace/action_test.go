@@ -0,0 +1,159 @@
+package slack_workspace_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/apple/pkl-go/pkl"
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/hatchery/pkg/actions/slack_workspace"
+	"github.com/m-mizutani/hatchery/pkg/domain/config"
+	"github.com/m-mizutani/hatchery/pkg/domain/model"
+	"github.com/m-mizutani/hatchery/pkg/infra"
+	"github.com/m-mizutani/hatchery/pkg/infra/cs"
+	"github.com/m-mizutani/hatchery/pkg/utils"
+)
+
+type mockHTTPClient struct {
+	requests []*http.Request
+	// accessLogs overrides pages of team.accessLogs if set
+	accessLogs []string
+}
+
+func (x *mockHTTPClient) Do(req *http.Request) (*http.Response, error) {
+	x.requests = append(x.requests, req)
+	q := req.URL.Query()
+
+	var body string
+	switch req.URL.Path {
+	case "/api/team.accessLogs":
+		if x.accessLogs != nil {
+			page, _ := strconv.Atoi(q.Get("page"))
+			body = x.accessLogs[max(page-1, 0)]
+		} else if q.Get("page") == "" {
+			body = `{"ok":true,"logins":[{"user_id":"U1"}],"paging":{"count":1,"total":2,"page":1,"pages":2}}`
+		} else {
+			body = `{"ok":true,"logins":[{"user_id":"U2"}],"paging":{"count":1,"total":2,"page":2,"pages":2}}`
+		}
+	case "/api/users.list":
+		if q.Get("cursor") == "" {
+			body = `{"ok":true,"members":[{"id":"U1"}],"response_metadata":{"next_cursor":"next-1"}}`
+		} else {
+			body = `{"ok":true,"members":[{"id":"U2"}],"response_metadata":{"next_cursor":""}}`
+		}
+	default:
+		body = `{"ok":false,"error":"unknown_method"}`
+	}
+
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Body:       io.NopCloser(strings.NewReader(body)),
+	}, nil
+}
+
+func TestSlackWorkspace(t *testing.T) {
+	mock := cs.NewMock()
+	httpClient := &mockHTTPClient{}
+	clients := infra.New(infra.WithCloudStorage(mock), infra.WithHTTPClient(httpClient))
+
+	ctx := context.Background()
+	_, ctx = utils.CtxRequestID(ctx)
+	now := time.Date(2024, 4, 1, 10, 0, 0, 0, time.UTC)
+	ctx = utils.CtxWithNow(ctx, func() time.Time { return now })
+
+	prefix := "slack/"
+	req := &config.SlackWorkspaceImpl{
+		AccessToken: "test-token",
+		Bucket:      "test-bucket",
+		Prefix:      &prefix,
+		Datasets:    []string{"access_logs", "users"},
+		Duration:    &pkl.Duration{Value: 1, Unit: pkl.Hour},
+		Limit:       1,
+	}
+	gt.NoError(t, slack_workspace.Exec(ctx, clients, req)).Must()
+
+	gt.A(t, httpClient.requests).Length(4).
+		At(0, func(t testing.TB, v *http.Request) {
+			gt.Equal(t, v.URL.Query().Get("before"), "1711965600")
+			gt.Equal(t, v.URL.Query().Get("count"), "1")
+			gt.Equal(t, v.Header.Get("Authorization"), "Bearer test-token")
+		}).
+		At(1, func(t testing.TB, v *http.Request) {
+			gt.Equal(t, v.URL.Query().Get("page"), "2")
+		}).
+		At(3, func(t testing.TB, v *http.Request) {
+			gt.Equal(t, v.URL.Query().Get("cursor"), "next-1")
+		})
+
+	gt.A(t, mock.Results).Length(4).
+		At(0, func(t testing.TB, v *cs.MockResult) {
+			gt.Equal(t, v.Object, model.DatasetLogObjectName(ctx, req, "access_logs", now, 0))
+			gt.S(t, string(v.Object)).HasPrefix("slack/access_logs/logs/2024/04/01/10/")
+		}).
+		At(3, func(t testing.TB, v *cs.MockResult) {
+			gt.Equal(t, v.Object, model.DatasetLogObjectName(ctx, req, "users", now, 1))
+
+			var resp struct {
+				Members []struct {
+					ID string `json:"id"`
+				} `json:"members"`
+			}
+			r := gt.R1(gzip.NewReader(bytes.NewReader(v.Body.Bytes()))).NoError(t)
+			gt.NoError(t, json.NewDecoder(r).Decode(&resp))
+			gt.A(t, resp.Members).Length(1).At(0, func(t testing.TB, v struct {
+				ID string `json:"id"`
+			}) {
+				gt.Equal(t, v.ID, "U2")
+			})
+		})
+}
+
+func TestSlackWorkspaceAccessLogsWindow(t *testing.T) {
+	mock := cs.NewMock()
+	httpClient := &mockHTTPClient{accessLogs: []string{
+		// 1711962000 is 2024-04-01T09:00:00Z
+		`{"ok":true,"logins":[{"user_id":"U1","date_last":1711963000},{"user_id":"U2","date_last":1711961000}],"paging":{"page":1,"pages":3}}`,
+		`{"ok":true,"logins":[{"user_id":"U3","date_last":1711960000}],"paging":{"page":2,"pages":3}}`,
+	}}
+	clients := infra.New(infra.WithCloudStorage(mock), infra.WithHTTPClient(httpClient))
+
+	now := time.Date(2024, 4, 1, 10, 0, 0, 0, time.UTC)
+	ctx := utils.CtxWithNow(context.Background(), func() time.Time { return now })
+	req := &config.SlackWorkspaceImpl{
+		AccessToken: "test-token",
+		Bucket:      "test-bucket",
+		Datasets:    []string{"access_logs"},
+		Duration:    &pkl.Duration{Value: 1, Unit: pkl.Hour},
+		Limit:       2,
+	}
+	gt.NoError(t, slack_workspace.Exec(ctx, clients, req)).Must()
+
+	// Pages after the one that has an older login are not requested
+	gt.A(t, httpClient.requests).Length(1)
+	gt.A(t, mock.Results).Length(1).At(0, func(t testing.TB, v *cs.MockResult) {
+		r := gt.R1(gzip.NewReader(bytes.NewReader(v.Body.Bytes()))).NoError(t)
+		body := string(gt.R1(io.ReadAll(r)).NoError(t))
+		gt.S(t, body).Contains(`"U1"`)
+		gt.S(t, body).NotContains(`"U2"`)
+	})
+}
+
+func TestSlackWorkspaceAPIError(t *testing.T) {
+	clients := infra.New(infra.WithCloudStorage(cs.NewMock()), infra.WithHTTPClient(&mockHTTPClient{}))
+	req := &config.SlackWorkspaceImpl{
+		AccessToken: "test-token",
+		Bucket:      "test-bucket",
+		Datasets:    []string{"integration_logs"},
+		Duration:    &pkl.Duration{Value: 1, Unit: pkl.Hour},
+		Limit:       10,
+	}
+	gt.Error(t, slack_workspace.Exec(context.Background(), clients, req))
+}
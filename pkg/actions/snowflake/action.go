@@ -0,0 +1,267 @@
+package snowflake
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/m-mizutani/goerr"
+	"github.com/m-mizutani/hatchery/pkg/domain/config"
+	"github.com/m-mizutani/hatchery/pkg/domain/interfaces"
+	"github.com/m-mizutani/hatchery/pkg/domain/model"
+	"github.com/m-mizutani/hatchery/pkg/domain/types"
+	"github.com/m-mizutani/hatchery/pkg/infra"
+	"github.com/m-mizutani/hatchery/pkg/infra/oauth"
+	"github.com/m-mizutani/hatchery/pkg/infra/output"
+	"github.com/m-mizutani/hatchery/pkg/utils"
+)
+
+const (
+	// Snowflake accepts JWT that expires within 1 hour
+	jwtLifetime = 59 * time.Minute
+
+	// Statement timeout in seconds
+	statementTimeout = 600
+
+	// Interval to check status of statement that is executed asynchronously
+	pollInterval = 500 * time.Millisecond
+)
+
+type dataset struct {
+	view string
+	// column of timestamp to bound the window
+	column string
+	// latency of the view. Rows within it from now may not be available yet
+	lag time.Duration
+}
+
+// See https://docs.snowflake.com/en/sql-reference/account-usage
+var datasets = map[string]dataset{
+	"login_history":  {view: "LOGIN_HISTORY", column: "EVENT_TIMESTAMP", lag: 2 * time.Hour},
+	"query_history":  {view: "QUERY_HISTORY", column: "START_TIME", lag: 45 * time.Minute},
+	"access_history": {view: "ACCESS_HISTORY", column: "QUERY_START_TIME", lag: 3 * time.Hour},
+}
+
+func Exec(ctx context.Context, clients *infra.Clients, req *config.SnowflakeAccountUsageImpl) error {
+	token, err := keyPairJWT(ctx, req)
+	if err != nil {
+		return goerr.Wrap(err, "failed to build JWT for Snowflake").With("req", req)
+	}
+	httpClient := oauth.NewClient(clients.HTTPClient(), oauth.StaticToken(token))
+
+	now := utils.CtxNow(ctx)
+	for _, name := range req.GetDatasets() {
+		ds, ok := datasets[name]
+		if !ok {
+			return goerr.Wrap(types.ErrInvalidOption, "unsupported Snowflake dataset").With("dataset", name)
+		}
+
+		if err := crawl(ctx, clients, httpClient, req, name, ds, now); err != nil {
+			return goerr.Wrap(err, "failed to crawl Snowflake account usage").With("dataset", name).With("req", req)
+		}
+	}
+
+	return nil
+}
+
+func baseURL(req *config.SnowflakeAccountUsageImpl) string {
+	return "https://" + strings.ToLower(req.GetAccount()) + ".snowflakecomputing.com/api/v2/statements"
+}
+
+// keyPairJWT builds JWT for key-pair authentication of SQL API. Account identifier in JWT must be uppercase and without region part.
+// See https://docs.snowflake.com/en/developer-guide/sql-api/authenticating#using-key-pair-authentication
+func keyPairJWT(ctx context.Context, req *config.SnowflakeAccountUsageImpl) (string, error) {
+	key, err := oauth.ParseRSAPrivateKey([]byte(req.GetPrivateKey()))
+	if err != nil {
+		return "", err
+	}
+
+	pubDER, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		return "", goerr.Wrap(err, "failed to marshal public key")
+	}
+	fp := sha256.Sum256(pubDER)
+
+	account := strings.ToUpper(strings.SplitN(req.GetAccount(), ".", 2)[0])
+	qualifiedUser := account + "." + strings.ToUpper(req.GetUser())
+
+	now := utils.CtxNow(ctx)
+	return oauth.SignJWT(key, nil, map[string]any{
+		"iss": qualifiedUser + ".SHA256:" + base64.StdEncoding.EncodeToString(fp[:]),
+		"sub": qualifiedUser,
+		"iat": now.Unix(),
+		"exp": now.Add(jwtLifetime).Unix(),
+	})
+}
+
+type binding struct {
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+type statementRequest struct {
+	Statement string             `json:"statement"`
+	Timeout   int                `json:"timeout"`
+	Warehouse string             `json:"warehouse,omitempty"`
+	Role      string             `json:"role,omitempty"`
+	Bindings  map[string]binding `json:"bindings"`
+}
+
+type statementResponse struct {
+	StatementHandle   string `json:"statementHandle"`
+	Message           string `json:"message"`
+	ResultSetMetaData struct {
+		NumRows       int `json:"numRows"`
+		PartitionInfo []struct {
+			RowCount int `json:"rowCount"`
+		} `json:"partitionInfo"`
+		RowType []struct {
+			Name string `json:"name"`
+		} `json:"rowType"`
+	} `json:"resultSetMetaData"`
+	Data [][]any `json:"data"`
+}
+
+// crawlWindow returns the window of the dataset, [now - lag - duration, now - lag), not to miss rows that appear in the view after its latency.
+func crawlWindow(req *config.SnowflakeAccountUsageImpl, ds dataset, now time.Time) (time.Time, time.Time) {
+	lag := ds.lag
+	if v := req.GetLag(); v != nil {
+		lag = v.GoDuration()
+	}
+	end := now.Add(-lag)
+	return end.Add(-req.GetDuration().GoDuration()), end
+}
+
+func crawl(ctx context.Context, clients *infra.Clients, httpClient interfaces.HTTPClient, req *config.SnowflakeAccountUsageImpl, name string, ds dataset, now time.Time) error {
+	start, end := crawlWindow(req, ds, now)
+
+	// View and column are fixed by dataset, and the window is passed by bindings
+	stmt := statementRequest{
+		Statement: fmt.Sprintf("SELECT * FROM SNOWFLAKE.ACCOUNT_USAGE.%s WHERE %s >= TO_TIMESTAMP_LTZ(?) AND %s < TO_TIMESTAMP_LTZ(?) ORDER BY %s",
+			ds.view, ds.column, ds.column, ds.column),
+		Timeout: statementTimeout,
+		Bindings: map[string]binding{
+			"1": {Type: "TEXT", Value: start.UTC().Format(time.RFC3339)},
+			"2": {Type: "TEXT", Value: end.UTC().Format(time.RFC3339)},
+		},
+	}
+	if req.Warehouse != nil {
+		stmt.Warehouse = *req.Warehouse
+	}
+	if req.Role != nil {
+		stmt.Role = *req.Role
+	}
+
+	body, err := json.Marshal(stmt)
+	if err != nil {
+		return goerr.Wrap(err, "failed to marshal statement request")
+	}
+
+	resp, err := call(ctx, httpClient, http.MethodPost, baseURL(req), body)
+	if err != nil {
+		return err
+	}
+	for resp.StatementHandle != "" && resp.ResultSetMetaData.RowType == nil {
+		select {
+		case <-ctx.Done():
+			return goerr.Wrap(ctx.Err(), "canceled while waiting statement").With("handle", resp.StatementHandle)
+		case <-time.After(pollInterval):
+		}
+
+		resp, err = call(ctx, httpClient, http.MethodGet, baseURL(req)+"/"+url.PathEscape(resp.StatementHandle), nil)
+		if err != nil {
+			return err
+		}
+	}
+
+	columns := make([]string, len(resp.ResultSetMetaData.RowType))
+	for i, col := range resp.ResultSetMetaData.RowType {
+		columns[i] = col.Name
+	}
+
+	objName := model.DatasetLogObjectName(ctx, req, name, now, 0)
+	w, err := output.NewLogObjectWriter(ctx, clients.CloudStorage(), req, objName, output.WithWindow(start, end), types.WithContentType(output.ContentTypeNDJSON))
+	if err != nil {
+		return err
+	}
+	encoder := json.NewEncoder(w)
+
+	rows := 0
+	for partition := 0; ; partition++ {
+		for _, row := range resp.Data {
+			record := make(map[string]any, len(columns))
+			for i, v := range row {
+				if i < len(columns) {
+					record[columns[i]] = v
+				}
+			}
+			if err := encoder.Encode(record); err != nil {
+				w.Abort()
+				return goerr.Wrap(err, "failed to write record to object writer").With("object", objName)
+			}
+			rows++
+		}
+
+		// Result set is split into partitions, and the first one is included in the response of the statement
+		if partition+1 >= len(resp.ResultSetMetaData.PartitionInfo) {
+			break
+		}
+		handle, meta := resp.StatementHandle, resp.ResultSetMetaData
+		next, err := call(ctx, httpClient, http.MethodGet, fmt.Sprintf("%s/%s?partition=%d", baseURL(req), url.PathEscape(handle), partition+1), nil)
+		if err != nil {
+			w.Abort()
+			return err
+		}
+		resp = next
+		resp.StatementHandle, resp.ResultSetMetaData = handle, meta
+	}
+
+	if err := w.Close(); err != nil {
+		return goerr.Wrap(err, "failed to close object writer").With("object", objName)
+	}
+
+	utils.CtxLogger(ctx).Info("harvested Snowflake account usage", "dataset", name, "rows", rows, "object", w.Object())
+
+	return nil
+}
+
+func call(ctx context.Context, httpClient interfaces.HTTPClient, method, apiURL string, body []byte) (*statementResponse, error) {
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, method, apiURL, reader)
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to create HTTP request")
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("Accept", "application/json")
+	httpReq.Header.Set("X-Snowflake-Authorization-Token-Type", "KEYPAIR_JWT")
+
+	httpResp, err := httpClient.Do(httpReq)
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to send HTTP request")
+	}
+	defer utils.SafeClose(httpResp.Body)
+
+	// 202 means the statement is still running
+	if httpResp.StatusCode != http.StatusOK && httpResp.StatusCode != http.StatusAccepted {
+		data, _ := io.ReadAll(httpResp.Body)
+		return nil, goerr.New("unexpected status code").With("status", httpResp.Status).With("body", string(data)).With("url", apiURL)
+	}
+
+	var resp statementResponse
+	if err := json.NewDecoder(httpResp.Body).Decode(&resp); err != nil {
+		return nil, goerr.Wrap(err, "failed to unmarshal response body")
+	}
+	return &resp, nil
+}
@@ -0,0 +1,166 @@
+package snowflake_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/apple/pkl-go/pkl"
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/hatchery/pkg/actions/snowflake"
+	"github.com/m-mizutani/hatchery/pkg/domain/config"
+	"github.com/m-mizutani/hatchery/pkg/domain/model"
+	"github.com/m-mizutani/hatchery/pkg/infra"
+	"github.com/m-mizutani/hatchery/pkg/infra/cs"
+	"github.com/m-mizutani/hatchery/pkg/utils"
+)
+
+func TestSnowflakeAccountUsage(t *testing.T) {
+	key := gt.R1(rsa.GenerateKey(rand.Reader, 2048)).NoError(t)
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+	fp := sha256.Sum256(gt.R1(x509.MarshalPKIXPublicKey(&key.PublicKey)).NoError(t))
+
+	checkAuth := func(t *testing.T, r *http.Request) {
+		gt.Equal(t, r.Header.Get("X-Snowflake-Authorization-Token-Type"), "KEYPAIR_JWT")
+		token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		parts := strings.Split(token, ".")
+		gt.A(t, parts).Length(3)
+
+		var claims map[string]any
+		gt.NoError(t, json.Unmarshal(gt.R1(base64.RawURLEncoding.DecodeString(parts[1])).NoError(t), &claims))
+		gt.Equal(t, claims["iss"], any("MYORG-MYACCOUNT.HATCHERY.SHA256:"+base64.StdEncoding.EncodeToString(fp[:])))
+		gt.Equal(t, claims["sub"], any("MYORG-MYACCOUNT.HATCHERY"))
+	}
+
+	var statements []map[string]any
+	var polled, partitions int
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST myorg-myaccount.snowflakecomputing.com/api/v2/statements", func(w http.ResponseWriter, r *http.Request) {
+		checkAuth(t, r)
+		var stmt map[string]any
+		gt.NoError(t, json.NewDecoder(r.Body).Decode(&stmt))
+		statements = append(statements, stmt)
+
+		if strings.Contains(stmt["statement"].(string), "LOGIN_HISTORY") {
+			// Executed asynchronously
+			w.WriteHeader(http.StatusAccepted)
+			_, _ = w.Write([]byte(`{"statementHandle":"h-login","message":"Asynchronous execution in progress."}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"statementHandle":"h-query","resultSetMetaData":{"numRows":2,"partitionInfo":[{"rowCount":1},{"rowCount":1}],"rowType":[{"name":"QUERY_ID"},{"name":"USER_NAME"}]},"data":[["q1","alice"]]}`))
+	})
+	mux.HandleFunc("GET myorg-myaccount.snowflakecomputing.com/api/v2/statements/h-login", func(w http.ResponseWriter, r *http.Request) {
+		checkAuth(t, r)
+		polled++
+		_, _ = w.Write([]byte(`{"statementHandle":"h-login","resultSetMetaData":{"numRows":1,"partitionInfo":[{"rowCount":1}],"rowType":[{"name":"EVENT_ID"},{"name":"IS_SUCCESS"}]},"data":[["1","YES"]]}`))
+	})
+	mux.HandleFunc("GET myorg-myaccount.snowflakecomputing.com/api/v2/statements/h-query", func(w http.ResponseWriter, r *http.Request) {
+		checkAuth(t, r)
+		gt.Equal(t, r.URL.Query().Get("partition"), "1")
+		partitions++
+		_, _ = w.Write([]byte(`{"data":[["q2",null]]}`))
+	})
+
+	mock := cs.NewMock()
+	clients := infra.New(infra.WithCloudStorage(mock), infra.WithHTTPClient(utils.NewFakeHTTPClient(mux)))
+
+	ctx := context.Background()
+	_, ctx = utils.CtxRequestID(ctx)
+	now := time.Date(2024, 4, 1, 10, 0, 0, 0, time.UTC)
+	ctx = utils.CtxWithNow(ctx, func() time.Time { return now })
+
+	warehouse := "AUDIT_WH"
+	req := &config.SnowflakeAccountUsageImpl{
+		Account:    "myorg-myaccount",
+		User:       "hatchery",
+		PrivateKey: string(keyPEM),
+		Warehouse:  &warehouse,
+		Datasets:   []string{"login_history", "query_history"},
+		Duration:   &pkl.Duration{Value: 1, Unit: pkl.Hour},
+		Bucket:     "test-bucket",
+	}
+	gt.NoError(t, snowflake.Exec(ctx, clients, req)).Must()
+
+	gt.Equal(t, polled, 1)
+	gt.Equal(t, partitions, 1)
+	gt.A(t, statements).Length(2).At(0, func(t testing.TB, v map[string]any) {
+		gt.Equal(t, v["statement"], any("SELECT * FROM SNOWFLAKE.ACCOUNT_USAGE.LOGIN_HISTORY WHERE EVENT_TIMESTAMP >= TO_TIMESTAMP_LTZ(?) AND EVENT_TIMESTAMP < TO_TIMESTAMP_LTZ(?) ORDER BY EVENT_TIMESTAMP"))
+		gt.Equal(t, v["warehouse"], any("AUDIT_WH"))
+		gt.Equal(t, v["bindings"], any(map[string]any{
+			"1": map[string]any{"type": "TEXT", "value": "2024-04-01T07:00:00Z"},
+			"2": map[string]any{"type": "TEXT", "value": "2024-04-01T08:00:00Z"},
+		}))
+	}).At(1, func(t testing.TB, v map[string]any) {
+		// Windows are shifted back by latency of each view
+		gt.Equal(t, v["bindings"], any(map[string]any{
+			"1": map[string]any{"type": "TEXT", "value": "2024-04-01T08:15:00Z"},
+			"2": map[string]any{"type": "TEXT", "value": "2024-04-01T09:15:00Z"},
+		}))
+	})
+
+	readAll := func(t testing.TB, v *cs.MockResult) string {
+		r := gt.R1(gzip.NewReader(bytes.NewReader(v.Body.Bytes()))).NoError(t)
+		return string(gt.R1(io.ReadAll(r)).NoError(t))
+	}
+	gt.A(t, mock.Results).Length(2).
+		At(0, func(t testing.TB, v *cs.MockResult) {
+			gt.Equal(t, v.Object, model.DatasetLogObjectName(ctx, req, "login_history", now, 0))
+			gt.Equal(t, readAll(t, v), `{"EVENT_ID":"1","IS_SUCCESS":"YES"}`+"\n")
+		}).
+		At(1, func(t testing.TB, v *cs.MockResult) {
+			gt.Equal(t, v.Object, model.DatasetLogObjectName(ctx, req, "query_history", now, 0))
+			gt.Equal(t, readAll(t, v), `{"QUERY_ID":"q1","USER_NAME":"alice"}`+"\n"+`{"QUERY_ID":"q2","USER_NAME":null}`+"\n")
+		})
+}
+
+func TestSnowflakeAccountUsageLag(t *testing.T) {
+	key := gt.R1(rsa.GenerateKey(rand.Reader, 2048)).NoError(t)
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+
+	var bindings []any
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST myorg-myaccount.snowflakecomputing.com/api/v2/statements", func(w http.ResponseWriter, r *http.Request) {
+		var stmt map[string]any
+		gt.NoError(t, json.NewDecoder(r.Body).Decode(&stmt))
+		bindings = append(bindings, stmt["bindings"])
+		_, _ = w.Write([]byte(`{"statementHandle":"h","resultSetMetaData":{"numRows":0,"rowType":[{"name":"EVENT_ID"}]},"data":[]}`))
+	})
+
+	mock := cs.NewMock()
+	clients := infra.New(infra.WithCloudStorage(mock), infra.WithHTTPClient(utils.NewFakeHTTPClient(mux)))
+
+	now := time.Date(2024, 4, 1, 10, 0, 0, 0, time.UTC)
+	ctx := utils.CtxWithNow(context.Background(), func() time.Time { return now })
+
+	req := &config.SnowflakeAccountUsageImpl{
+		Account:    "myorg-myaccount",
+		User:       "hatchery",
+		PrivateKey: string(keyPEM),
+		Datasets:   []string{"login_history", "access_history"},
+		Duration:   &pkl.Duration{Value: 1, Unit: pkl.Hour},
+		Lag:        &pkl.Duration{Value: 30, Unit: pkl.Minute},
+		Bucket:     "test-bucket",
+	}
+	gt.NoError(t, snowflake.Exec(ctx, clients, req)).Must()
+
+	// Configured lag overrides latency of all views
+	window := any(map[string]any{
+		"1": map[string]any{"type": "TEXT", "value": "2024-04-01T08:30:00Z"},
+		"2": map[string]any{"type": "TEXT", "value": "2024-04-01T09:30:00Z"},
+	})
+	gt.A(t, bindings).Length(2).
+		At(0, func(t testing.TB, v any) { gt.Equal(t, v, window) }).
+		At(1, func(t testing.TB, v any) { gt.Equal(t, v, window) })
+}
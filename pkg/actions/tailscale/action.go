@@ -0,0 +1,114 @@
+package tailscale
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/m-mizutani/goerr"
+	"github.com/m-mizutani/hatchery/pkg/domain/config"
+	"github.com/m-mizutani/hatchery/pkg/domain/interfaces"
+	"github.com/m-mizutani/hatchery/pkg/domain/model"
+	"github.com/m-mizutani/hatchery/pkg/domain/types"
+	"github.com/m-mizutani/hatchery/pkg/infra"
+	"github.com/m-mizutani/hatchery/pkg/infra/oauth"
+	"github.com/m-mizutani/hatchery/pkg/infra/output"
+	"github.com/m-mizutani/hatchery/pkg/utils"
+)
+
+const (
+	baseURL = "https://api.tailscale.com/api/v2"
+	// See https://tailscale.com/kb/1215/oauth-clients
+	tokenURL = baseURL + "/oauth/token"
+)
+
+// See https://tailscale.com/api#tag/logging
+var datasets = map[string]string{
+	"configuration": "/logging/configuration",
+	"network":       "/logging/network",
+}
+
+func Exec(ctx context.Context, clients *infra.Clients, req *config.TailscaleImpl) error {
+	ts, err := tokenSource(clients.HTTPClient(), req)
+	if err != nil {
+		return err
+	}
+	httpClient := oauth.NewClient(clients.HTTPClient(), ts)
+
+	now := utils.CtxNow(ctx)
+	for _, name := range req.GetDatasets() {
+		path, ok := datasets[name]
+		if !ok {
+			return goerr.Wrap(types.ErrInvalidOption, "unsupported Tailscale dataset").With("dataset", name)
+		}
+
+		if err := crawl(ctx, clients, httpClient, req, name, path, now); err != nil {
+			return goerr.Wrap(err, "failed to crawl Tailscale logs").With("dataset", name).With("req", req)
+		}
+	}
+
+	return nil
+}
+
+func tokenSource(client interfaces.HTTPClient, req *config.TailscaleImpl) (oauth.TokenSource, error) {
+	if req.ApiKey != nil {
+		return oauth.StaticToken(*req.ApiKey), nil
+	}
+
+	if req.OauthClientId == nil || req.OauthClientSecret == nil {
+		return nil, goerr.Wrap(types.ErrInvalidOption, "either api_key or oauth_client_id and oauth_client_secret is required").With("id", req.GetId())
+	}
+	return oauth.NewTokenSource(client, oauth.Config{
+		TokenURL:     tokenURL,
+		ClientID:     *req.OauthClientId,
+		ClientSecret: *req.OauthClientSecret,
+	}), nil
+}
+
+// crawl streams logs of the window into one object because logging API of Tailscale has no pagination and network flow logs can be large.
+func crawl(ctx context.Context, clients *infra.Clients, httpClient interfaces.HTTPClient, req *config.TailscaleImpl, name, path string, end time.Time) error {
+	start := end.Add(-req.GetDuration().GoDuration())
+
+	qv := url.Values{}
+	qv.Add("start", start.UTC().Format(time.RFC3339))
+	qv.Add("end", end.UTC().Format(time.RFC3339))
+
+	apiURL := baseURL + "/tailnet/" + url.PathEscape(req.GetTailnet()) + path + "?" + qv.Encode()
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, apiURL, nil)
+	if err != nil {
+		return goerr.Wrap(err, "failed to create HTTP request")
+	}
+	httpReq.Header.Set("Accept", "application/json")
+
+	httpResp, err := httpClient.Do(httpReq)
+	if err != nil {
+		return goerr.Wrap(err, "failed to send HTTP request")
+	}
+	defer utils.SafeClose(httpResp.Body)
+
+	if httpResp.StatusCode != http.StatusOK {
+		data, _ := io.ReadAll(httpResp.Body)
+		return goerr.New("unexpected status code").With("status", httpResp.Status).With("body", string(data))
+	}
+
+	objName := model.DatasetLogObjectName(ctx, req, name, end, 0)
+	w, err := output.NewLogObjectWriter(ctx, clients.CloudStorage(), req, objName, output.WithWindow(start, end))
+	if err != nil {
+		return err
+	}
+
+	n, err := io.Copy(w, httpResp.Body)
+	if err != nil {
+		w.Abort()
+		return goerr.Wrap(err, "failed to write response to object writer").With("bytes", n)
+	}
+	if err := w.Close(); err != nil {
+		return goerr.Wrap(err, "failed to close object writer").With("object", objName)
+	}
+
+	utils.CtxLogger(ctx).Info("harvested Tailscale logs", "dataset", name, "bytes", n, "object", w.Object())
+
+	return nil
+}
@@ -0,0 +1,93 @@
+package tailscale_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/apple/pkl-go/pkl"
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/hatchery/pkg/actions/tailscale"
+	"github.com/m-mizutani/hatchery/pkg/domain/config"
+	"github.com/m-mizutani/hatchery/pkg/domain/model"
+	"github.com/m-mizutani/hatchery/pkg/infra"
+	"github.com/m-mizutani/hatchery/pkg/infra/cs"
+	"github.com/m-mizutani/hatchery/pkg/utils"
+)
+
+func newMux(t *testing.T, token string) *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET api.tailscale.com/api/v2/tailnet/example.com/logging/{kind}", func(w http.ResponseWriter, r *http.Request) {
+		gt.Equal(t, r.Header.Get("Authorization"), "Bearer "+token)
+		gt.Equal(t, r.URL.Query().Get("start"), "2024-04-01T09:40:00Z")
+		gt.Equal(t, r.URL.Query().Get("end"), "2024-04-01T10:00:00Z")
+		_, _ = w.Write([]byte(`{"logs":[{"kind":"` + r.PathValue("kind") + `"}]}`))
+	})
+	return mux
+}
+
+func TestTailscale(t *testing.T) {
+	ctx := context.Background()
+	_, ctx = utils.CtxRequestID(ctx)
+	now := time.Date(2024, 4, 1, 10, 0, 0, 0, time.UTC)
+	ctx = utils.CtxWithNow(ctx, func() time.Time { return now })
+
+	t.Run("API key", func(t *testing.T) {
+		mock := cs.NewMock()
+		clients := infra.New(infra.WithCloudStorage(mock), infra.WithHTTPClient(utils.NewFakeHTTPClient(newMux(t, "tskey-api-test"))))
+
+		apiKey := "tskey-api-test"
+		req := &config.TailscaleImpl{
+			Tailnet:  "example.com",
+			ApiKey:   &apiKey,
+			Datasets: []string{"configuration", "network"},
+			Duration: &pkl.Duration{Value: 20, Unit: pkl.Minute},
+			Bucket:   "test-bucket",
+		}
+		gt.NoError(t, tailscale.Exec(ctx, clients, req)).Must()
+
+		gt.A(t, mock.Results).Length(2).
+			At(0, func(t testing.TB, v *cs.MockResult) {
+				gt.Equal(t, v.Object, model.DatasetLogObjectName(ctx, req, "configuration", now, 0))
+			}).
+			At(1, func(t testing.TB, v *cs.MockResult) {
+				gt.Equal(t, v.Object, model.DatasetLogObjectName(ctx, req, "network", now, 0))
+			})
+	})
+
+	t.Run("OAuth client", func(t *testing.T) {
+		mux := newMux(t, "oauth-token")
+		mux.HandleFunc("POST api.tailscale.com/api/v2/oauth/token", func(w http.ResponseWriter, r *http.Request) {
+			gt.NoError(t, r.ParseForm())
+			gt.Equal(t, r.PostForm.Get("client_id"), "test-client")
+			gt.Equal(t, r.PostForm.Get("client_secret"), "test-secret")
+			_, _ = w.Write([]byte(`{"access_token":"oauth-token","token_type":"Bearer","expires_in":3600}`))
+		})
+		mock := cs.NewMock()
+		clients := infra.New(infra.WithCloudStorage(mock), infra.WithHTTPClient(utils.NewFakeHTTPClient(mux)))
+
+		clientID, clientSecret := "test-client", "test-secret"
+		req := &config.TailscaleImpl{
+			Tailnet:           "example.com",
+			OauthClientId:     &clientID,
+			OauthClientSecret: &clientSecret,
+			Datasets:          []string{"configuration"},
+			Duration:          &pkl.Duration{Value: 20, Unit: pkl.Minute},
+			Bucket:            "test-bucket",
+		}
+		gt.NoError(t, tailscale.Exec(ctx, clients, req)).Must()
+		gt.A(t, mock.Results).Length(1)
+	})
+
+	t.Run("no credential", func(t *testing.T) {
+		clients := infra.New(infra.WithCloudStorage(cs.NewMock()), infra.WithHTTPClient(utils.NewFakeHTTPClient(newMux(t, ""))))
+		req := &config.TailscaleImpl{
+			Tailnet:  "example.com",
+			Datasets: []string{"configuration"},
+			Duration: &pkl.Duration{Value: 20, Unit: pkl.Minute},
+			Bucket:   "test-bucket",
+		}
+		gt.Error(t, tailscale.Exec(ctx, clients, req))
+	})
+}
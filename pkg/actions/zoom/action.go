@@ -0,0 +1,150 @@
+package zoom
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/m-mizutani/goerr"
+	"github.com/m-mizutani/hatchery/pkg/domain/config"
+	"github.com/m-mizutani/hatchery/pkg/domain/interfaces"
+	"github.com/m-mizutani/hatchery/pkg/domain/model"
+	"github.com/m-mizutani/hatchery/pkg/domain/types"
+	"github.com/m-mizutani/hatchery/pkg/infra"
+	"github.com/m-mizutani/hatchery/pkg/infra/oauth"
+	"github.com/m-mizutani/hatchery/pkg/infra/output"
+	"github.com/m-mizutani/hatchery/pkg/utils"
+)
+
+const (
+	// Server-to-Server OAuth token endpoint.
+	// See https://developers.zoom.us/docs/internal-apps/s2s-oauth/
+	tokenURL = "https://zoom.us/oauth/token"
+	baseURL  = "https://api.zoom.us/v2"
+)
+
+type dataset struct {
+	path string
+	// field name of log entries in response
+	field string
+}
+
+var datasets = map[string]dataset{
+	"activities":     {path: "/report/activities", field: "activity_logs"},
+	"operation_logs": {path: "/report/operationlogs", field: "operation_logs"},
+}
+
+func Exec(ctx context.Context, clients *infra.Clients, req *config.ZoomImpl) error {
+	ts := oauth.NewTokenSource(clients.HTTPClient(), oauth.Config{
+		TokenURL:     tokenURL,
+		ClientID:     req.GetClientId(),
+		ClientSecret: req.GetClientSecret(),
+		BasicAuth:    true,
+		Params: url.Values{
+			"grant_type": {"account_credentials"},
+			"account_id": {req.GetAccountId()},
+		},
+	})
+	httpClient := oauth.NewClient(clients.HTTPClient(), ts)
+
+	now := utils.CtxNow(ctx)
+	for _, name := range req.GetDatasets() {
+		ds, ok := datasets[name]
+		if !ok {
+			return goerr.Wrap(types.ErrInvalidOption, "unsupported Zoom dataset").With("dataset", name)
+		}
+
+		var nextPageToken string
+		for seq := 0; req.MaxPages == nil || seq < *req.MaxPages; seq++ {
+			next, err := crawl(ctx, clients, httpClient, req, name, ds, now, seq, nextPageToken)
+			if err != nil {
+				return goerr.Wrap(err, "failed to crawl Zoom logs").With("dataset", name).With("seq", seq).With("req", req)
+			}
+			if next == nil {
+				break
+			}
+			nextPageToken = *next
+		}
+	}
+
+	return nil
+}
+
+func crawl(ctx context.Context, clients *infra.Clients, httpClient interfaces.HTTPClient, req *config.ZoomImpl, name string, ds dataset, end time.Time, seq int, nextPageToken string) (*string, error) {
+	// Zoom report APIs accept only date, then the range is extended to whole days
+	start := end.Add(-req.GetDuration().GoDuration())
+
+	qv := url.Values{}
+	qv.Add("from", start.UTC().Format("2006-01-02"))
+	qv.Add("to", end.UTC().Format("2006-01-02"))
+	qv.Add("page_size", fmt.Sprintf("%d", req.GetLimit()))
+	if nextPageToken != "" {
+		qv.Add("next_page_token", nextPageToken)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, baseURL+ds.path+"?"+qv.Encode(), nil)
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to create HTTP request")
+	}
+	httpReq.Header.Set("Accept", "application/json")
+
+	httpResp, err := httpClient.Do(httpReq)
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to send HTTP request")
+	}
+	defer utils.SafeClose(httpResp.Body)
+
+	if httpResp.StatusCode != http.StatusOK {
+		data, _ := io.ReadAll(httpResp.Body)
+		return nil, goerr.New("unexpected status code").With("status", httpResp.Status).With("body", string(data))
+	}
+
+	body, err := io.ReadAll(httpResp.Body)
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to read response body")
+	}
+
+	var resp map[string]json.RawMessage
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, goerr.Wrap(err, "failed to unmarshal response body")
+	}
+	var logs []json.RawMessage
+	if raw, ok := resp[ds.field]; ok {
+		if err := json.Unmarshal(raw, &logs); err != nil {
+			return nil, goerr.Wrap(err, "failed to unmarshal log entries").With("field", ds.field)
+		}
+	}
+	var next string
+	if raw, ok := resp["next_page_token"]; ok {
+		if err := json.Unmarshal(raw, &next); err != nil {
+			return nil, goerr.Wrap(err, "failed to unmarshal next_page_token")
+		}
+	}
+
+	objName := model.DatasetLogObjectName(ctx, req, name, end, seq)
+	w, err := output.NewLogObjectWriter(ctx, clients.CloudStorage(), req, objName, output.WithWindow(start, end))
+	if err != nil {
+		return nil, err
+	}
+
+	n, err := io.Copy(w, bytes.NewReader(body))
+	if err != nil {
+		w.Abort()
+		return nil, goerr.Wrap(err, "failed to write response to object writer").With("bytes", n)
+	}
+	if err := w.Close(); err != nil {
+		return nil, goerr.Wrap(err, "failed to close object writer").With("object", objName)
+	}
+
+	utils.CtxLogger(ctx).Info("harvested Zoom logs", "dataset", name, "logs", len(logs), "bytes", n, "object", w.Object())
+
+	if next != "" {
+		return &next, nil
+	}
+	return nil, nil
+}
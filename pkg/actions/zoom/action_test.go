@@ -0,0 +1,82 @@
+package zoom_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/apple/pkl-go/pkl"
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/hatchery/pkg/actions/zoom"
+	"github.com/m-mizutani/hatchery/pkg/domain/config"
+	"github.com/m-mizutani/hatchery/pkg/domain/model"
+	"github.com/m-mizutani/hatchery/pkg/infra"
+	"github.com/m-mizutani/hatchery/pkg/infra/cs"
+	"github.com/m-mizutani/hatchery/pkg/utils"
+)
+
+func TestZoom(t *testing.T) {
+	var tokenCalls int
+	var activityQueries []string
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST zoom.us/oauth/token", func(w http.ResponseWriter, r *http.Request) {
+		tokenCalls++
+		user, pass, ok := r.BasicAuth()
+		gt.Equal(t, ok, true)
+		gt.Equal(t, user, "test-client")
+		gt.Equal(t, pass, "test-secret")
+		gt.NoError(t, r.ParseForm())
+		gt.Equal(t, r.PostForm.Get("grant_type"), "account_credentials")
+		gt.Equal(t, r.PostForm.Get("account_id"), "test-account")
+		_, _ = w.Write([]byte(`{"access_token":"test-token","token_type":"bearer","expires_in":3600}`))
+	})
+	mux.HandleFunc("GET api.zoom.us/v2/report/activities", func(w http.ResponseWriter, r *http.Request) {
+		gt.Equal(t, r.Header.Get("Authorization"), "Bearer test-token")
+		activityQueries = append(activityQueries, r.URL.RawQuery)
+		if r.URL.Query().Get("next_page_token") == "" {
+			_, _ = w.Write([]byte(`{"activity_logs":[{"email":"a@example.com"}],"next_page_token":"page-2"}`))
+		} else {
+			_, _ = w.Write([]byte(`{"activity_logs":[{"email":"b@example.com"}],"next_page_token":""}`))
+		}
+	})
+	mux.HandleFunc("GET api.zoom.us/v2/report/operationlogs", func(w http.ResponseWriter, r *http.Request) {
+		gt.Equal(t, r.Header.Get("Authorization"), "Bearer test-token")
+		_, _ = w.Write([]byte(`{"operation_logs":[{"operator":"admin@example.com"}]}`))
+	})
+
+	mock := cs.NewMock()
+	clients := infra.New(infra.WithCloudStorage(mock), infra.WithHTTPClient(utils.NewFakeHTTPClient(mux)))
+
+	ctx := context.Background()
+	_, ctx = utils.CtxRequestID(ctx)
+	now := time.Date(2024, 4, 2, 10, 0, 0, 0, time.UTC)
+	ctx = utils.CtxWithNow(ctx, func() time.Time { return now })
+
+	req := &config.ZoomImpl{
+		AccountId:    "test-account",
+		ClientId:     "test-client",
+		ClientSecret: "test-secret",
+		Datasets:     []string{"activities", "operation_logs"},
+		Duration:     &pkl.Duration{Value: 1, Unit: pkl.Day},
+		Limit:        300,
+		Bucket:       "test-bucket",
+	}
+	gt.NoError(t, zoom.Exec(ctx, clients, req)).Must()
+
+	gt.Equal(t, tokenCalls, 1)
+	gt.A(t, activityQueries).Equal([]string{
+		"from=2024-04-01&page_size=300&to=2024-04-02",
+		"from=2024-04-01&next_page_token=page-2&page_size=300&to=2024-04-02",
+	})
+	gt.A(t, mock.Results).Length(3).
+		At(0, func(t testing.TB, v *cs.MockResult) {
+			gt.Equal(t, v.Object, model.DatasetLogObjectName(ctx, req, "activities", now, 0))
+		}).
+		At(1, func(t testing.TB, v *cs.MockResult) {
+			gt.Equal(t, v.Object, model.DatasetLogObjectName(ctx, req, "activities", now, 1))
+		}).
+		At(2, func(t testing.TB, v *cs.MockResult) {
+			gt.Equal(t, v.Object, model.DatasetLogObjectName(ctx, req, "operation_logs", now, 0))
+		})
+}
@@ -54,6 +54,9 @@ func Run(argv []string) error {
 		},
 		Commands: []*cli.Command{
 			cmdExec(&rt),
+			cmdServe(&rt),
+			cmdSyslog(&rt),
+			cmdDecrypt(),
 		},
 	}
 
@@ -0,0 +1,116 @@
+package cli
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+
+	"filippo.io/age"
+	"github.com/m-mizutani/goerr"
+	"github.com/m-mizutani/hatchery/pkg/domain/types"
+	"github.com/m-mizutani/hatchery/pkg/infra"
+	"github.com/m-mizutani/hatchery/pkg/infra/cs"
+	"github.com/m-mizutani/hatchery/pkg/infra/envelope"
+	"github.com/m-mizutani/hatchery/pkg/utils"
+	"github.com/urfave/cli/v2"
+)
+
+func cmdDecrypt() *cli.Command {
+	var (
+		bucket      string
+		object      string
+		output      string
+		ageIdentity string
+	)
+
+	return &cli.Command{
+		Name:      "decrypt",
+		Usage:     "Decrypt an object encrypted by envelope encryption",
+		UsageText: `hatchery [global options] decrypt [command options]`,
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:        "bucket",
+				Aliases:     []string{"b"},
+				Usage:       "Bucket of the object",
+				Destination: &bucket,
+				Required:    true,
+			},
+			&cli.StringFlag{
+				Name:        "object",
+				Usage:       "Name of the encrypted object. Its key object (<object>.key) is also read",
+				Destination: &object,
+				Required:    true,
+			},
+			&cli.StringFlag{
+				Name:        "output",
+				Aliases:     []string{"o"},
+				Usage:       "Output file path of the decrypted data. '-' is stdout",
+				Value:       "-",
+				Destination: &output,
+			},
+			&cli.StringFlag{
+				Name:        "age-identity",
+				Usage:       "Path to age identity file for objects encrypted by age",
+				EnvVars:     []string{"HATCHERY_DECRYPT_AGE_IDENTITY"},
+				Destination: &ageIdentity,
+			},
+		},
+		Action: func(c *cli.Context) error {
+			ctx := c.Context
+
+			var opts []envelope.Option
+			if ageIdentity != "" {
+				identities, err := loadAgeIdentities(ageIdentity)
+				if err != nil {
+					return err
+				}
+				opts = append(opts, envelope.WithAgeIdentities(identities...))
+			}
+
+			csClient, err := cs.New(ctx)
+			if err != nil {
+				return err
+			}
+			clients := infra.New(infra.WithCloudStorage(csClient))
+
+			storage, err := envelope.New(ctx, csClient, clients, nil, opts...)
+			if err != nil {
+				return err
+			}
+			r, err := storage.NewObjectReader(ctx, types.CSBucket(bucket), types.CSObjectName(object))
+			if err != nil {
+				return err
+			}
+			defer utils.SafeClose(r)
+
+			var w io.Writer = os.Stdout
+			if output != "-" {
+				f, err := os.OpenFile(filepath.Clean(output), os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+				if err != nil {
+					return goerr.Wrap(err, "failed to open output file").With("path", output)
+				}
+				defer utils.SafeClose(f)
+				w = f
+			}
+
+			if _, err := io.Copy(w, r); err != nil {
+				return goerr.Wrap(err, "failed to decrypt object").With("bucket", bucket).With("object", object)
+			}
+			return nil
+		},
+	}
+}
+
+func loadAgeIdentities(path string) ([]age.Identity, error) {
+	f, err := os.Open(filepath.Clean(path))
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to open age identity file").With("path", path)
+	}
+	defer utils.SafeClose(f)
+
+	identities, err := age.ParseIdentities(f)
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to parse age identity file").With("path", path)
+	}
+	return identities, nil
+}
@@ -1,6 +1,8 @@
 package cli
 
 import (
+	"time"
+
 	"github.com/m-mizutani/hatchery/pkg/domain/model"
 	"github.com/m-mizutani/hatchery/pkg/infra"
 	"github.com/m-mizutani/hatchery/pkg/infra/cs"
@@ -11,10 +13,12 @@ import (
 
 func cmdExec(rt *runtime) *cli.Command {
 	var (
-		actionIDs  cli.StringSlice
-		actionTags cli.StringSlice
-		allAction  bool
-		dryRun     bool
+		actionIDs   cli.StringSlice
+		actionTags  cli.StringSlice
+		allAction   bool
+		dryRun      bool
+		maxParallel int
+		timeout     time.Duration
 	)
 
 	return &cli.Command{
@@ -51,6 +55,19 @@ func cmdExec(rt *runtime) *cli.Command {
 				EnvVars:     []string{"HATCHERY_EXEC_DRY_RUN"},
 				Destination: &dryRun,
 			},
+			&cli.IntFlag{
+				Name:        "max-parallel",
+				Aliases:     []string{"p"},
+				Usage:       "Maximum number of actions executed in parallel. It overrides max_parallel of config, and 0 means the config value",
+				EnvVars:     []string{"HATCHERY_EXEC_MAX_PARALLEL"},
+				Destination: &maxParallel,
+			},
+			&cli.DurationFlag{
+				Name:        "timeout",
+				Usage:       "Maximum duration of whole execution. It overrides exec_timeout of config, and 0 means the config value",
+				EnvVars:     []string{"HATCHERY_EXEC_TIMEOUT"},
+				Destination: &timeout,
+			},
 		},
 		Action: func(c *cli.Context) error {
 			_, ctx := utils.CtxRequestID(c.Context)
@@ -68,6 +85,16 @@ func cmdExec(rt *runtime) *cli.Command {
 			if dryRun {
 				options = append(options, usecase.WithDryRun())
 			}
+			if maxParallel > 0 {
+				options = append(options, usecase.WithMaxParallel(maxParallel))
+			} else if rt.config.MaxParallel != nil {
+				options = append(options, usecase.WithMaxParallel(*rt.config.MaxParallel))
+			}
+			if timeout > 0 {
+				options = append(options, usecase.WithTimeout(timeout))
+			} else if rt.config.ExecTimeout != nil {
+				options = append(options, usecase.WithTimeout(rt.config.ExecTimeout.GoDuration()))
+			}
 
 			csClient, err := cs.New(ctx)
 			if err != nil {
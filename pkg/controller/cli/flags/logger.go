@@ -75,10 +75,29 @@ func (x *Logger) Configure() (*slog.Logger, error) {
 	filter := masq.New(
 		// for OnePassword
 		masq.WithFieldName("ApiToken", redactOpt),
-		// for FalconDataReplicator
-		masq.WithFieldName("AwsSecretAccessKey", redactOpt),
+		// for AWSStaticCredential
+		masq.WithFieldName("SecretAccessKey", redactOpt),
+		masq.WithFieldName("SessionToken", redactOpt),
 		// for Slack
 		masq.WithFieldName("AccessToken", redactOpt),
+		// for Office365 and EntraID
+		masq.WithFieldName("ClientSecret", redactOpt),
+		masq.WithFieldName("ClientPrivateKey", redactOpt),
+		// for AtlassianAudit
+		masq.WithFieldName("ApiKey", redactOpt),
+		// for Dropbox
+		masq.WithFieldName("RefreshToken", redactOpt),
+		// for SalesforceEventLog and SnowflakeAccountUsage
+		masq.WithFieldName("PrivateKey", redactOpt),
+		// for Duo
+		masq.WithFieldName("SecretKey", redactOpt),
+		// for Tailscale
+		masq.WithFieldName("OauthClientSecret", redactOpt),
+		// for JamfPro and KafkaTopic
+		masq.WithFieldName("Password", redactOpt),
+		// for webhook verifiers
+		masq.WithFieldName("Secret", redactOpt),
+		masq.WithFieldName("SigningSecret", redactOpt),
 	)
 
 	// Log level
@@ -0,0 +1,100 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/m-mizutani/goerr"
+	"github.com/m-mizutani/hatchery/pkg/controller/server"
+	"github.com/m-mizutani/hatchery/pkg/infra"
+	"github.com/m-mizutani/hatchery/pkg/infra/cs"
+	"github.com/m-mizutani/hatchery/pkg/utils"
+	"github.com/urfave/cli/v2"
+)
+
+func cmdServe(rt *runtime) *cli.Command {
+	var (
+		addr            string
+		shutdownTimeout time.Duration
+	)
+
+	return &cli.Command{
+		Name:      "serve",
+		Aliases:   []string{"s"},
+		Usage:     "Receive events pushed by webhooks",
+		UsageText: `hatchery [global options] serve [command options]`,
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:        "addr",
+				Usage:       "Listen address",
+				EnvVars:     []string{"HATCHERY_SERVE_ADDR"},
+				Value:       ":8080",
+				Destination: &addr,
+			},
+			&cli.DurationFlag{
+				Name:        "shutdown-timeout",
+				Usage:       "Timeout to wait for in-flight requests on shutdown",
+				EnvVars:     []string{"HATCHERY_SERVE_SHUTDOWN_TIMEOUT"},
+				Value:       30 * time.Second,
+				Destination: &shutdownTimeout,
+			},
+		},
+		Action: func(c *cli.Context) error {
+			ctx, stop := signal.NotifyContext(c.Context, os.Interrupt, syscall.SIGTERM)
+			defer stop()
+
+			csClient, err := cs.New(ctx)
+			if err != nil {
+				return err
+			}
+			clients := infra.New(infra.WithCloudStorage(csClient))
+
+			// Batchers keep flushing after the signal until Close
+			srv, err := server.New(context.WithoutCancel(ctx), clients, rt.config.Webhooks)
+			if err != nil {
+				return err
+			}
+
+			httpServer := &http.Server{
+				Addr:              addr,
+				Handler:           srv,
+				ReadHeaderTimeout: 10 * time.Second,
+			}
+
+			errCh := make(chan error, 1)
+			go func() {
+				utils.Logger().Info("starting server", "addr", addr, "webhooks", len(rt.config.Webhooks))
+				if err := httpServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+					errCh <- goerr.Wrap(err, "failed to listen").With("addr", addr)
+				}
+				close(errCh)
+			}()
+
+			var serveErr error
+			select {
+			case serveErr = <-errCh:
+			case <-ctx.Done():
+				utils.Logger().Info("shutting down server")
+				shutdownCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), shutdownTimeout)
+				defer cancel()
+				if err := httpServer.Shutdown(shutdownCtx); err != nil {
+					serveErr = goerr.Wrap(err, "failed to shutdown server")
+					// Close connections to cancel requests still blocked in queue of batchers. They respond with error and the vendor retries them
+					if err := httpServer.Close(); err != nil {
+						serveErr = errors.Join(serveErr, goerr.Wrap(err, "failed to close server"))
+					}
+				}
+			}
+
+			if err := srv.Close(); err != nil {
+				return errors.Join(serveErr, err)
+			}
+			return serveErr
+		},
+	}
+}
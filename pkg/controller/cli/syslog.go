@@ -0,0 +1,68 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/m-mizutani/goerr"
+	"github.com/m-mizutani/hatchery/pkg/controller/syslog"
+	"github.com/m-mizutani/hatchery/pkg/domain/types"
+	"github.com/m-mizutani/hatchery/pkg/infra"
+	"github.com/m-mizutani/hatchery/pkg/infra/cs"
+	"github.com/urfave/cli/v2"
+)
+
+func cmdSyslog(rt *runtime) *cli.Command {
+	return &cli.Command{
+		Name:      "syslog",
+		Usage:     "Receive syslog messages",
+		UsageText: `hatchery [global options] syslog`,
+		Action: func(c *cli.Context) error {
+			if len(rt.config.Syslogs) == 0 {
+				return goerr.Wrap(types.ErrInvalidOption, "no syslog listener in config")
+			}
+
+			ctx, stop := signal.NotifyContext(c.Context, os.Interrupt, syscall.SIGTERM)
+			defer stop()
+
+			csClient, err := cs.New(ctx)
+			if err != nil {
+				return err
+			}
+			clients := infra.New(infra.WithCloudStorage(csClient))
+
+			var listeners []*syslog.Listener
+			for _, cfg := range rt.config.Syslogs {
+				// Batchers keep flushing after the signal until Serve returns
+				ln, err := syslog.New(context.WithoutCancel(ctx), clients, cfg)
+				if err != nil {
+					// Release opened listeners by serving with canceled context
+					stop()
+					for _, l := range listeners {
+						_ = l.Serve(ctx)
+					}
+					return err
+				}
+				listeners = append(listeners, ln)
+			}
+
+			errCh := make(chan error, len(listeners))
+			for _, ln := range listeners {
+				go func(ln *syslog.Listener) {
+					errCh <- ln.Serve(ctx)
+				}(ln)
+			}
+
+			var errs []error
+			for range listeners {
+				if err := <-errCh; err != nil {
+					errs = append(errs, err)
+				}
+			}
+			return errors.Join(errs...)
+		},
+	}
+}
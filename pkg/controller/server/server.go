@@ -0,0 +1,158 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/m-mizutani/goerr"
+	"github.com/m-mizutani/hatchery/pkg/domain/config"
+	"github.com/m-mizutani/hatchery/pkg/domain/types"
+	"github.com/m-mizutani/hatchery/pkg/infra"
+	"github.com/m-mizutani/hatchery/pkg/infra/batch"
+	"github.com/m-mizutani/hatchery/pkg/infra/cs"
+	"github.com/m-mizutani/hatchery/pkg/utils"
+)
+
+// Server receives events pushed by SaaS vendors on the endpoints of webhooks and stores them into CloudStorage in batches.
+type Server struct {
+	mux      *http.ServeMux
+	batchers []*batch.Batcher
+	// inflight counts requests being handled, to flush their events on Close
+	inflight sync.WaitGroup
+}
+
+// New creates Server and starts batchers of webhooks. ctx is used for flushing, and Close must be called to flush remaining events.
+func New(ctx context.Context, clients *infra.Clients, webhooks []config.Webhook) (*Server, error) {
+	s := &Server{
+		mux: http.NewServeMux(),
+	}
+
+	paths := map[string]struct{}{}
+	for _, hook := range webhooks {
+		if _, ok := paths[hook.GetPath()]; ok {
+			return nil, goerr.Wrap(types.ErrInvalidOption, "duplicated webhook path").With("path", hook.GetPath())
+		}
+		paths[hook.GetPath()] = struct{}{}
+
+		v, err := newVerifier(hook.GetVerifier())
+		if err != nil {
+			return nil, goerr.Wrap(err, "failed to configure webhook").With("id", hook.GetId())
+		}
+
+		storage, err := cs.ForAction(ctx, clients, hook)
+		if err != nil {
+			return nil, err
+		}
+		b, err := batch.NewStream(ctx, storage, hook)
+		if err != nil {
+			return nil, err
+		}
+		s.batchers = append(s.batchers, b)
+		s.mux.Handle(hook.GetPath(), &handler{hook: hook, verifier: v, batcher: b})
+	}
+
+	return s, nil
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.inflight.Add(1)
+	defer s.inflight.Done()
+	s.mux.ServeHTTP(w, r)
+}
+
+// Close waits for requests being handled and flushes buffered events of all webhooks. It must be called after the HTTP server stops serving.
+func (s *Server) Close() error {
+	s.inflight.Wait()
+
+	var errs []error
+	for _, b := range s.batchers {
+		if err := b.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) > 0 {
+		return goerr.Wrap(errors.Join(errs...), "failed to flush webhook events")
+	}
+	return nil
+}
+
+type handler struct {
+	hook     config.Webhook
+	verifier verifier
+	batcher  *batch.Batcher
+}
+
+// record is stored as a line of NDJSON object
+type record struct {
+	ReceivedAt time.Time         `json:"received_at"`
+	WebhookID  string            `json:"webhook_id"`
+	Headers    map[string]string `json:"headers,omitempty"`
+	// Body is stored as JSON if possible, otherwise as string
+	Body any `json:"body"`
+}
+
+func (x *handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := utils.CtxLogger(ctx).With("webhook", x.hook.GetId(), "path", r.URL.Path)
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, int64(x.hook.GetMaxBodyBytes())))
+	if err != nil {
+		logger.Warn("failed to read webhook body", utils.ErrLog(err))
+		http.Error(w, "request body too large", http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	if err := x.verifier.verify(r, body); err != nil {
+		logger.Warn("webhook verification failed", utils.ErrLog(err))
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	if x.verifier.challenge(w, r, body) {
+		logger.Info("answered verification challenge of webhook")
+		return
+	}
+
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	rec := record{
+		ReceivedAt: utils.CtxNow(ctx).UTC(),
+		WebhookID:  x.hook.GetId(),
+		Body:       string(body),
+	}
+	if json.Valid(body) {
+		rec.Body = json.RawMessage(body)
+	}
+	for _, key := range x.hook.GetRecordHeaders() {
+		if v := r.Header.Get(key); v != "" {
+			if rec.Headers == nil {
+				rec.Headers = map[string]string{}
+			}
+			rec.Headers[key] = v
+		}
+	}
+
+	raw, err := json.Marshal(rec)
+	if err != nil {
+		utils.HandleError(ctx, "failed to marshal webhook record", goerr.Wrap(err, "failed to marshal webhook record"))
+		http.Error(w, "internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	// Add blocks while the destination is unavailable, and the vendor will retry the request after timeout
+	if err := x.batcher.Add(ctx, raw); err != nil {
+		logger.Warn("failed to queue webhook record", utils.ErrLog(err))
+		http.Error(w, "service unavailable", http.StatusServiceUnavailable)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
@@ -0,0 +1,175 @@
+package server_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/hatchery/pkg/controller/server"
+	"github.com/m-mizutani/hatchery/pkg/domain/config"
+	"github.com/m-mizutani/hatchery/pkg/infra"
+	"github.com/m-mizutani/hatchery/pkg/infra/cs"
+	"github.com/m-mizutani/hatchery/pkg/utils"
+)
+
+func sign(secret, data string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	_, _ = mac.Write([]byte(data))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func newWebhook(id, path string, v config.WebhookVerifier) *config.WebhookImpl {
+	return &config.WebhookImpl{
+		Id:            id,
+		Bucket:        "test-bucket",
+		Path:          path,
+		Verifier:      v,
+		RecordHeaders: []string{"X-GitHub-Event"},
+		MaxBodyBytes:  1024,
+		MaxRecords:    100,
+		MaxBytes:      1024 * 1024,
+	}
+}
+
+func send(t *testing.T, ctx context.Context, h http.Handler, method, path, body string, header map[string]string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(method, path, strings.NewReader(body)).WithContext(ctx)
+	for k, v := range header {
+		req.Header.Set(k, v)
+	}
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	return w
+}
+
+func readRecords(t *testing.T, result *cs.MockResult) []map[string]any {
+	r := gt.R1(gzip.NewReader(bytes.NewReader(result.Body.Bytes()))).NoError(t)
+	data := gt.R1(io.ReadAll(r)).NoError(t)
+
+	var records []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		var rec map[string]any
+		gt.NoError(t, json.Unmarshal([]byte(line), &rec))
+		records = append(records, rec)
+	}
+	return records
+}
+
+func TestServer(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 4, 1, 10, 0, 0, 0, time.UTC)
+	ctx = utils.CtxWithNow(ctx, func() time.Time { return now })
+
+	mock := cs.NewMock()
+	clients := infra.New(infra.WithCloudStorage(mock))
+
+	hooks := []config.Webhook{
+		newWebhook("github", "/github", &config.GitHubSignatureImpl{Secret: "gh-secret"}),
+		newWebhook("slack", "/slack", &config.SlackSignatureImpl{SigningSecret: "slack-secret"}),
+		newWebhook("shared", "/shared", &config.SharedSecretImpl{Header: "Authorization", Secret: "token"}),
+		newWebhook("okta", "/okta", &config.OktaEventHookImpl{Header: "Authorization", Secret: "okta-token"}),
+	}
+	srv := gt.R1(server.New(ctx, clients, hooks)).NoError(t)
+
+	t.Run("GitHub", func(t *testing.T) {
+		body := `{"action":"opened"}`
+		gt.Equal(t, send(t, ctx, srv, http.MethodPost, "/github", body, map[string]string{
+			"X-Hub-Signature-256": "sha256=" + sign("gh-secret", body),
+			"X-GitHub-Event":      "issues",
+		}).Code, http.StatusOK)
+
+		gt.Equal(t, send(t, ctx, srv, http.MethodPost, "/github", body, map[string]string{
+			"X-Hub-Signature-256": "sha256=" + sign("wrong", body),
+		}).Code, http.StatusUnauthorized)
+	})
+
+	t.Run("Slack", func(t *testing.T) {
+		ts := strconv.FormatInt(now.Unix(), 10)
+		challenge := `{"type":"url_verification","challenge":"abc"}`
+		w := send(t, ctx, srv, http.MethodPost, "/slack", challenge, map[string]string{
+			"X-Slack-Request-Timestamp": ts,
+			"X-Slack-Signature":         "v0=" + sign("slack-secret", "v0:"+ts+":"+challenge),
+		})
+		gt.Equal(t, w.Code, http.StatusOK)
+		gt.Equal(t, w.Body.String(), "abc")
+
+		body := `{"type":"event_callback"}`
+		gt.Equal(t, send(t, ctx, srv, http.MethodPost, "/slack", body, map[string]string{
+			"X-Slack-Request-Timestamp": ts,
+			"X-Slack-Signature":         "v0=" + sign("slack-secret", "v0:"+ts+":"+body),
+		}).Code, http.StatusOK)
+
+		// Replayed request is rejected
+		old := strconv.FormatInt(now.Add(-10*time.Minute).Unix(), 10)
+		gt.Equal(t, send(t, ctx, srv, http.MethodPost, "/slack", body, map[string]string{
+			"X-Slack-Request-Timestamp": old,
+			"X-Slack-Signature":         "v0=" + sign("slack-secret", "v0:"+old+":"+body),
+		}).Code, http.StatusUnauthorized)
+	})
+
+	t.Run("SharedSecret", func(t *testing.T) {
+		gt.Equal(t, send(t, ctx, srv, http.MethodPost, "/shared", "plain text", map[string]string{
+			"Authorization": "token",
+		}).Code, http.StatusOK)
+		gt.Equal(t, send(t, ctx, srv, http.MethodPost, "/shared", "plain text", nil).Code, http.StatusUnauthorized)
+
+		// Body exceeds max_body_bytes
+		gt.Equal(t, send(t, ctx, srv, http.MethodPost, "/shared", strings.Repeat("x", 2048), map[string]string{
+			"Authorization": "token",
+		}).Code, http.StatusRequestEntityTooLarge)
+	})
+
+	t.Run("Okta", func(t *testing.T) {
+		w := send(t, ctx, srv, http.MethodGet, "/okta", "", map[string]string{
+			"Authorization":                 "okta-token",
+			"X-Okta-Verification-Challenge": "xyz",
+		})
+		gt.Equal(t, w.Code, http.StatusOK)
+		gt.Equal(t, strings.TrimSpace(w.Body.String()), `{"verification":"xyz"}`)
+	})
+
+	t.Run("unknown path", func(t *testing.T) {
+		gt.Equal(t, send(t, ctx, srv, http.MethodPost, "/unknown", "{}", nil).Code, http.StatusNotFound)
+	})
+
+	gt.NoError(t, srv.Close())
+
+	gt.A(t, mock.Results).Length(3)
+
+	for _, r := range mock.Results {
+		records := readRecords(t, r)
+		gt.A(t, records).Length(1)
+		switch records[0]["webhook_id"] {
+		case "github":
+			gt.Equal(t, records[0]["body"], any(map[string]any{"action": "opened"}))
+			gt.Equal(t, records[0]["headers"], any(map[string]any{"X-GitHub-Event": "issues"}))
+		case "slack":
+			gt.Equal(t, records[0]["body"], any(map[string]any{"type": "event_callback"}))
+		case "shared":
+			gt.Equal(t, records[0]["body"], any("plain text"))
+		default:
+			t.Errorf("unexpected record: %v", records[0])
+		}
+	}
+}
+
+func TestDuplicatedPath(t *testing.T) {
+	hooks := []config.Webhook{
+		newWebhook("a", "/hook", &config.SharedSecretImpl{Header: "Authorization", Secret: "x"}),
+		newWebhook("b", "/hook", &config.SharedSecretImpl{Header: "Authorization", Secret: "x"}),
+	}
+	_, err := server.New(context.Background(), infra.New(), hooks)
+	gt.Error(t, err)
+}
@@ -0,0 +1,144 @@
+package server
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/m-mizutani/goerr"
+	"github.com/m-mizutani/hatchery/pkg/domain/config"
+	"github.com/m-mizutani/hatchery/pkg/domain/types"
+	"github.com/m-mizutani/hatchery/pkg/utils"
+)
+
+// verifier authenticates a webhook request. If challenge returns true, the request is a verification request of the vendor and it has been answered, then the body must not be recorded.
+type verifier interface {
+	verify(r *http.Request, body []byte) error
+	challenge(w http.ResponseWriter, r *http.Request, body []byte) bool
+}
+
+var errUnauthorized = goerr.New("unauthorized webhook request")
+
+func newVerifier(cfg config.WebhookVerifier) (verifier, error) {
+	switch v := cfg.(type) {
+	case *config.GitHubSignatureImpl:
+		return &githubVerifier{secret: []byte(v.GetSecret())}, nil
+	case *config.SlackSignatureImpl:
+		tolerance := 5 * time.Minute
+		if v.GetTolerance() != nil {
+			tolerance = v.GetTolerance().GoDuration()
+		}
+		return &slackVerifier{secret: []byte(v.GetSigningSecret()), tolerance: tolerance}, nil
+	case *config.SharedSecretImpl:
+		return &sharedSecretVerifier{header: v.GetHeader(), secret: v.GetSecret()}, nil
+	case *config.OktaEventHookImpl:
+		return &oktaVerifier{sharedSecretVerifier{header: v.GetHeader(), secret: v.GetSecret()}}, nil
+	default:
+		return nil, goerr.Wrap(types.ErrInvalidOption, "unknown webhook verifier").With("verifier", fmt.Sprintf("%T", cfg))
+	}
+}
+
+func hmacSHA256(secret, data []byte) []byte {
+	mac := hmac.New(sha256.New, secret)
+	_, _ = mac.Write(data)
+	return mac.Sum(nil)
+}
+
+// githubVerifier validates X-Hub-Signature-256 header.
+// See https://docs.github.com/en/webhooks/using-webhooks/validating-webhook-deliveries
+type githubVerifier struct {
+	secret []byte
+}
+
+func (x *githubVerifier) verify(r *http.Request, body []byte) error {
+	expected := "sha256=" + hex.EncodeToString(hmacSHA256(x.secret, body))
+	if !hmac.Equal([]byte(r.Header.Get("X-Hub-Signature-256")), []byte(expected)) {
+		return goerr.Wrap(errUnauthorized, "signature mismatch of GitHub webhook")
+	}
+	return nil
+}
+
+func (x *githubVerifier) challenge(w http.ResponseWriter, r *http.Request, body []byte) bool {
+	return false
+}
+
+// slackVerifier validates X-Slack-Signature header with request timestamp.
+// See https://api.slack.com/authentication/verifying-requests-from-slack
+type slackVerifier struct {
+	secret    []byte
+	tolerance time.Duration
+}
+
+func (x *slackVerifier) verify(r *http.Request, body []byte) error {
+	tsHeader := r.Header.Get("X-Slack-Request-Timestamp")
+	ts, err := strconv.ParseInt(tsHeader, 10, 64)
+	if err != nil {
+		return goerr.Wrap(errUnauthorized, "invalid X-Slack-Request-Timestamp").With("timestamp", tsHeader)
+	}
+
+	now := utils.CtxNow(r.Context())
+	if diff := now.Sub(time.Unix(ts, 0)); diff > x.tolerance || diff < -x.tolerance {
+		return goerr.Wrap(errUnauthorized, "X-Slack-Request-Timestamp is out of tolerance").With("timestamp", tsHeader)
+	}
+
+	base := append([]byte("v0:"+tsHeader+":"), body...)
+	expected := "v0=" + hex.EncodeToString(hmacSHA256(x.secret, base))
+	if !hmac.Equal([]byte(r.Header.Get("X-Slack-Signature")), []byte(expected)) {
+		return goerr.Wrap(errUnauthorized, "signature mismatch of Slack request")
+	}
+	return nil
+}
+
+// challenge answers url_verification of Slack Events API.
+func (x *slackVerifier) challenge(w http.ResponseWriter, r *http.Request, body []byte) bool {
+	var req struct {
+		Type      string `json:"type"`
+		Challenge string `json:"challenge"`
+	}
+	if err := json.Unmarshal(body, &req); err != nil || req.Type != "url_verification" {
+		return false
+	}
+
+	w.Header().Set("Content-Type", "text/plain")
+	_, _ = w.Write([]byte(req.Challenge))
+	return true
+}
+
+type sharedSecretVerifier struct {
+	header string
+	secret string
+}
+
+func (x *sharedSecretVerifier) verify(r *http.Request, body []byte) error {
+	if subtle.ConstantTimeCompare([]byte(r.Header.Get(x.header)), []byte(x.secret)) != 1 {
+		return goerr.Wrap(errUnauthorized, "shared secret mismatch").With("header", x.header)
+	}
+	return nil
+}
+
+func (x *sharedSecretVerifier) challenge(w http.ResponseWriter, r *http.Request, body []byte) bool {
+	return false
+}
+
+// oktaVerifier authenticates by the header configured in event hook, and answers one-time verification request.
+// See https://developer.okta.com/docs/concepts/event-hooks/#one-time-verification-request
+type oktaVerifier struct {
+	sharedSecretVerifier
+}
+
+func (x *oktaVerifier) challenge(w http.ResponseWriter, r *http.Request, body []byte) bool {
+	value := r.Header.Get("X-Okta-Verification-Challenge")
+	if r.Method != http.MethodGet || value == "" {
+		return false
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]string{"verification": value})
+	return true
+}
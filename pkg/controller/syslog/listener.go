@@ -0,0 +1,275 @@
+package syslog
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"strconv"
+	"sync"
+
+	"github.com/m-mizutani/goerr"
+	"github.com/m-mizutani/hatchery/pkg/domain/config"
+	"github.com/m-mizutani/hatchery/pkg/domain/types"
+	"github.com/m-mizutani/hatchery/pkg/infra"
+	"github.com/m-mizutani/hatchery/pkg/infra/batch"
+	"github.com/m-mizutani/hatchery/pkg/infra/cs"
+	"github.com/m-mizutani/hatchery/pkg/utils"
+)
+
+// Listener receives syslog messages on UDP, TCP and TLS addresses of Syslog config and stores them into CloudStorage in batches.
+type Listener struct {
+	cfg     config.Syslog
+	batcher *batch.Batcher
+
+	packetConns []net.PacketConn
+	listeners   []net.Listener
+
+	mutex sync.Mutex
+	conns map[net.Conn]struct{}
+	wg    sync.WaitGroup
+}
+
+// New opens addresses of cfg and starts batcher. ctx is used for flushing. Call Serve to start receiving messages.
+func New(ctx context.Context, clients *infra.Clients, cfg config.Syslog) (*Listener, error) {
+	if cfg.GetUdpAddr() == nil && cfg.GetTcpAddr() == nil && cfg.GetTls() == nil {
+		return nil, goerr.Wrap(types.ErrInvalidOption, "no address of syslog listener").With("id", cfg.GetId())
+	}
+
+	x := &Listener{
+		cfg:   cfg,
+		conns: map[net.Conn]struct{}{},
+	}
+
+	if addr := cfg.GetUdpAddr(); addr != nil {
+		conn, err := net.ListenPacket("udp", *addr)
+		if err != nil {
+			return nil, goerr.Wrap(err, "failed to listen UDP").With("addr", *addr)
+		}
+		x.packetConns = append(x.packetConns, conn)
+	}
+
+	if addr := cfg.GetTcpAddr(); addr != nil {
+		ln, err := net.Listen("tcp", *addr)
+		if err != nil {
+			x.closeListeners()
+			return nil, goerr.Wrap(err, "failed to listen TCP").With("addr", *addr)
+		}
+		x.listeners = append(x.listeners, ln)
+	}
+
+	if tlsCfg := cfg.GetTls(); tlsCfg != nil {
+		cert, err := tls.LoadX509KeyPair(tlsCfg.CertFile, tlsCfg.KeyFile)
+		if err != nil {
+			x.closeListeners()
+			return nil, goerr.Wrap(err, "failed to load TLS certificate").With("cert_file", tlsCfg.CertFile)
+		}
+		ln, err := tls.Listen("tcp", tlsCfg.Addr, &tls.Config{
+			Certificates: []tls.Certificate{cert},
+			MinVersion:   tls.VersionTLS12,
+		})
+		if err != nil {
+			x.closeListeners()
+			return nil, goerr.Wrap(err, "failed to listen TLS").With("addr", tlsCfg.Addr)
+		}
+		x.listeners = append(x.listeners, ln)
+	}
+
+	storage, err := cs.ForAction(ctx, clients, cfg)
+	if err != nil {
+		x.closeListeners()
+		return nil, err
+	}
+	b, err := batch.NewStream(ctx, storage, cfg)
+	if err != nil {
+		x.closeListeners()
+		return nil, err
+	}
+	x.batcher = b
+
+	return x, nil
+}
+
+// Addrs returns listening addresses. It is useful when port 0 is specified.
+func (x *Listener) Addrs() []net.Addr {
+	var addrs []net.Addr
+	for _, conn := range x.packetConns {
+		addrs = append(addrs, conn.LocalAddr())
+	}
+	for _, ln := range x.listeners {
+		addrs = append(addrs, ln.Addr())
+	}
+	return addrs
+}
+
+// Serve receives messages until ctx is canceled, then flushes remaining records.
+func (x *Listener) Serve(ctx context.Context) error {
+	logger := utils.CtxLogger(ctx).With("id", x.cfg.GetId())
+
+	for _, conn := range x.packetConns {
+		x.wg.Add(1)
+		go func(conn net.PacketConn) {
+			defer x.wg.Done()
+			x.serveUDP(ctx, conn)
+		}(conn)
+	}
+	for _, ln := range x.listeners {
+		x.wg.Add(1)
+		go func(ln net.Listener) {
+			defer x.wg.Done()
+			x.acceptLoop(ctx, ln)
+		}(ln)
+	}
+	for _, addr := range x.Addrs() {
+		logger.Info("listening syslog", "network", addr.Network(), "addr", addr.String())
+	}
+
+	<-ctx.Done()
+	x.closeListeners()
+
+	x.mutex.Lock()
+	for conn := range x.conns {
+		utils.SafeClose(conn)
+	}
+	x.mutex.Unlock()
+
+	x.wg.Wait()
+	return x.batcher.Close()
+}
+
+func (x *Listener) closeListeners() {
+	for _, conn := range x.packetConns {
+		utils.SafeClose(conn)
+	}
+	for _, ln := range x.listeners {
+		utils.SafeClose(ln)
+	}
+}
+
+func (x *Listener) serveUDP(ctx context.Context, conn net.PacketConn) {
+	buf := make([]byte, x.cfg.GetMaxMessageBytes())
+	for {
+		n, addr, err := conn.ReadFrom(buf)
+		if err != nil {
+			if !errors.Is(err, net.ErrClosed) {
+				utils.HandleError(ctx, "failed to read UDP syslog", goerr.Wrap(err, "failed to read UDP"))
+			}
+			return
+		}
+
+		// Datagram longer than buffer is truncated
+		x.handle(ctx, addr.String(), buf[:n])
+	}
+}
+
+func (x *Listener) acceptLoop(ctx context.Context, ln net.Listener) {
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			if !errors.Is(err, net.ErrClosed) {
+				utils.HandleError(ctx, "failed to accept syslog connection", goerr.Wrap(err, "failed to accept"))
+			}
+			return
+		}
+
+		x.mutex.Lock()
+		x.conns[conn] = struct{}{}
+		x.mutex.Unlock()
+
+		x.wg.Add(1)
+		go func() {
+			defer x.wg.Done()
+			defer func() {
+				x.mutex.Lock()
+				delete(x.conns, conn)
+				x.mutex.Unlock()
+				utils.SafeClose(conn)
+			}()
+
+			if err := x.serveStream(ctx, conn); err != nil && !errors.Is(err, net.ErrClosed) {
+				utils.CtxLogger(ctx).Warn("syslog connection closed by error", "remote", conn.RemoteAddr().String(), utils.ErrLog(err))
+			}
+		}()
+	}
+}
+
+// serveStream reads messages framed by octet counting ("LEN SP MSG") or LF (RFC 6587). Framing is detected for each message by the first character.
+func (x *Listener) serveStream(ctx context.Context, conn net.Conn) error {
+	r := bufio.NewReaderSize(conn, 64*1024)
+	remote := conn.RemoteAddr().String()
+	maxBytes := x.cfg.GetMaxMessageBytes()
+
+	for {
+		head, err := r.Peek(1)
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return goerr.Wrap(err, "failed to read syslog stream")
+		}
+
+		var msg []byte
+		if head[0] >= '1' && head[0] <= '9' {
+			lenStr, err := r.ReadString(' ')
+			if err != nil {
+				return goerr.Wrap(err, "failed to read message length")
+			}
+			n, err := strconv.Atoi(lenStr[:len(lenStr)-1])
+			if err != nil || n > maxBytes {
+				return goerr.Wrap(errInvalidMessage, "invalid message length").With("length", lenStr)
+			}
+			msg = make([]byte, n)
+			if _, err := io.ReadFull(r, msg); err != nil {
+				return goerr.Wrap(err, "failed to read message")
+			}
+		} else {
+			for {
+				line, err := r.ReadSlice('\n')
+				msg = append(msg, line...)
+				if len(msg) > maxBytes {
+					return goerr.Wrap(errInvalidMessage, "message is too long").With("max", maxBytes)
+				}
+				if errors.Is(err, bufio.ErrBufferFull) {
+					continue
+				}
+				if err != nil && !(errors.Is(err, io.EOF) && len(msg) > 0) {
+					return goerr.Wrap(err, "failed to read message")
+				}
+				break
+			}
+		}
+
+		x.handle(ctx, remote, msg)
+	}
+}
+
+func (x *Listener) handle(ctx context.Context, source string, data []byte) {
+	data = bytes.TrimRight(data, "\r\n\x00")
+	if len(data) == 0 {
+		return
+	}
+
+	now := utils.CtxNow(ctx)
+	msg, err := Parse(data, now)
+	if err != nil {
+		utils.CtxLogger(ctx).Debug("failed to parse syslog message", utils.ErrLog(err))
+		msg = &Message{Format: FormatUnknown, Message: string(data)}
+	}
+	msg.ReceivedAt = now.UTC()
+	msg.Source = source
+
+	raw, err := json.Marshal(msg)
+	if err != nil {
+		utils.HandleError(ctx, "failed to marshal syslog record", goerr.Wrap(err, "failed to marshal syslog record"))
+		return
+	}
+
+	// Add blocks while the destination is unavailable and spool is full. UDP messages are dropped by kernel in the meantime.
+	if err := x.batcher.Add(ctx, raw); err != nil && !errors.Is(err, context.Canceled) {
+		utils.HandleError(ctx, "failed to queue syslog record", err)
+	}
+}
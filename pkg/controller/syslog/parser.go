@@ -0,0 +1,229 @@
+package syslog
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/m-mizutani/goerr"
+)
+
+// Message is a parsed syslog message to be stored as JSON record.
+type Message struct {
+	ReceivedAt time.Time  `json:"received_at"`
+	Source     string     `json:"source,omitempty"`
+	Format     string     `json:"format"`
+	Timestamp  *time.Time `json:"timestamp,omitempty"`
+	Facility   int        `json:"facility"`
+	Severity   string     `json:"severity"`
+	Host       string     `json:"host,omitempty"`
+	App        string     `json:"app,omitempty"`
+	ProcID     string     `json:"proc_id,omitempty"`
+	MsgID      string     `json:"msg_id,omitempty"`
+	// StructuredData is a map of SD-ID to parameters (RFC 5424 only)
+	StructuredData map[string]map[string]string `json:"structured_data,omitempty"`
+	Message        string                       `json:"message"`
+}
+
+const (
+	FormatRFC5424 = "rfc5424"
+	FormatRFC3164 = "rfc3164"
+	// FormatUnknown is a message without valid PRI. Whole message is stored as it is.
+	FormatUnknown = "unknown"
+)
+
+var severities = []string{"emerg", "alert", "crit", "err", "warning", "notice", "info", "debug"}
+
+var errInvalidMessage = goerr.New("invalid syslog message")
+
+// Parse parses RFC 5424 or RFC 3164 message. now is used to complement year of RFC 3164 timestamp.
+func Parse(data []byte, now time.Time) (*Message, error) {
+	data = bytes.TrimRight(data, "\r\n\x00")
+	pri, rest, err := parsePRI(data)
+	if err != nil {
+		return nil, err
+	}
+
+	msg := &Message{
+		Facility: pri / 8,
+		Severity: severities[pri%8],
+	}
+
+	// RFC 5424 has VERSION "1" just after PRI
+	if len(rest) >= 2 && rest[0] == '1' && rest[1] == ' ' {
+		if err := parseRFC5424(msg, string(rest[2:])); err != nil {
+			return nil, err
+		}
+		return msg, nil
+	}
+
+	parseRFC3164(msg, string(rest), now)
+	return msg, nil
+}
+
+func parsePRI(data []byte) (int, []byte, error) {
+	if len(data) < 3 || data[0] != '<' {
+		return 0, nil, goerr.Wrap(errInvalidMessage, "PRI is not found")
+	}
+	end := bytes.IndexByte(data[:min(len(data), 5)], '>')
+	if end < 2 {
+		return 0, nil, goerr.Wrap(errInvalidMessage, "PRI is not closed")
+	}
+	pri, err := strconv.Atoi(string(data[1:end]))
+	if err != nil || pri < 0 || pri > 191 {
+		return 0, nil, goerr.Wrap(errInvalidMessage, "invalid PRI").With("pri", string(data[1:end]))
+	}
+	return pri, data[end+1:], nil
+}
+
+// nextField returns a field separated by SP and the rest.
+func nextField(s string) (string, string) {
+	if i := strings.IndexByte(s, ' '); i >= 0 {
+		return s[:i], s[i+1:]
+	}
+	return s, ""
+}
+
+func nilValue(s string) string {
+	if s == "-" {
+		return ""
+	}
+	return s
+}
+
+// parseRFC5424 parses after VERSION: TIMESTAMP HOSTNAME APP-NAME PROCID MSGID STRUCTURED-DATA [MSG]
+func parseRFC5424(msg *Message, s string) error {
+	msg.Format = FormatRFC5424
+
+	var ts, host, app, procID, msgID string
+	ts, s = nextField(s)
+	host, s = nextField(s)
+	app, s = nextField(s)
+	procID, s = nextField(s)
+	msgID, s = nextField(s)
+
+	if ts != "-" {
+		t, err := time.Parse(time.RFC3339Nano, ts)
+		if err != nil {
+			return goerr.Wrap(errInvalidMessage, "invalid timestamp").With("timestamp", ts)
+		}
+		msg.Timestamp = &t
+	}
+	msg.Host = nilValue(host)
+	msg.App = nilValue(app)
+	msg.ProcID = nilValue(procID)
+	msg.MsgID = nilValue(msgID)
+
+	if strings.HasPrefix(s, "-") {
+		s = strings.TrimPrefix(s[1:], " ")
+	} else {
+		sd, rest, err := parseStructuredData(s)
+		if err != nil {
+			return err
+		}
+		msg.StructuredData = sd
+		s = strings.TrimPrefix(rest, " ")
+	}
+
+	msg.Message = strings.TrimPrefix(s, "\ufeff")
+	return nil
+}
+
+// parseStructuredData parses SD-ELEMENTs such as `[id@123 key="value"][id2 key="a\"b"]`.
+func parseStructuredData(s string) (map[string]map[string]string, string, error) {
+	sd := map[string]map[string]string{}
+	for strings.HasPrefix(s, "[") {
+		s = s[1:]
+		end := strings.IndexAny(s, " ]")
+		if end < 0 {
+			return nil, "", goerr.Wrap(errInvalidMessage, "SD-ELEMENT is not closed")
+		}
+		params := map[string]string{}
+		sd[s[:end]] = params
+		s = s[end:]
+
+		for {
+			s = strings.TrimLeft(s, " ")
+			if strings.HasPrefix(s, "]") {
+				s = s[1:]
+				break
+			}
+
+			eq := strings.Index(s, `="`)
+			if eq < 0 {
+				return nil, "", goerr.Wrap(errInvalidMessage, "invalid SD-PARAM")
+			}
+			name := s[:eq]
+			s = s[eq+2:]
+
+			var value strings.Builder
+			closed := false
+			for i := 0; i < len(s); i++ {
+				if s[i] == '\\' && i+1 < len(s) && strings.IndexByte(`"\]`, s[i+1]) >= 0 {
+					value.WriteByte(s[i+1])
+					i++
+				} else if s[i] == '"' {
+					s = s[i+1:]
+					closed = true
+					break
+				} else {
+					value.WriteByte(s[i])
+				}
+			}
+			if !closed {
+				return nil, "", goerr.Wrap(errInvalidMessage, "PARAM-VALUE is not closed")
+			}
+			params[name] = value.String()
+		}
+	}
+
+	return sd, s, nil
+}
+
+// parseRFC3164 parses BSD syslog: TIMESTAMP HOSTNAME TAG[PID]: MSG. As RFC 3164 messages vary by implementation, unrecognized part is left in Message.
+func parseRFC3164(msg *Message, s string, now time.Time) {
+	msg.Format = FormatRFC3164
+
+	// "Jan  2 15:04:05" has fixed length of 15
+	if len(s) >= 16 && s[15] == ' ' {
+		if t, err := time.ParseInLocation(time.Stamp, s[:15], now.Location()); err == nil {
+			t = t.AddDate(now.Year(), 0, 0)
+			// Message at the end of year may be received in the next year
+			if t.After(now.AddDate(0, 0, 1)) {
+				t = t.AddDate(-1, 0, 0)
+			}
+			msg.Timestamp = &t
+			s = s[16:]
+		}
+	}
+	if msg.Timestamp == nil {
+		// Some implementations send RFC 3339 timestamp
+		if ts, rest := nextField(s); ts != "" {
+			if t, err := time.Parse(time.RFC3339Nano, ts); err == nil {
+				msg.Timestamp = &t
+				s = rest
+			}
+		}
+	}
+
+	if msg.Timestamp != nil {
+		if host, rest := nextField(s); host != "" && !strings.HasSuffix(host, ":") {
+			msg.Host = host
+			s = rest
+		}
+	}
+
+	// TAG is alphanumeric up to 32 characters, and usually followed by "[PID]:" or ":"
+	if tag, rest := nextField(s); strings.HasSuffix(tag, ":") && len(tag) <= 64 {
+		tag = strings.TrimSuffix(tag, ":")
+		if i := strings.IndexByte(tag, '['); i > 0 && strings.HasSuffix(tag, "]") {
+			msg.ProcID = tag[i+1 : len(tag)-1]
+			tag = tag[:i]
+		}
+		msg.App = tag
+		s = rest
+	}
+
+	msg.Message = s
+}
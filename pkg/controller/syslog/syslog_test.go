@@ -0,0 +1,156 @@
+package syslog_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/hatchery/pkg/controller/syslog"
+	"github.com/m-mizutani/hatchery/pkg/domain/config"
+	"github.com/m-mizutani/hatchery/pkg/infra"
+	"github.com/m-mizutani/hatchery/pkg/infra/cs"
+)
+
+func TestParseRFC5424(t *testing.T) {
+	now := time.Date(2024, 4, 1, 10, 0, 0, 0, time.UTC)
+	data := `<165>1 2024-04-01T09:59:58.123Z host1 app1 1234 ID47 [exampleSDID@32473 iut="3" eventSource="Application \"x\""][meta seq="1"] ` + "\ufeff" + `An application event`
+
+	msg := gt.R1(syslog.Parse([]byte(data), now)).NoError(t)
+	gt.Equal(t, msg.Format, syslog.FormatRFC5424)
+	gt.Equal(t, msg.Facility, 20)
+	gt.Equal(t, msg.Severity, "notice")
+	gt.Equal(t, *msg.Timestamp, time.Date(2024, 4, 1, 9, 59, 58, 123000000, time.UTC))
+	gt.Equal(t, msg.Host, "host1")
+	gt.Equal(t, msg.App, "app1")
+	gt.Equal(t, msg.ProcID, "1234")
+	gt.Equal(t, msg.MsgID, "ID47")
+	gt.Equal(t, msg.StructuredData, map[string]map[string]string{
+		"exampleSDID@32473": {"iut": "3", "eventSource": `Application "x"`},
+		"meta":              {"seq": "1"},
+	})
+	gt.Equal(t, msg.Message, "An application event")
+
+	t.Run("nil values", func(t *testing.T) {
+		msg := gt.R1(syslog.Parse([]byte(`<14>1 - - - - - - hello`), now)).NoError(t)
+		gt.Equal(t, msg.Timestamp, nil)
+		gt.Equal(t, msg.Host, "")
+		gt.Equal(t, len(msg.StructuredData), 0)
+		gt.Equal(t, msg.Message, "hello")
+	})
+
+	t.Run("broken structured data", func(t *testing.T) {
+		_, err := syslog.Parse([]byte(`<14>1 - - - - - [id k="v hello`), now)
+		gt.Error(t, err)
+	})
+}
+
+func TestParseRFC3164(t *testing.T) {
+	now := time.Date(2024, 4, 1, 10, 0, 0, 0, time.UTC)
+
+	msg := gt.R1(syslog.Parse([]byte("<34>Apr  1 09:59:58 mymachine su[123]: 'su root' failed for lonvick on /dev/pts/8\n"), now)).NoError(t)
+	gt.Equal(t, msg.Format, syslog.FormatRFC3164)
+	gt.Equal(t, msg.Facility, 4)
+	gt.Equal(t, msg.Severity, "crit")
+	gt.Equal(t, *msg.Timestamp, time.Date(2024, 4, 1, 9, 59, 58, 0, time.UTC))
+	gt.Equal(t, msg.Host, "mymachine")
+	gt.Equal(t, msg.App, "su")
+	gt.Equal(t, msg.ProcID, "123")
+	gt.Equal(t, msg.Message, "'su root' failed for lonvick on /dev/pts/8")
+
+	t.Run("previous year", func(t *testing.T) {
+		now := time.Date(2024, 1, 1, 0, 0, 1, 0, time.UTC)
+		msg := gt.R1(syslog.Parse([]byte("<13>Dec 31 23:59:59 host app: bye"), now)).NoError(t)
+		gt.Equal(t, *msg.Timestamp, time.Date(2023, 12, 31, 23, 59, 59, 0, time.UTC))
+	})
+
+	t.Run("without header", func(t *testing.T) {
+		msg := gt.R1(syslog.Parse([]byte("<13>just a message"), now)).NoError(t)
+		gt.Equal(t, msg.Timestamp, nil)
+		gt.Equal(t, msg.Message, "just a message")
+	})
+
+	t.Run("no PRI", func(t *testing.T) {
+		_, err := syslog.Parse([]byte("hello"), now)
+		gt.Error(t, err)
+	})
+}
+
+func readRecords(t *testing.T, mock *cs.Mock) []map[string]any {
+	var records []map[string]any
+	for _, result := range mock.Results {
+		r := gt.R1(gzip.NewReader(bytes.NewReader(result.Body.Bytes()))).NoError(t)
+		data := gt.R1(io.ReadAll(r)).NoError(t)
+		for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+			var rec map[string]any
+			gt.NoError(t, json.Unmarshal([]byte(line), &rec))
+			records = append(records, rec)
+		}
+	}
+	return records
+}
+
+func TestListener(t *testing.T) {
+	mock := cs.NewMock()
+	clients := infra.New(infra.WithCloudStorage(mock))
+	cfg := &config.SyslogImpl{
+		Id:              "syslog",
+		Bucket:          "test-bucket",
+		UdpAddr:         ptr("127.0.0.1:0"),
+		TcpAddr:         ptr("127.0.0.1:0"),
+		MaxMessageBytes: 1024,
+		MaxRecords:      100,
+		MaxBytes:        1024 * 1024,
+	}
+
+	ln := gt.R1(syslog.New(context.Background(), clients, cfg)).NoError(t)
+	addrs := ln.Addrs()
+	gt.A(t, addrs).Length(2)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- ln.Serve(ctx) }()
+
+	udp := gt.R1(net.Dial("udp", addrs[0].String())).NoError(t)
+	gt.R1(udp.Write([]byte("<14>1 - host1 app1 - - - via udp"))).NoError(t)
+	gt.NoError(t, udp.Close())
+
+	tcp := gt.R1(net.Dial("tcp", addrs[1].String())).NoError(t)
+	framed := "<14>1 - host2 app2 - - - octet\ncounting"
+	gt.R1(fmt.Fprintf(tcp, "%d %s", len(framed), framed)).NoError(t)
+	gt.R1(tcp.Write([]byte("<13>Apr  1 09:59:58 host3 app3: lf framing\n"))).NoError(t)
+	gt.NoError(t, tcp.Close())
+
+	time.Sleep(100 * time.Millisecond)
+	cancel()
+	gt.NoError(t, <-done)
+
+	records := readRecords(t, mock)
+	gt.A(t, records).Length(3)
+
+	messages := map[string]map[string]any{}
+	for _, rec := range records {
+		messages[rec["host"].(string)] = rec
+	}
+	gt.Equal(t, messages["host1"]["message"], any("via udp"))
+	gt.Equal(t, messages["host2"]["message"], any("octet\ncounting"))
+	gt.Equal(t, messages["host3"]["message"], any("lf framing"))
+	gt.Equal(t, messages["host3"]["format"], any("rfc3164"))
+	gt.Equal(t, messages["host1"]["severity"], any("info"))
+}
+
+func TestListenerWithoutAddress(t *testing.T) {
+	_, err := syslog.New(context.Background(), infra.New(), &config.SyslogImpl{Id: "syslog"})
+	gt.Error(t, err)
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
@@ -1,6 +1,8 @@
 // Code generated from Pkl module `org.github.m_mizutani.hatchery.config`. DO NOT EDIT.
 package config
 
+import "github.com/apple/pkl-go/pkl"
+
 type Action interface {
 	GetId() string
 
@@ -9,4 +11,22 @@ type Action interface {
 	GetBucket() string
 
 	GetPrefix() *string
+
+	GetDestinations() []Destination
+
+	GetDestinationPolicy() string
+
+	GetCompression() Compression
+
+	GetEncryption() Encryption
+
+	GetStorageClass() *string
+
+	GetObjectNaming() string
+
+	GetConcurrencyKey() *string
+
+	GetDependsOn() []string
+
+	GetTimeout() *pkl.Duration
 }
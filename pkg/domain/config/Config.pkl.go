@@ -9,6 +9,14 @@ import (
 
 type Config struct {
 	Actions []Action `pkl:"actions"`
+
+	Webhooks []Webhook `pkl:"webhooks"`
+
+	Syslogs []Syslog `pkl:"syslogs"`
+
+	MaxParallel *int `pkl:"max_parallel"`
+
+	ExecTimeout *pkl.Duration `pkl:"exec_timeout"`
 }
 
 // LoadFromPath loads the pkl module at the given path and evaluates it into a Config
@@ -1,37 +1,47 @@
 // Code generated from Pkl module `org.github.m_mizutani.hatchery.config`. DO NOT EDIT.
 package config
 
-type FalconDataReplicator interface {
-	Action
+import "github.com/apple/pkl-go/pkl"
 
-	GetAwsRegion() string
+type FalconDataReplicator interface {
+	AWSAction
 
-	GetAwsAccessKeyId() string
+	GetSqsUrl() string
 
-	GetAwsSecretAccessKey() string
+	GetSqsEndpoint() *string
 
-	GetSqsUrl() string
+	GetS3Endpoint() *string
 
 	GetMaxMessages() *int
 
 	GetMaxPulls() *int
+
+	GetTranscode() bool
 }
 
 var _ FalconDataReplicator = (*FalconDataReplicatorImpl)(nil)
 
 type FalconDataReplicatorImpl struct {
-	AwsRegion string `pkl:"aws_region"`
+	SqsUrl string `pkl:"sqs_url"`
 
-	AwsAccessKeyId string `pkl:"aws_access_key_id"`
+	SqsEndpoint *string `pkl:"sqs_endpoint"`
 
-	AwsSecretAccessKey string `pkl:"aws_secret_access_key"`
-
-	SqsUrl string `pkl:"sqs_url"`
+	S3Endpoint *string `pkl:"s3_endpoint"`
 
 	MaxMessages *int `pkl:"max_messages"`
 
 	MaxPulls *int `pkl:"max_pulls"`
 
+	Transcode bool `pkl:"transcode"`
+
+	AwsRegion string `pkl:"aws_region"`
+
+	AwsCredential AWSCredential `pkl:"aws_credential"`
+
+	AwsEndpoint *string `pkl:"aws_endpoint"`
+
+	AwsUsePathStyle bool `pkl:"aws_use_path_style"`
+
 	Id string `pkl:"id"`
 
 	Tags *[]string `pkl:"tags"`
@@ -39,24 +49,38 @@ type FalconDataReplicatorImpl struct {
 	Bucket string `pkl:"bucket"`
 
 	Prefix *string `pkl:"prefix"`
-}
 
-func (rcv *FalconDataReplicatorImpl) GetAwsRegion() string {
-	return rcv.AwsRegion
-}
+	Destinations []Destination `pkl:"destinations"`
 
-func (rcv *FalconDataReplicatorImpl) GetAwsAccessKeyId() string {
-	return rcv.AwsAccessKeyId
-}
+	DestinationPolicy string `pkl:"destination_policy"`
+
+	Compression Compression `pkl:"compression"`
+
+	Encryption Encryption `pkl:"encryption"`
+
+	StorageClass *string `pkl:"storage_class"`
 
-func (rcv *FalconDataReplicatorImpl) GetAwsSecretAccessKey() string {
-	return rcv.AwsSecretAccessKey
+	ObjectNaming string `pkl:"object_naming"`
+
+	ConcurrencyKey *string `pkl:"concurrency_key"`
+
+	DependsOn []string `pkl:"depends_on"`
+
+	Timeout *pkl.Duration `pkl:"timeout"`
 }
 
 func (rcv *FalconDataReplicatorImpl) GetSqsUrl() string {
 	return rcv.SqsUrl
 }
 
+func (rcv *FalconDataReplicatorImpl) GetSqsEndpoint() *string {
+	return rcv.SqsEndpoint
+}
+
+func (rcv *FalconDataReplicatorImpl) GetS3Endpoint() *string {
+	return rcv.S3Endpoint
+}
+
 func (rcv *FalconDataReplicatorImpl) GetMaxMessages() *int {
 	return rcv.MaxMessages
 }
@@ -65,6 +89,26 @@ func (rcv *FalconDataReplicatorImpl) GetMaxPulls() *int {
 	return rcv.MaxPulls
 }
 
+func (rcv *FalconDataReplicatorImpl) GetTranscode() bool {
+	return rcv.Transcode
+}
+
+func (rcv *FalconDataReplicatorImpl) GetAwsRegion() string {
+	return rcv.AwsRegion
+}
+
+func (rcv *FalconDataReplicatorImpl) GetAwsCredential() AWSCredential {
+	return rcv.AwsCredential
+}
+
+func (rcv *FalconDataReplicatorImpl) GetAwsEndpoint() *string {
+	return rcv.AwsEndpoint
+}
+
+func (rcv *FalconDataReplicatorImpl) GetAwsUsePathStyle() bool {
+	return rcv.AwsUsePathStyle
+}
+
 func (rcv *FalconDataReplicatorImpl) GetId() string {
 	return rcv.Id
 }
@@ -80,3 +124,39 @@ func (rcv *FalconDataReplicatorImpl) GetBucket() string {
 func (rcv *FalconDataReplicatorImpl) GetPrefix() *string {
 	return rcv.Prefix
 }
+
+func (rcv *FalconDataReplicatorImpl) GetDestinations() []Destination {
+	return rcv.Destinations
+}
+
+func (rcv *FalconDataReplicatorImpl) GetDestinationPolicy() string {
+	return rcv.DestinationPolicy
+}
+
+func (rcv *FalconDataReplicatorImpl) GetCompression() Compression {
+	return rcv.Compression
+}
+
+func (rcv *FalconDataReplicatorImpl) GetEncryption() Encryption {
+	return rcv.Encryption
+}
+
+func (rcv *FalconDataReplicatorImpl) GetStorageClass() *string {
+	return rcv.StorageClass
+}
+
+func (rcv *FalconDataReplicatorImpl) GetObjectNaming() string {
+	return rcv.ObjectNaming
+}
+
+func (rcv *FalconDataReplicatorImpl) GetConcurrencyKey() *string {
+	return rcv.ConcurrencyKey
+}
+
+func (rcv *FalconDataReplicatorImpl) GetDependsOn() []string {
+	return rcv.DependsOn
+}
+
+func (rcv *FalconDataReplicatorImpl) GetTimeout() *pkl.Duration {
+	return rcv.Timeout
+}
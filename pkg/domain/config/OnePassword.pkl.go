@@ -13,6 +13,12 @@ type OnePassword interface {
 	GetLimit() int
 
 	GetMaxPages() *int
+
+	GetEvents() []string
+
+	GetBaseUrl() string
+
+	GetDedup() *Dedup
 }
 
 var _ OnePassword = (*OnePasswordImpl)(nil)
@@ -26,6 +32,12 @@ type OnePasswordImpl struct {
 
 	MaxPages *int `pkl:"max_pages"`
 
+	Events []string `pkl:"events"`
+
+	BaseUrl string `pkl:"base_url"`
+
+	Dedup *Dedup `pkl:"dedup"`
+
 	Id string `pkl:"id"`
 
 	Tags *[]string `pkl:"tags"`
@@ -33,6 +45,24 @@ type OnePasswordImpl struct {
 	Bucket string `pkl:"bucket"`
 
 	Prefix *string `pkl:"prefix"`
+
+	Destinations []Destination `pkl:"destinations"`
+
+	DestinationPolicy string `pkl:"destination_policy"`
+
+	Compression Compression `pkl:"compression"`
+
+	Encryption Encryption `pkl:"encryption"`
+
+	StorageClass *string `pkl:"storage_class"`
+
+	ObjectNaming string `pkl:"object_naming"`
+
+	ConcurrencyKey *string `pkl:"concurrency_key"`
+
+	DependsOn []string `pkl:"depends_on"`
+
+	Timeout *pkl.Duration `pkl:"timeout"`
 }
 
 func (rcv *OnePasswordImpl) GetApiToken() string {
@@ -51,6 +81,18 @@ func (rcv *OnePasswordImpl) GetMaxPages() *int {
 	return rcv.MaxPages
 }
 
+func (rcv *OnePasswordImpl) GetEvents() []string {
+	return rcv.Events
+}
+
+func (rcv *OnePasswordImpl) GetBaseUrl() string {
+	return rcv.BaseUrl
+}
+
+func (rcv *OnePasswordImpl) GetDedup() *Dedup {
+	return rcv.Dedup
+}
+
 func (rcv *OnePasswordImpl) GetId() string {
 	return rcv.Id
 }
@@ -66,3 +108,39 @@ func (rcv *OnePasswordImpl) GetBucket() string {
 func (rcv *OnePasswordImpl) GetPrefix() *string {
 	return rcv.Prefix
 }
+
+func (rcv *OnePasswordImpl) GetDestinations() []Destination {
+	return rcv.Destinations
+}
+
+func (rcv *OnePasswordImpl) GetDestinationPolicy() string {
+	return rcv.DestinationPolicy
+}
+
+func (rcv *OnePasswordImpl) GetCompression() Compression {
+	return rcv.Compression
+}
+
+func (rcv *OnePasswordImpl) GetEncryption() Encryption {
+	return rcv.Encryption
+}
+
+func (rcv *OnePasswordImpl) GetStorageClass() *string {
+	return rcv.StorageClass
+}
+
+func (rcv *OnePasswordImpl) GetObjectNaming() string {
+	return rcv.ObjectNaming
+}
+
+func (rcv *OnePasswordImpl) GetConcurrencyKey() *string {
+	return rcv.ConcurrencyKey
+}
+
+func (rcv *OnePasswordImpl) GetDependsOn() []string {
+	return rcv.DependsOn
+}
+
+func (rcv *OnePasswordImpl) GetTimeout() *pkl.Duration {
+	return rcv.Timeout
+}
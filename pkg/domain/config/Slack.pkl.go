@@ -13,6 +13,16 @@ type Slack interface {
 	GetLimit() int
 
 	GetMaxPages() *int
+
+	GetAction() *[]string
+
+	GetActor() *string
+
+	GetEntity() *string
+
+	GetReferencePrefix() *string
+
+	GetDedup() *Dedup
 }
 
 var _ Slack = (*SlackImpl)(nil)
@@ -26,6 +36,16 @@ type SlackImpl struct {
 
 	MaxPages *int `pkl:"max_pages"`
 
+	Action *[]string `pkl:"action"`
+
+	Actor *string `pkl:"actor"`
+
+	Entity *string `pkl:"entity"`
+
+	ReferencePrefix *string `pkl:"reference_prefix"`
+
+	Dedup *Dedup `pkl:"dedup"`
+
 	Id string `pkl:"id"`
 
 	Tags *[]string `pkl:"tags"`
@@ -33,6 +53,24 @@ type SlackImpl struct {
 	Bucket string `pkl:"bucket"`
 
 	Prefix *string `pkl:"prefix"`
+
+	Destinations []Destination `pkl:"destinations"`
+
+	DestinationPolicy string `pkl:"destination_policy"`
+
+	Compression Compression `pkl:"compression"`
+
+	Encryption Encryption `pkl:"encryption"`
+
+	StorageClass *string `pkl:"storage_class"`
+
+	ObjectNaming string `pkl:"object_naming"`
+
+	ConcurrencyKey *string `pkl:"concurrency_key"`
+
+	DependsOn []string `pkl:"depends_on"`
+
+	Timeout *pkl.Duration `pkl:"timeout"`
 }
 
 func (rcv *SlackImpl) GetAccessToken() string {
@@ -51,6 +89,26 @@ func (rcv *SlackImpl) GetMaxPages() *int {
 	return rcv.MaxPages
 }
 
+func (rcv *SlackImpl) GetAction() *[]string {
+	return rcv.Action
+}
+
+func (rcv *SlackImpl) GetActor() *string {
+	return rcv.Actor
+}
+
+func (rcv *SlackImpl) GetEntity() *string {
+	return rcv.Entity
+}
+
+func (rcv *SlackImpl) GetReferencePrefix() *string {
+	return rcv.ReferencePrefix
+}
+
+func (rcv *SlackImpl) GetDedup() *Dedup {
+	return rcv.Dedup
+}
+
 func (rcv *SlackImpl) GetId() string {
 	return rcv.Id
 }
@@ -66,3 +124,39 @@ func (rcv *SlackImpl) GetBucket() string {
 func (rcv *SlackImpl) GetPrefix() *string {
 	return rcv.Prefix
 }
+
+func (rcv *SlackImpl) GetDestinations() []Destination {
+	return rcv.Destinations
+}
+
+func (rcv *SlackImpl) GetDestinationPolicy() string {
+	return rcv.DestinationPolicy
+}
+
+func (rcv *SlackImpl) GetCompression() Compression {
+	return rcv.Compression
+}
+
+func (rcv *SlackImpl) GetEncryption() Encryption {
+	return rcv.Encryption
+}
+
+func (rcv *SlackImpl) GetStorageClass() *string {
+	return rcv.StorageClass
+}
+
+func (rcv *SlackImpl) GetObjectNaming() string {
+	return rcv.ObjectNaming
+}
+
+func (rcv *SlackImpl) GetConcurrencyKey() *string {
+	return rcv.ConcurrencyKey
+}
+
+func (rcv *SlackImpl) GetDependsOn() []string {
+	return rcv.DependsOn
+}
+
+func (rcv *SlackImpl) GetTimeout() *pkl.Duration {
+	return rcv.Timeout
+}
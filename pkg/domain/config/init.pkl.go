@@ -6,6 +6,46 @@ import "github.com/apple/pkl-go/pkl"
 func init() {
 	pkl.RegisterMapping("org.github.m_mizutani.hatchery.config", Config{})
 	pkl.RegisterMapping("org.github.m_mizutani.hatchery.config#OnePassword", OnePasswordImpl{})
+	pkl.RegisterMapping("org.github.m_mizutani.hatchery.config#AWSStaticCredential", AWSStaticCredentialImpl{})
+	pkl.RegisterMapping("org.github.m_mizutani.hatchery.config#AWSDefaultCredential", AWSDefaultCredentialImpl{})
+	pkl.RegisterMapping("org.github.m_mizutani.hatchery.config#AWSAssumeRoleCredential", AWSAssumeRoleCredentialImpl{})
+	pkl.RegisterMapping("org.github.m_mizutani.hatchery.config#AWSWebIdentityCredential", AWSWebIdentityCredentialImpl{})
 	pkl.RegisterMapping("org.github.m_mizutani.hatchery.config#FalconDataReplicator", FalconDataReplicatorImpl{})
 	pkl.RegisterMapping("org.github.m_mizutani.hatchery.config#Slack", SlackImpl{})
+	pkl.RegisterMapping("org.github.m_mizutani.hatchery.config#SlackWorkspace", SlackWorkspaceImpl{})
+	pkl.RegisterMapping("org.github.m_mizutani.hatchery.config#Office365", Office365Impl{})
+	pkl.RegisterMapping("org.github.m_mizutani.hatchery.config#EntraID", EntraIDImpl{})
+	pkl.RegisterMapping("org.github.m_mizutani.hatchery.config#AtlassianAudit", AtlassianAuditImpl{})
+	pkl.RegisterMapping("org.github.m_mizutani.hatchery.config#Zoom", ZoomImpl{})
+	pkl.RegisterMapping("org.github.m_mizutani.hatchery.config#Box", BoxImpl{})
+	pkl.RegisterMapping("org.github.m_mizutani.hatchery.config#Dropbox", DropboxImpl{})
+	pkl.RegisterMapping("org.github.m_mizutani.hatchery.config#SalesforceEventLog", SalesforceEventLogImpl{})
+	pkl.RegisterMapping("org.github.m_mizutani.hatchery.config#Cloudflare", CloudflareImpl{})
+	pkl.RegisterMapping("org.github.m_mizutani.hatchery.config#Duo", DuoImpl{})
+	pkl.RegisterMapping("org.github.m_mizutani.hatchery.config#Tailscale", TailscaleImpl{})
+	pkl.RegisterMapping("org.github.m_mizutani.hatchery.config#JamfPro", JamfProImpl{})
+	pkl.RegisterMapping("org.github.m_mizutani.hatchery.config#Kandji", KandjiImpl{})
+	pkl.RegisterMapping("org.github.m_mizutani.hatchery.config#SnowflakeAccountUsage", SnowflakeAccountUsageImpl{})
+	pkl.RegisterMapping("org.github.m_mizutani.hatchery.config#GCPAuditLogs", GCPAuditLogsImpl{})
+	pkl.RegisterMapping("org.github.m_mizutani.hatchery.config#GitHubSignature", GitHubSignatureImpl{})
+	pkl.RegisterMapping("org.github.m_mizutani.hatchery.config#SlackSignature", SlackSignatureImpl{})
+	pkl.RegisterMapping("org.github.m_mizutani.hatchery.config#SharedSecret", SharedSecretImpl{})
+	pkl.RegisterMapping("org.github.m_mizutani.hatchery.config#OktaEventHook", OktaEventHookImpl{})
+	pkl.RegisterMapping("org.github.m_mizutani.hatchery.config#Webhook", WebhookImpl{})
+	pkl.RegisterMapping("org.github.m_mizutani.hatchery.config#Syslog", SyslogImpl{})
+	pkl.RegisterMapping("org.github.m_mizutani.hatchery.config#Spool", Spool{})
+	pkl.RegisterMapping("org.github.m_mizutani.hatchery.config#SyslogTLS", SyslogTLS{})
+	pkl.RegisterMapping("org.github.m_mizutani.hatchery.config#KafkaTopic", KafkaTopicImpl{})
+	pkl.RegisterMapping("org.github.m_mizutani.hatchery.config#PubSubSubscription", PubSubSubscriptionImpl{})
+	pkl.RegisterMapping("org.github.m_mizutani.hatchery.config#KafkaSASL", KafkaSASL{})
+	pkl.RegisterMapping("org.github.m_mizutani.hatchery.config#GCSDestination", GCSDestinationImpl{})
+	pkl.RegisterMapping("org.github.m_mizutani.hatchery.config#S3Destination", S3DestinationImpl{})
+	pkl.RegisterMapping("org.github.m_mizutani.hatchery.config#Gzip", GzipImpl{})
+	pkl.RegisterMapping("org.github.m_mizutani.hatchery.config#Zstd", ZstdImpl{})
+	pkl.RegisterMapping("org.github.m_mizutani.hatchery.config#Snappy", SnappyImpl{})
+	pkl.RegisterMapping("org.github.m_mizutani.hatchery.config#NoCompression", NoCompressionImpl{})
+	pkl.RegisterMapping("org.github.m_mizutani.hatchery.config#GCPKMSEncryption", GCPKMSEncryptionImpl{})
+	pkl.RegisterMapping("org.github.m_mizutani.hatchery.config#AWSKMSEncryption", AWSKMSEncryptionImpl{})
+	pkl.RegisterMapping("org.github.m_mizutani.hatchery.config#AgeEncryption", AgeEncryptionImpl{})
+	pkl.RegisterMapping("org.github.m_mizutani.hatchery.config#Dedup", Dedup{})
 }
@@ -3,28 +3,39 @@ package interfaces
 import (
 	"context"
 
-	"github.com/aws/aws-sdk-go/aws/request"
-	"github.com/aws/aws-sdk-go/aws/session"
-	"github.com/aws/aws-sdk-go/service/s3"
-	"github.com/aws/aws-sdk-go/service/sqs"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/kms"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
 )
 
-type NewSQS func(*session.Session) SQS
-type NewS3 func(*session.Session) S3
+type NewSQS func(cfg aws.Config, optFns ...func(*sqs.Options)) SQS
+type NewS3 func(cfg aws.Config, optFns ...func(*s3.Options)) S3
+type NewKMS func(cfg aws.Config, optFns ...func(*kms.Options)) KMS
 
-func DefaultNewSQS(s *session.Session) SQS {
-	return sqs.New(s)
+func DefaultNewSQS(cfg aws.Config, optFns ...func(*sqs.Options)) SQS {
+	return sqs.NewFromConfig(cfg, optFns...)
 }
 
-func DefaultNewS3(s *session.Session) S3 {
-	return s3.New(s)
+func DefaultNewS3(cfg aws.Config, optFns ...func(*s3.Options)) S3 {
+	return s3.NewFromConfig(cfg, optFns...)
+}
+
+func DefaultNewKMS(cfg aws.Config, optFns ...func(*kms.Options)) KMS {
+	return kms.NewFromConfig(cfg, optFns...)
 }
 
 type SQS interface {
-	ReceiveMessageWithContext(ctx context.Context, input *sqs.ReceiveMessageInput, opts ...request.Option) (*sqs.ReceiveMessageOutput, error)
-	DeleteMessageWithContext(ctx context.Context, input *sqs.DeleteMessageInput, opts ...request.Option) (*sqs.DeleteMessageOutput, error)
+	ReceiveMessage(ctx context.Context, input *sqs.ReceiveMessageInput, optFns ...func(*sqs.Options)) (*sqs.ReceiveMessageOutput, error)
+	DeleteMessage(ctx context.Context, input *sqs.DeleteMessageInput, optFns ...func(*sqs.Options)) (*sqs.DeleteMessageOutput, error)
 }
 
 type S3 interface {
-	GetObjectWithContext(ctx context.Context, input *s3.GetObjectInput, opts ...request.Option) (*s3.GetObjectOutput, error)
+	GetObject(ctx context.Context, input *s3.GetObjectInput, optFns ...func(*s3.Options)) (*s3.GetObjectOutput, error)
+	PutObject(ctx context.Context, input *s3.PutObjectInput, optFns ...func(*s3.Options)) (*s3.PutObjectOutput, error)
+}
+
+type KMS interface {
+	Encrypt(ctx context.Context, input *kms.EncryptInput, optFns ...func(*kms.Options)) (*kms.EncryptOutput, error)
+	Decrypt(ctx context.Context, input *kms.DecryptInput, optFns ...func(*kms.Options)) (*kms.DecryptOutput, error)
 }
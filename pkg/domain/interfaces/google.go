@@ -0,0 +1,23 @@
+package interfaces
+
+import (
+	"context"
+
+	"cloud.google.com/go/pubsub"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/google"
+)
+
+type NewGoogleTokenSource func(ctx context.Context, scopes ...string) (oauth2.TokenSource, error)
+
+// DefaultNewGoogleTokenSource uses Application Default Credentials.
+func DefaultNewGoogleTokenSource(ctx context.Context, scopes ...string) (oauth2.TokenSource, error) {
+	return google.DefaultTokenSource(ctx, scopes...)
+}
+
+type NewPubSub func(ctx context.Context, projectID string) (*pubsub.Client, error)
+
+// DefaultNewPubSub uses Application Default Credentials. The emulator is used if PUBSUB_EMULATOR_HOST is set.
+func DefaultNewPubSub(ctx context.Context, projectID string) (*pubsub.Client, error) {
+	return pubsub.NewClient(ctx, projectID)
+}
@@ -9,10 +9,20 @@ import (
 )
 
 type CloudStorage interface {
-	NewObjectWriter(ctx context.Context, bucket types.CSBucket, object types.CSObjectName) io.WriteCloser
+	NewObjectWriter(ctx context.Context, bucket types.CSBucket, object types.CSObjectName, opts ...types.WriteOption) io.WriteCloser
 	NewObjectReader(ctx context.Context, bucket types.CSBucket, object types.CSObjectName) (io.ReadCloser, error)
 }
 
+// ObjectAborter is implemented by object writers of CloudStorage that can discard the object without creating it. An object writer must not be closed on failure, because Close creates the object with incomplete data.
+type ObjectAborter interface {
+	Abort()
+}
+
 type HTTPClient interface {
 	Do(req *http.Request) (*http.Response, error)
 }
+
+// RequestSigner signs HTTP request in place (e.g. setting Authorization and Date headers) for vendors that require request signing instead of bearer token.
+type RequestSigner interface {
+	Sign(req *http.Request) error
+}
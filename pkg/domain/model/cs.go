@@ -2,7 +2,10 @@ package model
 
 import (
 	"context"
+	"encoding/hex"
 	"fmt"
+	"path"
+	"strings"
 	"time"
 
 	"github.com/m-mizutani/hatchery/pkg/domain/config"
@@ -10,6 +13,13 @@ import (
 	"github.com/m-mizutani/hatchery/pkg/utils"
 )
 
+const (
+	// ObjectNamingRequest names log objects by time and request ID of the execution.
+	ObjectNamingRequest = "request"
+	// ObjectNamingContent names log objects by digest of the content under the time based path of the execution. Only retries writing the same content in the same hour get the same name.
+	ObjectNamingContent = "content"
+)
+
 func LogObjNamePrefix(action config.Action, now time.Time) types.CSObjectName {
 	objPrefix := now.Format("logs/2006/01/02/15/")
 	if prefix := action.GetPrefix(); prefix != nil {
@@ -20,10 +30,59 @@ func LogObjNamePrefix(action config.Action, now time.Time) types.CSObjectName {
 }
 
 func DefaultLogObjectName(ctx context.Context, action config.Action, now time.Time, seq int) types.CSObjectName {
+	return LogObjNamePrefix(action, now) + logObjectBaseName(ctx, action, now, seq)
+}
+
+// DatasetLogObjectName returns object name for an action that collects multiple datasets. The dataset name is inserted as a sub-prefix between the prefix of action and the time based path.
+func DatasetLogObjectName(ctx context.Context, action config.Action, dataset string, now time.Time, seq int) types.CSObjectName {
+	objPrefix := dataset + "/" + now.Format("logs/2006/01/02/15/")
+	if prefix := action.GetPrefix(); prefix != nil {
+		objPrefix = *prefix + objPrefix
+	}
+
+	return types.CSObjectName(objPrefix) + logObjectBaseName(ctx, action, now, seq)
+}
+
+func logObjectBaseName(ctx context.Context, action config.Action, now time.Time, seq int) types.CSObjectName {
 	reqID, _ := utils.CtxRequestID(ctx)
-	objName := types.CSObjectName(
-		fmt.Sprintf("%s-%s-%08d.json.gz", now.Format("20060102T150304"), reqID, seq),
+	return types.CSObjectName(
+		fmt.Sprintf("%s-%s-%08d.json%s", now.Format("20060102T150304"), reqID, seq, ObjectExt(action)),
 	)
+}
+
+// ContentObjectName replaces base name of the object (before extensions) with hex encoded digest of the content. Path and extensions are kept, e.g. "logs/2024/01/02/15/20240102T150405-<request ID>-00000000.json.gz" becomes "logs/2024/01/02/15/<digest>.json.gz".
+func ContentObjectName(object types.CSObjectName, digest []byte) types.CSObjectName {
+	dir, base := path.Split(string(object))
+	ext := ""
+	if i := strings.Index(base, "."); i >= 0 {
+		ext = base[i:]
+	}
+	return types.CSObjectName(dir + hex.EncodeToString(digest) + ext)
+}
+
+// ObjectExt returns extension of object name following the data format (e.g. ".json") by compression and encryption of the action.
+func ObjectExt(action config.Action) string {
+	return CompressionExt(action.GetCompression()) + EncryptionExt(action.GetEncryption())
+}
 
-	return LogObjNamePrefix(action, now) + objName
+// CompressionExt returns extension of object name for the compression. nil is regarded as Gzip, the default of Action.
+func CompressionExt(compression config.Compression) string {
+	switch compression.(type) {
+	case *config.ZstdImpl:
+		return ".zst"
+	case *config.SnappyImpl:
+		return ".sz"
+	case *config.NoCompressionImpl:
+		return ""
+	default:
+		return ".gz"
+	}
+}
+
+// EncryptionExt returns extension of object name for the encryption. Unencrypted objects have no extension.
+func EncryptionExt(encryption config.Encryption) string {
+	if encryption == nil {
+		return ""
+	}
+	return ".enc"
 }
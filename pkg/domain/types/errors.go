@@ -5,6 +5,13 @@ import "errors"
 var (
 	ErrInvalidOption = errors.New("invalid option")
 
+	// ErrObjectExists is returned when an object written with WithIfNotExist already exists.
+	ErrObjectExists = errors.New("object already exists")
+	// ErrObjectNotFound is returned by CloudStorage.NewObjectReader when the object does not exist.
+	ErrObjectNotFound = errors.New("object not found")
+
 	ErrActonFailed  = errors.New("action failed")
 	ErrAssertFailed = errors.New("assert failed")
+	// ErrActionTimeout is returned for an action canceled by its timeout or deadline of the execution.
+	ErrActionTimeout = errors.New("action timed out")
 )
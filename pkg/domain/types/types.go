@@ -16,3 +16,60 @@ type OnePasswordAPIToken string
 func (t OnePasswordAPIToken) Bearer() string {
 	return "Bearer " + string(t)
 }
+
+// ObjectAttrs is attributes of an object written into CloudStorage. Empty values are not set to the object.
+type ObjectAttrs struct {
+	ContentType     string
+	ContentEncoding string
+	Metadata        map[string]string
+	StorageClass    string
+	// IfNotExist is precondition that the object does not exist. Close of the writer returns ErrObjectExists if it exists.
+	IfNotExist bool
+}
+
+// WriteOption sets attributes of an object written by CloudStorage.NewObjectWriter.
+type WriteOption func(attrs *ObjectAttrs)
+
+func NewObjectAttrs(opts ...WriteOption) ObjectAttrs {
+	var attrs ObjectAttrs
+	for _, opt := range opts {
+		opt(&attrs)
+	}
+	return attrs
+}
+
+func WithContentType(contentType string) WriteOption {
+	return func(attrs *ObjectAttrs) {
+		attrs.ContentType = contentType
+	}
+}
+
+func WithContentEncoding(contentEncoding string) WriteOption {
+	return func(attrs *ObjectAttrs) {
+		attrs.ContentEncoding = contentEncoding
+	}
+}
+
+// WithMetadata adds a custom metadata. Keys should be lower case because S3 stores them in lower case.
+func WithMetadata(key, value string) WriteOption {
+	return func(attrs *ObjectAttrs) {
+		if attrs.Metadata == nil {
+			attrs.Metadata = map[string]string{}
+		}
+		attrs.Metadata[key] = value
+	}
+}
+
+// WithStorageClass sets storage class of the object (e.g. NEARLINE for GCS, STANDARD_IA for S3). Empty means the default class of the bucket.
+func WithStorageClass(storageClass string) WriteOption {
+	return func(attrs *ObjectAttrs) {
+		attrs.StorageClass = storageClass
+	}
+}
+
+// WithIfNotExist writes the object only if it does not exist (GCS DoesNotExist, S3 If-None-Match).
+func WithIfNotExist() WriteOption {
+	return func(attrs *ObjectAttrs) {
+		attrs.IfNotExist = true
+	}
+}
@@ -0,0 +1,89 @@
+package awscred
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/credentials/stscreds"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/m-mizutani/goerr"
+	"github.com/m-mizutani/hatchery/pkg/domain/config"
+	"github.com/m-mizutani/hatchery/pkg/domain/types"
+)
+
+const (
+	// defaultSessionName is used for AssumeRole and AssumeRoleWithWebIdentity if session_name is not specified.
+	defaultSessionName = "hatchery"
+)
+
+// LoadConfig creates AWS config for the region with the credential mode specified in config. AWSDefaultCredential uses default credential provider chain of AWS SDK.
+func LoadConfig(ctx context.Context, region string, cred config.AWSCredential) (aws.Config, error) {
+	base, err := awsconfig.LoadDefaultConfig(ctx, awsconfig.WithRegion(region))
+	if err != nil {
+		return aws.Config{}, goerr.Wrap(err, "failed to load AWS config").With("region", region)
+	}
+
+	provider, err := newProvider(base, cred)
+	if err != nil {
+		return aws.Config{}, err
+	}
+
+	cfg := base.Copy()
+	cfg.Credentials = provider
+	return cfg, nil
+}
+
+func newProvider(base aws.Config, cred config.AWSCredential) (aws.CredentialsProvider, error) {
+	switch v := cred.(type) {
+	case *config.AWSStaticCredentialImpl:
+		var token string
+		if v.SessionToken != nil {
+			token = *v.SessionToken
+		}
+		return credentials.NewStaticCredentialsProvider(v.AccessKeyId, v.SecretAccessKey, token), nil
+
+	case *config.AWSDefaultCredentialImpl:
+		return base.Credentials, nil
+
+	case *config.AWSAssumeRoleCredentialImpl:
+		src := base.Copy()
+		if v.Source != nil {
+			srcProvider, err := newProvider(base, v.Source)
+			if err != nil {
+				return nil, goerr.Wrap(err, "failed to create source credential of AssumeRole").With("role_arn", v.RoleArn)
+			}
+			src.Credentials = srcProvider
+		}
+
+		provider := stscreds.NewAssumeRoleProvider(sts.NewFromConfig(src), v.RoleArn, func(o *stscreds.AssumeRoleOptions) {
+			o.ExternalID = v.ExternalId
+			o.RoleSessionName = sessionName(v.SessionName)
+		})
+		return aws.NewCredentialsCache(provider), nil
+
+	case *config.AWSWebIdentityCredentialImpl:
+		provider := stscreds.NewWebIdentityRoleProvider(
+			sts.NewFromConfig(base),
+			v.RoleArn,
+			stscreds.IdentityTokenFile(v.TokenFile),
+			func(o *stscreds.WebIdentityRoleOptions) {
+				o.RoleSessionName = sessionName(v.SessionName)
+			},
+		)
+		return aws.NewCredentialsCache(provider), nil
+
+	default:
+		// Do not put cred itself into error values to avoid leaking secrets
+		return nil, goerr.Wrap(types.ErrInvalidOption, "unknown AWS credential type").With("type", fmt.Sprintf("%T", cred))
+	}
+}
+
+func sessionName(name *string) string {
+	if name != nil {
+		return *name
+	}
+	return defaultSessionName
+}
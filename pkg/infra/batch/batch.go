@@ -0,0 +1,355 @@
+package batch
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/m-mizutani/goerr"
+	"github.com/m-mizutani/hatchery/pkg/domain/config"
+	"github.com/m-mizutani/hatchery/pkg/domain/interfaces"
+	"github.com/m-mizutani/hatchery/pkg/domain/model"
+	"github.com/m-mizutani/hatchery/pkg/domain/types"
+	"github.com/m-mizutani/hatchery/pkg/infra/output"
+	"github.com/m-mizutani/hatchery/pkg/utils"
+)
+
+var ErrClosed = errors.New("batcher is closed")
+
+// Flusher writes buffered records to the destination. Records are not modified by Batcher after calling Flusher.
+type Flusher func(ctx context.Context, records [][]byte) error
+
+// FlushGracePeriod is time allowed to write buffered records after ctx of the action is canceled.
+const FlushGracePeriod = 30 * time.Second
+
+// FlushContext returns a context to write buffered records. It is not canceled together with ctx, so that records already consumed are written in the final flush, but it is canceled FlushGracePeriod after ctx is done so that a stalled write does not outlive timeout of the action.
+func FlushContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	flushCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	stop := context.AfterFunc(ctx, func() {
+		time.AfterFunc(FlushGracePeriod, cancel)
+	})
+	return flushCtx, func() {
+		stop()
+		cancel()
+	}
+}
+
+// Batcher buffers records and flushes them when number of records or total bytes reaches the limit, or the interval has passed since the last flush. If Flusher fails, the batch is retried and new records are not consumed until it succeeds, so that Add blocks as backpressure when the queue is full. With Spool, failed batches are stored in local disk and retried in background instead, and backpressure applies only after the spool is full.
+type Batcher struct {
+	flush         Flusher
+	maxRecords    int
+	maxBytes      int
+	interval      time.Duration
+	retryInterval time.Duration
+	queueSize     int
+	spool         *Spool
+
+	input     chan []byte
+	closed    chan struct{}
+	done      chan struct{}
+	closeOnce sync.Once
+	err       error
+
+	// mutex guards closing against Add starting, so that run can wait for adding records that have passed the check
+	mutex   sync.Mutex
+	closing bool
+	adding  sync.WaitGroup
+}
+
+type Option func(*Batcher)
+
+func WithMaxRecords(n int) Option {
+	return func(x *Batcher) {
+		x.maxRecords = n
+	}
+}
+
+func WithMaxBytes(n int) Option {
+	return func(x *Batcher) {
+		x.maxBytes = n
+	}
+}
+
+func WithInterval(d time.Duration) Option {
+	return func(x *Batcher) {
+		x.interval = d
+	}
+}
+
+func WithRetryInterval(d time.Duration) Option {
+	return func(x *Batcher) {
+		x.retryInterval = d
+	}
+}
+
+// WithQueueSize sets number of records that can be queued without blocking Add while flushing.
+func WithQueueSize(n int) Option {
+	return func(x *Batcher) {
+		x.queueSize = n
+	}
+}
+
+// WithSpool enables spooling failed batches into local disk.
+func WithSpool(spool *Spool) Option {
+	return func(x *Batcher) {
+		x.spool = spool
+	}
+}
+
+// WithStreamAction sets limits of StreamAction config. Unset (zero) limits keep default values.
+func WithStreamAction(action config.StreamAction) Option {
+	return func(x *Batcher) {
+		if action.GetMaxRecords() > 0 {
+			x.maxRecords = action.GetMaxRecords()
+		}
+		if action.GetMaxBytes() > 0 {
+			x.maxBytes = action.GetMaxBytes()
+		}
+		if action.GetFlushInterval() != nil {
+			x.interval = action.GetFlushInterval().GoDuration()
+		}
+	}
+}
+
+// New creates Batcher and starts background goroutine. ctx is passed to Flusher and it should not be canceled before Close.
+func New(ctx context.Context, flush Flusher, opts ...Option) *Batcher {
+	x := &Batcher{
+		flush:         flush,
+		maxRecords:    10000,
+		maxBytes:      16 * 1024 * 1024,
+		interval:      time.Minute,
+		retryInterval: 10 * time.Second,
+		queueSize:     1000,
+		closed:        make(chan struct{}),
+		done:          make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(x)
+	}
+	x.input = make(chan []byte, x.queueSize)
+
+	go x.run(ctx)
+	return x
+}
+
+// Add puts a record into the queue. It blocks while the queue is full. A record is flushed by Close if Add returns nil.
+func (x *Batcher) Add(ctx context.Context, record []byte) error {
+	x.mutex.Lock()
+	if x.closing {
+		x.mutex.Unlock()
+		return ErrClosed
+	}
+	x.adding.Add(1)
+	x.mutex.Unlock()
+	defer x.adding.Done()
+
+	select {
+	case x.input <- record:
+		return nil
+	case <-x.closed:
+		return ErrClosed
+	case <-ctx.Done():
+		return goerr.Wrap(ctx.Err(), "canceled while waiting queue of batcher")
+	}
+}
+
+// Close flushes remaining records and stops the background goroutine. It returns error if the last flush failed.
+func (x *Batcher) Close() error {
+	x.closeOnce.Do(func() {
+		x.mutex.Lock()
+		x.closing = true
+		x.mutex.Unlock()
+		close(x.closed)
+	})
+	<-x.done
+	return x.err
+}
+
+func (x *Batcher) run(ctx context.Context) {
+	defer close(x.done)
+
+	ticker := time.NewTicker(x.interval)
+	defer ticker.Stop()
+
+	// retry is set while spooled batches remain
+	var retry <-chan time.Time
+	drain := func() {
+		retry = nil
+		if x.drainSpool(ctx) {
+			retry = time.After(x.retryInterval)
+		}
+	}
+	if x.spool != nil && x.spool.Len() > 0 {
+		drain()
+	}
+
+	var buf [][]byte
+	var size int
+	flush := func() {
+		if len(buf) == 0 {
+			return
+		}
+		if x.spool != nil {
+			if x.flushOrSpool(ctx, buf) && retry == nil {
+				retry = time.After(x.retryInterval)
+			}
+		} else {
+			x.err = x.flushWithRetry(ctx, buf)
+		}
+		buf, size = nil, 0
+	}
+
+	add := func(record []byte) {
+		buf = append(buf, record)
+		size += len(record)
+		if len(buf) >= x.maxRecords || size >= x.maxBytes {
+			flush()
+		}
+	}
+
+	for {
+		select {
+		case record := <-x.input:
+			add(record)
+
+		case <-ticker.C:
+			flush()
+
+		case <-retry:
+			drain()
+
+		case <-x.closed:
+			// Drain records until Add in progress returns, then records that are already queued
+			added := make(chan struct{})
+			go func() {
+				x.adding.Wait()
+				close(added)
+			}()
+		wait:
+			for {
+				select {
+				case record := <-x.input:
+					add(record)
+				case <-added:
+					break wait
+				}
+			}
+			for {
+				select {
+				case record := <-x.input:
+					add(record)
+					continue
+				default:
+				}
+				break
+			}
+			flush()
+			if x.spool != nil && x.drainSpool(ctx) {
+				// Spooled batches are kept and flushed after restart
+				utils.CtxLogger(ctx).Warn("batches remain in spool", "dir", x.spool.dir, "batches", x.spool.Len())
+			}
+			return
+		}
+	}
+}
+
+// flushOrSpool tries Flusher once and stores the batch into spool on failure. If batches are already spooled, the batch is spooled to keep order. It returns true if the batch is spooled. When the spool is full, it falls back to flushWithRetry.
+func (x *Batcher) flushOrSpool(ctx context.Context, records [][]byte) bool {
+	if x.spool.Len() == 0 {
+		err := x.flush(ctx, records)
+		if err == nil {
+			return false
+		}
+		utils.HandleError(ctx, "failed to flush records", goerr.Wrap(err, "flush failed, spooling").With("records", len(records)))
+	}
+
+	if err := x.spool.Put(records); err != nil {
+		utils.HandleError(ctx, "failed to spool records", err)
+		x.err = x.flushWithRetry(ctx, records)
+		return x.spool.Len() > 0
+	}
+	return true
+}
+
+// drainSpool flushes spooled batches and returns true if any batch remains.
+func (x *Batcher) drainSpool(ctx context.Context) bool {
+	if err := x.spool.Drain(ctx, func(records [][]byte) error {
+		return x.flush(ctx, records)
+	}); err != nil {
+		utils.HandleError(ctx, "failed to flush spooled records", goerr.Wrap(err, "flush failed").With("spooled", x.spool.Len()))
+	}
+	return x.spool.Len() > 0
+}
+
+// flushWithRetry retries Flusher until it succeeds or Batcher is closed. Error is returned only if the final attempt after closing fails.
+func (x *Batcher) flushWithRetry(ctx context.Context, records [][]byte) error {
+	for {
+		err := x.flush(ctx, records)
+		if err == nil {
+			return nil
+		}
+		utils.HandleError(ctx, "failed to flush records", goerr.Wrap(err, "flush failed").With("records", len(records)))
+
+		select {
+		case <-x.closed:
+			if err := x.flush(ctx, records); err != nil {
+				return goerr.Wrap(err, "failed to flush records on close").With("records", len(records))
+			}
+			return nil
+		case <-time.After(x.retryInterval):
+		}
+	}
+}
+
+// NewObjectFlusher returns Flusher that writes records as NDJSON object of the action, compressed by its compression. Object name is model.DefaultLogObjectName with sequence number incremented for each flush.
+func NewObjectFlusher(storage interfaces.CloudStorage, action config.Action) Flusher {
+	var seq int
+	return func(ctx context.Context, records [][]byte) error {
+		// Each flush is identified by its own request ID in object name and metadata
+		_, ctx = utils.CtxRequestID(ctx)
+		now := utils.CtxNow(ctx)
+		objName := model.DefaultLogObjectName(ctx, action, now, seq)
+		seq++
+
+		w, err := output.NewLogObjectWriter(ctx, storage, action, objName, types.WithContentType(output.ContentTypeNDJSON))
+		if err != nil {
+			return err
+		}
+
+		var n int
+		for _, record := range records {
+			if _, err := w.Write(record); err != nil {
+				w.Abort()
+				return goerr.Wrap(err, "failed to write record").With("object", objName)
+			}
+			if _, err := w.Write([]byte("\n")); err != nil {
+				w.Abort()
+				return goerr.Wrap(err, "failed to write record").With("object", objName)
+			}
+			n += len(record) + 1
+		}
+
+		if err := w.Close(); err != nil {
+			return goerr.Wrap(err, "failed to close object writer").With("object", objName)
+		}
+
+		utils.CtxLogger(ctx).Info("flushed records", "id", action.GetId(), "records", len(records), "bytes", n, "object", w.Object())
+		return nil
+	}
+}
+
+// NewStream creates Batcher that writes records of StreamAction into CloudStorage with limits and spool of the config.
+func NewStream(ctx context.Context, storage interfaces.CloudStorage, action config.StreamAction) (*Batcher, error) {
+	opts := []Option{WithStreamAction(action)}
+	if cfg := action.GetSpool(); cfg != nil {
+		spool, err := NewSpool(filepath.Join(cfg.Dir, action.GetId()), int64(cfg.MaxBytes))
+		if err != nil {
+			return nil, goerr.Wrap(err, "failed to open spool").With("id", action.GetId())
+		}
+		opts = append(opts, WithSpool(spool))
+	}
+
+	return New(ctx, NewObjectFlusher(storage, action), opts...), nil
+}
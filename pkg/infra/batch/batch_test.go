@@ -0,0 +1,258 @@
+package batch_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/hatchery/pkg/domain/config"
+	"github.com/m-mizutani/hatchery/pkg/domain/model"
+	"github.com/m-mizutani/hatchery/pkg/infra/batch"
+	"github.com/m-mizutani/hatchery/pkg/infra/cs"
+	"github.com/m-mizutani/hatchery/pkg/utils"
+)
+
+type recorder struct {
+	mutex   sync.Mutex
+	batches [][]string
+	fail    int
+}
+
+func (x *recorder) flush(ctx context.Context, records [][]byte) error {
+	x.mutex.Lock()
+	defer x.mutex.Unlock()
+	if x.fail > 0 {
+		x.fail--
+		return errors.New("destination unavailable")
+	}
+
+	var batch []string
+	for _, r := range records {
+		batch = append(batch, string(r))
+	}
+	x.batches = append(x.batches, batch)
+	return nil
+}
+
+func (x *recorder) get() [][]string {
+	x.mutex.Lock()
+	defer x.mutex.Unlock()
+	return append([][]string{}, x.batches...)
+}
+
+func TestBatcherLimits(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("max records", func(t *testing.T) {
+		var rec recorder
+		b := batch.New(ctx, rec.flush, batch.WithMaxRecords(2), batch.WithInterval(time.Hour))
+		for _, r := range []string{"a", "b", "c"} {
+			gt.NoError(t, b.Add(ctx, []byte(r)))
+		}
+		gt.NoError(t, b.Close())
+		gt.A(t, rec.get()).Equal([][]string{{"a", "b"}, {"c"}})
+	})
+
+	t.Run("max bytes", func(t *testing.T) {
+		var rec recorder
+		b := batch.New(ctx, rec.flush, batch.WithMaxBytes(4), batch.WithInterval(time.Hour))
+		for _, r := range []string{"aaa", "bb", "c"} {
+			gt.NoError(t, b.Add(ctx, []byte(r)))
+		}
+		gt.NoError(t, b.Close())
+		gt.A(t, rec.get()).Equal([][]string{{"aaa", "bb"}, {"c"}})
+	})
+
+	t.Run("interval", func(t *testing.T) {
+		var rec recorder
+		b := batch.New(ctx, rec.flush, batch.WithInterval(10*time.Millisecond))
+		gt.NoError(t, b.Add(ctx, []byte("a")))
+		time.Sleep(100 * time.Millisecond)
+		gt.A(t, rec.get()).Equal([][]string{{"a"}})
+		gt.NoError(t, b.Close())
+	})
+
+	t.Run("closed", func(t *testing.T) {
+		var rec recorder
+		b := batch.New(ctx, rec.flush)
+		gt.NoError(t, b.Close())
+		gt.Error(t, b.Add(ctx, []byte("a"))).Is(batch.ErrClosed)
+	})
+}
+
+func TestBatcherAddWhileClosing(t *testing.T) {
+	ctx := context.Background()
+
+	for i := 0; i < 20; i++ {
+		var rec recorder
+		b := batch.New(ctx, rec.flush, batch.WithQueueSize(1), batch.WithInterval(time.Hour))
+
+		var mutex sync.Mutex
+		var added int
+		var wg sync.WaitGroup
+		for j := 0; j < 10; j++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if err := b.Add(ctx, []byte("a")); err == nil {
+					mutex.Lock()
+					added++
+					mutex.Unlock()
+				}
+			}()
+		}
+		gt.NoError(t, b.Close())
+		wg.Wait()
+
+		// Every record accepted by Add is flushed
+		var flushed int
+		for _, batch := range rec.get() {
+			flushed += len(batch)
+		}
+		gt.Equal(t, flushed, added)
+	}
+}
+
+func TestBatcherRetry(t *testing.T) {
+	ctx := context.Background()
+	rec := recorder{fail: 2}
+	b := batch.New(ctx, rec.flush,
+		batch.WithMaxRecords(1),
+		batch.WithInterval(time.Hour),
+		batch.WithRetryInterval(10*time.Millisecond),
+	)
+	gt.NoError(t, b.Add(ctx, []byte("a")))
+	time.Sleep(100 * time.Millisecond)
+	gt.A(t, rec.get()).Equal([][]string{{"a"}})
+	gt.NoError(t, b.Close())
+}
+
+func TestBatcherBackpressure(t *testing.T) {
+	ctx := context.Background()
+	rec := recorder{fail: 1000}
+	b := batch.New(ctx, rec.flush,
+		batch.WithMaxRecords(1),
+		batch.WithQueueSize(1),
+		batch.WithRetryInterval(time.Hour),
+	)
+
+	// First record is being flushed and second one is queued
+	gt.NoError(t, b.Add(ctx, []byte("a")))
+	gt.NoError(t, b.Add(ctx, []byte("b")))
+
+	timeoutCtx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancel()
+	gt.Error(t, b.Add(timeoutCtx, []byte("c"))).Is(context.DeadlineExceeded)
+
+	// Final attempt on close also fails
+	gt.Error(t, b.Close())
+}
+
+func TestBatcherSpool(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	t.Run("spool while destination is unavailable", func(t *testing.T) {
+		spool := gt.R1(batch.NewSpool(dir, 1024)).NoError(t)
+		rec := recorder{fail: 3}
+		b := batch.New(ctx, rec.flush,
+			batch.WithMaxRecords(1),
+			batch.WithQueueSize(1),
+			batch.WithInterval(time.Hour),
+			batch.WithRetryInterval(10*time.Millisecond),
+			batch.WithSpool(spool),
+		)
+
+		// Add is not blocked by failure of flush
+		for _, r := range []string{"a", "b", "c"} {
+			gt.NoError(t, b.Add(ctx, []byte(r)))
+		}
+		time.Sleep(200 * time.Millisecond)
+		gt.NoError(t, b.Close())
+
+		gt.A(t, rec.get()).Equal([][]string{{"a"}, {"b"}, {"c"}})
+		gt.Equal(t, spool.Len(), 0)
+	})
+
+	t.Run("spooled batches are loaded again", func(t *testing.T) {
+		spool := gt.R1(batch.NewSpool(dir, 1024)).NoError(t)
+		gt.NoError(t, spool.Put([][]byte{[]byte("a"), []byte("b\nc")}))
+		gt.NoError(t, spool.Put([][]byte{[]byte("d")}))
+
+		var rec recorder
+		b := batch.New(ctx, rec.flush, batch.WithSpool(gt.R1(batch.NewSpool(dir, 1024)).NoError(t)))
+		gt.NoError(t, b.Close())
+		gt.A(t, rec.get()).Equal([][]string{{"a", "b\nc"}, {"d"}})
+	})
+
+	t.Run("broken and temporary files", func(t *testing.T) {
+		dir := t.TempDir()
+		spool := gt.R1(batch.NewSpool(dir, 1024)).NoError(t)
+		gt.NoError(t, spool.Put([][]byte{[]byte("a")}))
+		gt.NoError(t, spool.Put([][]byte{[]byte("bcd")}))
+		gt.NoError(t, spool.Put([][]byte{[]byte("e")}))
+		gt.NoError(t, os.WriteFile(filepath.Join(dir, "99999999999999999999-00000000.spool.tmp"), []byte("x"), 0600))
+
+		// Truncate the second batch
+		files := gt.R1(filepath.Glob(filepath.Join(dir, "*.spool"))).NoError(t)
+		gt.A(t, files).Length(3)
+		gt.NoError(t, os.Truncate(files[1], 2))
+
+		var rec recorder
+		b := batch.New(ctx, rec.flush, batch.WithSpool(gt.R1(batch.NewSpool(dir, 1024)).NoError(t)))
+		gt.NoError(t, b.Close())
+		gt.A(t, rec.get()).Equal([][]string{{"a"}, {"e"}})
+
+		gt.A(t, gt.R1(filepath.Glob(filepath.Join(dir, "*.spool.tmp"))).NoError(t)).Length(0)
+		gt.A(t, gt.R1(filepath.Glob(filepath.Join(dir, "*.spool.corrupt"))).NoError(t)).Length(1)
+	})
+
+	t.Run("spool is full", func(t *testing.T) {
+		spool := gt.R1(batch.NewSpool(t.TempDir(), 8)).NoError(t)
+		gt.NoError(t, spool.Put([][]byte{[]byte("abcd")}))
+		gt.Error(t, spool.Put([][]byte{[]byte("efgh")})).Is(batch.ErrSpoolFull)
+	})
+}
+
+func TestObjectFlusher(t *testing.T) {
+	ctx := context.Background()
+	_, ctx = utils.CtxRequestID(ctx)
+	now := time.Date(2024, 4, 1, 10, 0, 0, 0, time.UTC)
+	ctx = utils.CtxWithNow(ctx, func() time.Time { return now })
+
+	mock := cs.NewMock()
+	action := &config.WebhookImpl{Id: "test", Bucket: "test-bucket"}
+	flush := batch.NewObjectFlusher(mock, action)
+
+	gt.NoError(t, flush(ctx, [][]byte{[]byte(`{"a":1}`), []byte(`{"b":2}`)}))
+	gt.NoError(t, flush(ctx, [][]byte{[]byte(`{"c":3}`)}))
+
+	gt.A(t, mock.Results).Length(2).
+		At(0, func(t testing.TB, v *cs.MockResult) {
+			gt.Equal(t, v.Object, model.DefaultLogObjectName(ctx, action, now, 0))
+			r := gt.R1(gzip.NewReader(bytes.NewReader(v.Body.Bytes()))).NoError(t)
+			gt.Equal(t, string(gt.R1(io.ReadAll(r)).NoError(t)), "{\"a\":1}\n{\"b\":2}\n")
+			gt.Equal(t, v.Body.Closed, true)
+		}).
+		At(1, func(t testing.TB, v *cs.MockResult) {
+			gt.Equal(t, v.Object, model.DefaultLogObjectName(ctx, action, now, 1))
+		})
+
+	// ctx of batcher has no request ID, and one is generated for each flush
+	mock = cs.NewMock()
+	flush = batch.NewObjectFlusher(mock, action)
+	gt.NoError(t, flush(context.Background(), [][]byte{[]byte(`{"a":1}`)}))
+	gt.A(t, mock.Results).Length(1).At(0, func(t testing.TB, v *cs.MockResult) {
+		reqID := v.Attrs.Metadata["hatchery-request-id"]
+		gt.NotEqual(t, reqID, "")
+		gt.S(t, string(v.Object)).Contains("-" + reqID + "-")
+	})
+}
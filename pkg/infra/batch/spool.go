@@ -0,0 +1,183 @@
+package batch
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/m-mizutani/goerr"
+	"github.com/m-mizutani/hatchery/pkg/utils"
+)
+
+var ErrSpoolFull = errors.New("spool is full")
+
+const (
+	spoolFileExt    = ".spool"
+	spoolTmpExt     = ".tmp"
+	spoolCorruptExt = ".corrupt"
+)
+
+// Spool keeps batches in local files while the destination is unavailable. Batches are stored one per file as length prefixed records, and they are read in the stored order. Files that remain after restart are loaded again.
+type Spool struct {
+	dir      string
+	maxBytes int64
+
+	mutex sync.Mutex
+	files []spoolFile
+	size  int64
+	seq   int
+}
+
+type spoolFile struct {
+	name string
+	size int64
+}
+
+// NewSpool creates Spool on dir. The directory is created if it does not exist, and existing spool files in it are loaded. Temporary files left by crash while writing are removed.
+func NewSpool(dir string, maxBytes int64) (*Spool, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, goerr.Wrap(err, "failed to create spool directory").With("dir", dir)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to read spool directory").With("dir", dir)
+	}
+
+	x := &Spool{dir: dir, maxBytes: maxBytes}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		if strings.HasSuffix(entry.Name(), spoolFileExt+spoolTmpExt) {
+			path := filepath.Join(dir, entry.Name())
+			if err := os.Remove(path); err != nil {
+				return nil, goerr.Wrap(err, "failed to remove temporary spool file").With("file", path)
+			}
+			continue
+		}
+		if !strings.HasSuffix(entry.Name(), spoolFileExt) {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return nil, goerr.Wrap(err, "failed to get spool file info").With("file", entry.Name())
+		}
+		x.files = append(x.files, spoolFile{name: entry.Name(), size: info.Size()})
+		x.size += info.Size()
+	}
+	// File name starts with zero padded timestamp
+	sort.Slice(x.files, func(i, j int) bool { return x.files[i].name < x.files[j].name })
+
+	return x, nil
+}
+
+// Len returns number of spooled batches.
+func (x *Spool) Len() int {
+	x.mutex.Lock()
+	defer x.mutex.Unlock()
+	return len(x.files)
+}
+
+// Put stores a batch into a new file. ErrSpoolFull is returned if the total size exceeds the limit.
+func (x *Spool) Put(records [][]byte) error {
+	var buf bytes.Buffer
+	var lenBuf [binary.MaxVarintLen64]byte
+	for _, record := range records {
+		n := binary.PutUvarint(lenBuf[:], uint64(len(record)))
+		buf.Write(lenBuf[:n])
+		buf.Write(record)
+	}
+
+	x.mutex.Lock()
+	defer x.mutex.Unlock()
+
+	if x.size+int64(buf.Len()) > x.maxBytes {
+		return goerr.Wrap(ErrSpoolFull, "no space in spool").With("dir", x.dir).With("size", x.size)
+	}
+
+	name := fmt.Sprintf("%020d-%08d%s", time.Now().UnixNano(), x.seq, spoolFileExt)
+	x.seq++
+
+	// Write to temporary file and rename it so that incomplete file is not loaded after crash
+	tmpPath := filepath.Join(x.dir, name+spoolTmpExt)
+	if err := os.WriteFile(tmpPath, buf.Bytes(), 0600); err != nil {
+		return goerr.Wrap(err, "failed to write spool file").With("file", tmpPath)
+	}
+	if err := os.Rename(tmpPath, filepath.Join(x.dir, name)); err != nil {
+		return goerr.Wrap(err, "failed to rename spool file").With("file", tmpPath)
+	}
+
+	x.files = append(x.files, spoolFile{name: name, size: int64(buf.Len())})
+	x.size += int64(buf.Len())
+	return nil
+}
+
+// Drain passes spooled batches to fn in the stored order and removes each file after fn succeeds. It stops at the first error of fn. A file that can not be read is renamed with .corrupt extension and skipped, so that it does not block following batches forever.
+func (x *Spool) Drain(ctx context.Context, fn func(records [][]byte) error) error {
+	for {
+		x.mutex.Lock()
+		if len(x.files) == 0 {
+			x.mutex.Unlock()
+			return nil
+		}
+		file := x.files[0]
+		x.mutex.Unlock()
+
+		path := filepath.Join(x.dir, file.name)
+		records, err := readSpoolFile(path)
+		if err != nil {
+			utils.HandleError(ctx, "skip broken spool file", err)
+			if err := os.Rename(path, path+spoolCorruptExt); err != nil {
+				return goerr.Wrap(err, "failed to rename broken spool file").With("file", path)
+			}
+		} else {
+			if err := fn(records); err != nil {
+				return err
+			}
+			if err := os.Remove(path); err != nil {
+				return goerr.Wrap(err, "failed to remove spool file").With("file", path)
+			}
+		}
+
+		x.mutex.Lock()
+		x.files = x.files[1:]
+		x.size -= file.size
+		x.mutex.Unlock()
+	}
+}
+
+func readSpoolFile(path string) ([][]byte, error) {
+	f, err := os.Open(filepath.Clean(path))
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to open spool file").With("file", path)
+	}
+	defer utils.SafeClose(f)
+
+	r := bufio.NewReader(f)
+	var records [][]byte
+	for {
+		n, err := binary.ReadUvarint(r)
+		if errors.Is(err, io.EOF) {
+			return records, nil
+		} else if err != nil {
+			return nil, goerr.Wrap(err, "failed to read spool file").With("file", path)
+		}
+
+		record := make([]byte, n)
+		if _, err := io.ReadFull(r, record); err != nil {
+			return nil, goerr.Wrap(err, "broken spool file").With("file", path)
+		}
+		records = append(records, record)
+	}
+}
@@ -1,26 +1,61 @@
 package infra
 
 import (
+	"context"
+	"net"
 	"net/http"
+	"time"
 
-	"github.com/aws/aws-sdk-go/aws/session"
+	"cloud.google.com/go/pubsub"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/kms"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
 	"github.com/m-mizutani/hatchery/pkg/domain/interfaces"
+	"golang.org/x/oauth2"
 )
 
+// Timeouts of the default HTTP client. They limit connecting and waiting for response headers, so that a stalled API does not block an action forever even if timeout of the action is not set. Reading the response body is not limited because it may be a long download, and it is bounded by ctx of the request.
+const (
+	DefaultDialTimeout           = 30 * time.Second
+	DefaultTLSHandshakeTimeout   = 10 * time.Second
+	DefaultResponseHeaderTimeout = time.Minute
+)
+
+func newDefaultHTTPClient() *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = (&net.Dialer{
+		Timeout:   DefaultDialTimeout,
+		KeepAlive: 30 * time.Second,
+	}).DialContext
+	transport.TLSHandshakeTimeout = DefaultTLSHandshakeTimeout
+	transport.ResponseHeaderTimeout = DefaultResponseHeaderTimeout
+	return &http.Client{Transport: transport}
+}
+
 type Clients struct {
 	cs     interfaces.CloudStorage
+	state  interfaces.CloudStorage
 	http   interfaces.HTTPClient
 	newS3  interfaces.NewS3
 	newSQS interfaces.NewSQS
+	newKMS interfaces.NewKMS
+
+	newGoogleTokenSource interfaces.NewGoogleTokenSource
+	newPubSub            interfaces.NewPubSub
 }
 
 type Option func(*Clients)
 
 func New(opts ...Option) *Clients {
 	c := &Clients{
-		http:   http.DefaultClient,
+		http:   newDefaultHTTPClient(),
 		newS3:  interfaces.DefaultNewS3,
 		newSQS: interfaces.DefaultNewSQS,
+		newKMS: interfaces.DefaultNewKMS,
+
+		newGoogleTokenSource: interfaces.DefaultNewGoogleTokenSource,
+		newPubSub:            interfaces.DefaultNewPubSub,
 	}
 	for _, opt := range opts {
 		opt(c)
@@ -38,6 +73,20 @@ func WithCloudStorage(cs interfaces.CloudStorage) Option {
 	}
 }
 
+// StateStorage returns CloudStorage for internal state of actions (e.g. seen IDs of dedup). It is the storage without destinations and encryption of the action. CloudStorage is returned if not set.
+func (c *Clients) StateStorage() interfaces.CloudStorage {
+	if c.state != nil {
+		return c.state
+	}
+	return c.cs
+}
+
+func WithStateStorage(state interfaces.CloudStorage) Option {
+	return func(c *Clients) {
+		c.state = state
+	}
+}
+
 func (c *Clients) HTTPClient() interfaces.HTTPClient {
 	return c.http
 }
@@ -48,8 +97,8 @@ func WithHTTPClient(http interfaces.HTTPClient) Option {
 	}
 }
 
-func (c *Clients) NewS3(s *session.Session) interfaces.S3 {
-	return c.newS3(s)
+func (c *Clients) NewS3(cfg aws.Config, optFns ...func(*s3.Options)) interfaces.S3 {
+	return c.newS3(cfg, optFns...)
 }
 
 func WithNewS3(newS3 interfaces.NewS3) Option {
@@ -58,8 +107,8 @@ func WithNewS3(newS3 interfaces.NewS3) Option {
 	}
 }
 
-func (c *Clients) NewSQS(s *session.Session) interfaces.SQS {
-	return c.newSQS(s)
+func (c *Clients) NewSQS(cfg aws.Config, optFns ...func(*sqs.Options)) interfaces.SQS {
+	return c.newSQS(cfg, optFns...)
 }
 
 func WithNewSQS(newSQS interfaces.NewSQS) Option {
@@ -67,3 +116,42 @@ func WithNewSQS(newSQS interfaces.NewSQS) Option {
 		c.newSQS = newSQS
 	}
 }
+
+func (c *Clients) NewKMS(cfg aws.Config, optFns ...func(*kms.Options)) interfaces.KMS {
+	return c.newKMS(cfg, optFns...)
+}
+
+func WithNewKMS(newKMS interfaces.NewKMS) Option {
+	return func(c *Clients) {
+		c.newKMS = newKMS
+	}
+}
+
+func (c *Clients) GoogleTokenSource(ctx context.Context, scopes ...string) (oauth2.TokenSource, error) {
+	return c.newGoogleTokenSource(ctx, scopes...)
+}
+
+func WithNewGoogleTokenSource(newGoogleTokenSource interfaces.NewGoogleTokenSource) Option {
+	return func(c *Clients) {
+		c.newGoogleTokenSource = newGoogleTokenSource
+	}
+}
+
+func (c *Clients) NewPubSub(ctx context.Context, projectID string) (*pubsub.Client, error) {
+	return c.newPubSub(ctx, projectID)
+}
+
+func WithNewPubSub(newPubSub interfaces.NewPubSub) Option {
+	return func(c *Clients) {
+		c.newPubSub = newPubSub
+	}
+}
+
+// With returns a copy of Clients with the options applied.
+func (c *Clients) With(opts ...Option) *Clients {
+	copied := *c
+	for _, opt := range opts {
+		opt(&copied)
+	}
+	return &copied
+}
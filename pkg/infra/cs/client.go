@@ -2,12 +2,15 @@ package cs
 
 import (
 	"context"
+	"errors"
 	"io"
+	"net/http"
 
 	"cloud.google.com/go/storage"
 	"github.com/m-mizutani/goerr"
 	"github.com/m-mizutani/hatchery/pkg/domain/interfaces"
 	"github.com/m-mizutani/hatchery/pkg/domain/types"
+	"google.golang.org/api/googleapi"
 	"google.golang.org/api/option"
 )
 
@@ -30,8 +33,12 @@ func New(ctx context.Context, opts ...option.ClientOption) (*Client, error) {
 
 // NewObjectReader implements interfaces.CloudStorage.
 func (c *Client) NewObjectReader(ctx context.Context, bucket types.CSBucket, object types.CSObjectName) (io.ReadCloser, error) {
-	r, err := c.client.Bucket(string(bucket)).Object(string(object)).NewReader(ctx)
+	// Objects with Content-Encoding are read as stored, not decompressed by GCS
+	r, err := c.client.Bucket(string(bucket)).Object(string(object)).ReadCompressed(true).NewReader(ctx)
 	if err != nil {
+		if errors.Is(err, storage.ErrObjectNotExist) {
+			return nil, goerr.Wrap(types.ErrObjectNotFound).With("bucket", bucket).With("object", object)
+		}
 		return nil, goerr.Wrap(err, "fail to create object reader")
 	}
 
@@ -39,6 +46,45 @@ func (c *Client) NewObjectReader(ctx context.Context, bucket types.CSBucket, obj
 }
 
 // NewObjectWriter implements interfaces.CloudStorage.
-func (c *Client) NewObjectWriter(ctx context.Context, bucket types.CSBucket, object types.CSObjectName) io.WriteCloser {
-	return c.client.Bucket(string(bucket)).Object(string(object)).NewWriter(ctx)
+func (c *Client) NewObjectWriter(ctx context.Context, bucket types.CSBucket, object types.CSObjectName, opts ...types.WriteOption) io.WriteCloser {
+	attrs := types.NewObjectAttrs(opts...)
+	obj := c.client.Bucket(string(bucket)).Object(string(object))
+	if attrs.IfNotExist {
+		obj = obj.If(storage.Conditions{DoesNotExist: true})
+	}
+
+	// Canceling ctx of the writer discards the object
+	ctx, cancel := context.WithCancel(ctx)
+	w := obj.NewWriter(ctx)
+	w.ContentType = attrs.ContentType
+	w.ContentEncoding = attrs.ContentEncoding
+	w.Metadata = attrs.Metadata
+	w.StorageClass = attrs.StorageClass
+	return &gcsWriter{Writer: w, cancel: cancel, bucket: bucket, object: object}
+}
+
+type gcsWriter struct {
+	*storage.Writer
+	cancel context.CancelFunc
+	bucket types.CSBucket
+	object types.CSObjectName
+}
+
+// Abort implements interfaces.ObjectAborter.
+func (x *gcsWriter) Abort() {
+	x.cancel()
+	_ = x.Writer.Close()
+}
+
+// Close returns types.ErrObjectExists if precondition of the object fails.
+func (x *gcsWriter) Close() error {
+	defer x.cancel()
+	if err := x.Writer.Close(); err != nil {
+		var apiErr *googleapi.Error
+		if errors.As(err, &apiErr) && apiErr.Code == http.StatusPreconditionFailed {
+			return goerr.Wrap(types.ErrObjectExists).With("bucket", x.bucket).With("object", x.object)
+		}
+		return err
+	}
+	return nil
 }
@@ -11,7 +11,7 @@ import (
 )
 
 type Mock struct {
-	NewObjectWriterFn func(ctx context.Context, bucket types.CSBucket, object types.CSObjectName) io.WriteCloser
+	NewObjectWriterFn func(ctx context.Context, bucket types.CSBucket, object types.CSObjectName, opts ...types.WriteOption) io.WriteCloser
 	Results           []*MockResult
 }
 
@@ -21,11 +21,18 @@ type MockResult struct {
 	Body   Writer
 	Bucket types.CSBucket
 	Object types.CSObjectName
+	Attrs  types.ObjectAttrs
 }
 
 type Writer struct {
 	bytes.Buffer
-	Closed bool
+	Closed  bool
+	Aborted bool
+}
+
+// Abort implements interfaces.ObjectAborter.
+func (x *Writer) Abort() {
+	x.Aborted = true
 }
 
 func (x *Writer) Close() error {
@@ -37,24 +44,49 @@ func NewMock() *Mock {
 	return &Mock{}
 }
 
-func (x *Mock) NewObjectWriter(ctx context.Context, bucket types.CSBucket, object types.CSObjectName) io.WriteCloser {
+func (x *Mock) NewObjectWriter(ctx context.Context, bucket types.CSBucket, object types.CSObjectName, opts ...types.WriteOption) io.WriteCloser {
 	if x.NewObjectWriterFn != nil {
-		return x.NewObjectWriterFn(ctx, bucket, object)
+		return x.NewObjectWriterFn(ctx, bucket, object, opts...)
+	}
+
+	attrs := types.NewObjectAttrs(opts...)
+	if attrs.IfNotExist && x.exists(bucket, object) {
+		return &existsWriter{}
 	}
 
 	var result MockResult
 	x.Results = append(x.Results, &result)
 	result.Bucket = bucket
 	result.Object = object
+	result.Attrs = attrs
 	return &result.Body
 }
 
-func (x *Mock) NewObjectReader(ctx context.Context, bucket types.CSBucket, object types.CSObjectName) (io.ReadCloser, error) {
+// exists returns true if the object has been closed, as an object of GCS and S3 is created on Close.
+func (x *Mock) exists(bucket types.CSBucket, object types.CSObjectName) bool {
 	for _, r := range x.Results {
-		if r.Bucket == bucket && r.Object == object {
+		if r.Bucket == bucket && r.Object == object && r.Body.Closed {
+			return true
+		}
+	}
+	return false
+}
+
+// existsWriter discards data and fails on Close as precondition of GCS and S3.
+type existsWriter struct{}
+
+func (x *existsWriter) Write(p []byte) (int, error) { return len(p), nil }
+func (x *existsWriter) Close() error                { return types.ErrObjectExists }
+func (x *existsWriter) Abort()                      {}
+
+func (x *Mock) NewObjectReader(ctx context.Context, bucket types.CSBucket, object types.CSObjectName) (io.ReadCloser, error) {
+	// The last one is read as an object overwritten
+	for i := len(x.Results) - 1; i >= 0; i-- {
+		r := x.Results[i]
+		if r.Bucket == bucket && r.Object == object && r.Body.Closed {
 			return io.NopCloser(bytes.NewReader(r.Body.Bytes())), nil
 		}
 	}
 
-	return nil, goerr.New("not found")
+	return nil, goerr.Wrap(types.ErrObjectNotFound).With("bucket", bucket).With("object", object)
 }
@@ -0,0 +1,286 @@
+package cs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/m-mizutani/goerr"
+	"github.com/m-mizutani/hatchery/pkg/domain/config"
+	"github.com/m-mizutani/hatchery/pkg/domain/interfaces"
+	"github.com/m-mizutani/hatchery/pkg/domain/types"
+	"github.com/m-mizutani/hatchery/pkg/infra"
+	"github.com/m-mizutani/hatchery/pkg/infra/awscred"
+	"github.com/m-mizutani/hatchery/pkg/infra/envelope"
+	"github.com/m-mizutani/hatchery/pkg/utils"
+)
+
+const (
+	PolicyAll     = "all"
+	PolicyPrimary = "primary"
+	PolicyAny     = "any"
+)
+
+// Destination is a copy of objects written by Multi. Objects are written with the same bytes as the primary storage.
+type Destination struct {
+	Name    string
+	Storage interfaces.CloudStorage
+	Bucket  types.CSBucket
+	// Prefix replaces prefix of the action in object name if not nil
+	Prefix *string
+	// StorageClass replaces storage class of the object if not nil
+	StorageClass *string
+}
+
+// Multi is CloudStorage that tees objects of an action to additional destinations. Objects are read from the primary storage.
+type Multi struct {
+	primary      interfaces.CloudStorage
+	actionPrefix string
+	destinations []Destination
+	policy       string
+}
+
+var _ interfaces.CloudStorage = (*Multi)(nil)
+
+func NewMulti(primary interfaces.CloudStorage, action config.Action, destinations []Destination) *Multi {
+	x := &Multi{
+		primary:      primary,
+		destinations: destinations,
+		policy:       action.GetDestinationPolicy(),
+	}
+	if prefix := action.GetPrefix(); prefix != nil {
+		x.actionPrefix = *prefix
+	}
+	if x.policy == "" {
+		x.policy = PolicyAll
+	}
+	return x
+}
+
+// ForAction returns CloudStorage for objects of the action. If the action has destinations, Multi over the primary storage of clients and the destinations is returned. If the action has encryption, objects are encrypted once and then written to all of them.
+func ForAction(ctx context.Context, clients *infra.Clients, action config.Action) (interfaces.CloudStorage, error) {
+	storage, err := withDestinations(ctx, clients, action)
+	if err != nil {
+		return nil, err
+	}
+
+	if encryption := action.GetEncryption(); encryption != nil {
+		encrypted, err := envelope.New(ctx, storage, clients, encryption)
+		if err != nil {
+			return nil, goerr.Wrap(err, "failed to configure encryption").With("id", action.GetId())
+		}
+		return encrypted, nil
+	}
+
+	return storage, nil
+}
+
+func withDestinations(ctx context.Context, clients *infra.Clients, action config.Action) (interfaces.CloudStorage, error) {
+	if len(action.GetDestinations()) == 0 {
+		return clients.CloudStorage(), nil
+	}
+
+	var destinations []Destination
+	for i, dst := range action.GetDestinations() {
+		d := Destination{
+			Bucket:       types.CSBucket(dst.GetBucket()),
+			Prefix:       dst.GetPrefix(),
+			StorageClass: dst.GetStorageClass(),
+		}
+
+		switch v := dst.(type) {
+		case *config.GCSDestinationImpl:
+			d.Storage = clients.CloudStorage()
+
+		case *config.S3DestinationImpl:
+			awsCfg, err := awscred.LoadConfig(ctx, v.AwsRegion, v.AwsCredential)
+			if err != nil {
+				return nil, goerr.Wrap(err, "failed to create AWS config of destination").With("id", action.GetId()).With("index", i)
+			}
+			client := clients.NewS3(awsCfg, func(o *s3.Options) {
+				if v.AwsEndpoint != nil {
+					o.BaseEndpoint = v.AwsEndpoint
+				}
+				o.UsePathStyle = v.AwsUsePathStyle
+			})
+			d.Storage = NewS3(client)
+
+		default:
+			return nil, goerr.Wrap(types.ErrInvalidOption, "unknown destination type").With("id", action.GetId()).With("type", fmt.Sprintf("%T", dst))
+		}
+
+		d.Name = storageName(d.Storage, d.Bucket)
+		destinations = append(destinations, d)
+	}
+
+	return NewMulti(clients.CloudStorage(), action, destinations), nil
+}
+
+// storageName returns URL of the bucket by type of the storage for logs and errors. Only the bucket is returned for unknown storage.
+func storageName(storage interfaces.CloudStorage, bucket types.CSBucket) string {
+	switch storage.(type) {
+	case *Client:
+		return "gs://" + string(bucket)
+	case *S3:
+		return "s3://" + string(bucket)
+	default:
+		return string(bucket)
+	}
+}
+
+// NewObjectReader implements interfaces.CloudStorage.
+func (x *Multi) NewObjectReader(ctx context.Context, bucket types.CSBucket, object types.CSObjectName) (io.ReadCloser, error) {
+	return x.primary.NewObjectReader(ctx, bucket, object)
+}
+
+// NewObjectWriter implements interfaces.CloudStorage. The object is written to the primary storage with given bucket and name, and to each destination with its bucket. The prefix of the action in the name is replaced with the prefix of the destination.
+func (x *Multi) NewObjectWriter(ctx context.Context, bucket types.CSBucket, object types.CSObjectName, opts ...types.WriteOption) io.WriteCloser {
+	w := &multiWriter{
+		ctx:    ctx,
+		policy: x.policy,
+		targets: []*target{{
+			name:   storageName(x.primary, bucket),
+			object: object,
+			w:      x.primary.NewObjectWriter(ctx, bucket, object, opts...),
+		}},
+	}
+
+	for _, dst := range x.destinations {
+		name := object
+		// Objects outside the prefix of the action (e.g. reference data of Slack) keep their names
+		if dst.Prefix != nil && strings.HasPrefix(string(object), x.actionPrefix) {
+			name = types.CSObjectName(*dst.Prefix + strings.TrimPrefix(string(object), x.actionPrefix))
+		}
+		dstOpts := opts
+		if dst.StorageClass != nil {
+			dstOpts = append(append([]types.WriteOption{}, opts...), types.WithStorageClass(*dst.StorageClass))
+		}
+		w.targets = append(w.targets, &target{
+			name:   dst.Name,
+			object: name,
+			w:      dst.Storage.NewObjectWriter(ctx, dst.Bucket, name, dstOpts...),
+		})
+	}
+
+	return w
+}
+
+type target struct {
+	name   string
+	object types.CSObjectName
+	w      io.WriteCloser
+	err    error
+}
+
+type multiWriter struct {
+	ctx     context.Context
+	policy  string
+	targets []*target
+	// err is set when the policy is violated and all targets are aborted
+	err error
+}
+
+// Write writes p to all targets that have not failed. A failed target is aborted not to create an incomplete object. It fails only when the policy can no longer be satisfied, and then all targets are aborted.
+func (x *multiWriter) Write(p []byte) (int, error) {
+	if x.err != nil {
+		return 0, x.err
+	}
+
+	for _, t := range x.targets {
+		if t.err != nil {
+			continue
+		}
+		if _, err := t.w.Write(p); err != nil {
+			t.err = goerr.Wrap(err, "failed to write object").With("destination", t.name).With("object", t.object)
+			abortTarget(t)
+		}
+	}
+
+	if err := x.check(); err != nil {
+		x.err = err
+		x.Abort()
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Abort implements interfaces.ObjectAborter. All targets are aborted.
+func (x *multiWriter) Abort() {
+	for _, t := range x.targets {
+		abortTarget(t)
+	}
+}
+
+// abortTarget aborts the object of the target. A writer that can not abort is closed to release resources, and the object may be left incomplete.
+func abortTarget(t *target) {
+	if _, ok := t.w.(interfaces.ObjectAborter); ok {
+		utils.SafeAbort(t.w)
+		return
+	}
+	_ = t.w.Close()
+}
+
+// Close closes all targets and evaluates the policy. Failures of targets that are tolerated by the policy are reported as errors. A target where the object already exists (types.ErrObjectExists) is regarded as written, so that a retry completes only missing copies. types.ErrObjectExists is returned if the object exists in all targets.
+func (x *multiWriter) Close() error {
+	if x.err != nil {
+		return x.err
+	}
+
+	existing := 0
+	for _, t := range x.targets {
+		if t.err != nil {
+			// Already aborted in Write
+			continue
+		}
+		if err := t.w.Close(); err != nil {
+			if errors.Is(err, types.ErrObjectExists) {
+				existing++
+				continue
+			}
+			t.err = goerr.Wrap(err, "failed to close object").With("destination", t.name).With("object", t.object)
+		}
+	}
+
+	if err := x.check(); err != nil {
+		return err
+	}
+	if existing == len(x.targets) {
+		return goerr.Wrap(types.ErrObjectExists).With("object", x.targets[0].object)
+	}
+
+	for _, t := range x.targets {
+		if t.err != nil {
+			utils.HandleError(x.ctx, "failed to write object to a destination", t.err)
+		}
+	}
+	return nil
+}
+
+// check returns error if failures of targets violate the policy.
+func (x *multiWriter) check() error {
+	var errs []error
+	for _, t := range x.targets {
+		if t.err != nil {
+			errs = append(errs, t.err)
+		}
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+
+	switch x.policy {
+	case PolicyPrimary:
+		if x.targets[0].err == nil {
+			return nil
+		}
+	case PolicyAny:
+		if len(errs) < len(x.targets) {
+			return nil
+		}
+	}
+
+	return goerr.Wrap(errors.Join(errs...), "failed to write object to destinations").With("policy", x.policy)
+}
@@ -0,0 +1,147 @@
+package cs
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	s3Types "github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/smithy-go"
+	"github.com/m-mizutani/goerr"
+	"github.com/m-mizutani/hatchery/pkg/domain/interfaces"
+	"github.com/m-mizutani/hatchery/pkg/domain/types"
+)
+
+// S3 is CloudStorage of Amazon S3. As PutObject requires content length, written data is buffered in a temporary file and uploaded on Close.
+type S3 struct {
+	client interfaces.S3
+}
+
+var _ interfaces.CloudStorage = (*S3)(nil)
+
+func NewS3(client interfaces.S3) *S3 {
+	return &S3{client: client}
+}
+
+// NewObjectReader implements interfaces.CloudStorage.
+func (x *S3) NewObjectReader(ctx context.Context, bucket types.CSBucket, object types.CSObjectName) (io.ReadCloser, error) {
+	resp, err := x.client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(string(bucket)),
+		Key:    aws.String(string(object)),
+	})
+	if err != nil {
+		var noSuchKey *s3Types.NoSuchKey
+		if errors.As(err, &noSuchKey) {
+			return nil, goerr.Wrap(types.ErrObjectNotFound).With("bucket", bucket).With("object", object)
+		}
+		return nil, goerr.Wrap(err, "fail to get S3 object").With("bucket", bucket).With("object", object)
+	}
+
+	return resp.Body, nil
+}
+
+// NewObjectWriter implements interfaces.CloudStorage.
+func (x *S3) NewObjectWriter(ctx context.Context, bucket types.CSBucket, object types.CSObjectName, opts ...types.WriteOption) io.WriteCloser {
+	return &s3Writer{
+		ctx:    ctx,
+		client: x.client,
+		bucket: bucket,
+		object: object,
+		attrs:  types.NewObjectAttrs(opts...),
+	}
+}
+
+type s3Writer struct {
+	ctx    context.Context
+	client interfaces.S3
+	bucket types.CSBucket
+	object types.CSObjectName
+	attrs  types.ObjectAttrs
+
+	tmp  *os.File
+	size int64
+	err  error
+}
+
+func (x *s3Writer) Write(p []byte) (int, error) {
+	if x.err != nil {
+		return 0, x.err
+	}
+	if x.tmp == nil {
+		tmp, err := os.CreateTemp("", "hatchery-s3-*")
+		if err != nil {
+			x.err = goerr.Wrap(err, "fail to create temporary file for S3 object")
+			return 0, x.err
+		}
+		x.tmp = tmp
+	}
+
+	n, err := x.tmp.Write(p)
+	x.size += int64(n)
+	if err != nil {
+		x.err = goerr.Wrap(err, "fail to write temporary file for S3 object")
+	}
+	return n, x.err
+}
+
+// Abort implements interfaces.ObjectAborter. The object is not put.
+func (x *s3Writer) Abort() {
+	if x.tmp != nil {
+		_ = x.tmp.Close()
+		_ = os.Remove(x.tmp.Name())
+		x.tmp = nil
+	}
+	x.err = goerr.New("object writer is aborted")
+}
+
+func (x *s3Writer) Close() error {
+	if x.tmp != nil {
+		defer func() {
+			_ = x.tmp.Close()
+			_ = os.Remove(x.tmp.Name())
+		}()
+	}
+	if x.err != nil {
+		return x.err
+	}
+
+	var body io.ReadSeeker = bytes.NewReader(nil)
+	if x.tmp != nil {
+		if _, err := x.tmp.Seek(0, io.SeekStart); err != nil {
+			return goerr.Wrap(err, "fail to seek temporary file for S3 object")
+		}
+		body = x.tmp
+	}
+
+	input := &s3.PutObjectInput{
+		Bucket:        aws.String(string(x.bucket)),
+		Key:           aws.String(string(x.object)),
+		Body:          body,
+		ContentLength: aws.Int64(x.size),
+		Metadata:      x.attrs.Metadata,
+		StorageClass:  s3Types.StorageClass(x.attrs.StorageClass),
+	}
+	if x.attrs.ContentType != "" {
+		input.ContentType = aws.String(x.attrs.ContentType)
+	}
+	if x.attrs.ContentEncoding != "" {
+		input.ContentEncoding = aws.String(x.attrs.ContentEncoding)
+	}
+	if x.attrs.IfNotExist {
+		input.IfNoneMatch = aws.String("*")
+	}
+
+	if _, err := x.client.PutObject(x.ctx, input); err != nil {
+		var apiErr smithy.APIError
+		if errors.As(err, &apiErr) && apiErr.ErrorCode() == "PreconditionFailed" {
+			return goerr.Wrap(types.ErrObjectExists).With("bucket", x.bucket).With("object", x.object)
+		}
+		return goerr.Wrap(err, "fail to put S3 object").With("bucket", x.bucket).With("object", x.object)
+	}
+
+	return nil
+}
@@ -0,0 +1,191 @@
+package dedup
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/m-mizutani/goerr"
+	"github.com/m-mizutani/hatchery/pkg/domain/config"
+	"github.com/m-mizutani/hatchery/pkg/domain/interfaces"
+	"github.com/m-mizutani/hatchery/pkg/domain/types"
+	"github.com/m-mizutani/hatchery/pkg/utils"
+)
+
+// DefaultRetention is used if retention of Dedup is not specified.
+const DefaultRetention = 24 * time.Hour
+
+// Set is IDs of events collected by an action. IDs are partitioned by hour of execution and stored as objects, and partitions older than retention are not loaded. A nil Set drops nothing, so that actions can use it regardless of Dedup config.
+type Set struct {
+	storage interfaces.CloudStorage
+	bucket  types.CSBucket
+	// Partition object of the execution hour
+	object types.CSObjectName
+
+	seen map[string]struct{}
+	// IDs of the partition of now, including ones loaded from the existing partition
+	current []string
+	added   int
+}
+
+// ObjectName returns name of the partition object of the hour.
+func ObjectName(action config.Action, hour time.Time) types.CSObjectName {
+	name := "dedup/" + action.GetId() + "/" + hour.UTC().Format("2006/01/02/15") + ".json.gz"
+	if prefix := action.GetPrefix(); prefix != nil {
+		name = *prefix + name
+	}
+	return types.CSObjectName(name)
+}
+
+// Load reads partitions of the action within retention from storage. It returns nil Set if cfg is nil. Partitions that do not exist are regarded as empty.
+func Load(ctx context.Context, storage interfaces.CloudStorage, action config.Action, cfg *config.Dedup) (*Set, error) {
+	if cfg == nil {
+		return nil, nil
+	}
+
+	retention := DefaultRetention
+	if cfg.Retention != nil {
+		retention = cfg.Retention.GoDuration()
+	}
+
+	now := utils.CtxNow(ctx).UTC().Truncate(time.Hour)
+	x := &Set{
+		storage: storage,
+		bucket:  types.CSBucket(action.GetBucket()),
+		object:  ObjectName(action, now),
+		seen:    map[string]struct{}{},
+	}
+
+	for hour := now.Add(-retention); !hour.After(now); hour = hour.Add(time.Hour) {
+		ids, err := x.read(ctx, ObjectName(action, hour))
+		if err != nil {
+			return nil, err
+		}
+		for _, id := range ids {
+			x.seen[id] = struct{}{}
+		}
+		if hour.Equal(now) {
+			x.current = ids
+		}
+	}
+	return x, nil
+}
+
+func (x *Set) read(ctx context.Context, object types.CSObjectName) ([]string, error) {
+	r, err := x.storage.NewObjectReader(ctx, x.bucket, object)
+	if err != nil {
+		if errors.Is(err, types.ErrObjectNotFound) {
+			return nil, nil
+		}
+		return nil, goerr.Wrap(err, "failed to read dedup partition").With("object", object)
+	}
+	defer utils.SafeClose(r)
+
+	dec, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to create gzip reader of dedup partition").With("object", object)
+	}
+	var ids []string
+	if err := json.NewDecoder(dec).Decode(&ids); err != nil {
+		return nil, goerr.Wrap(err, "failed to decode dedup partition").With("object", object)
+	}
+	return ids, nil
+}
+
+// Seen returns true if the ID has been collected. Otherwise the ID is recorded and false is returned, so that duplicates in the same execution are also dropped.
+func (x *Set) Seen(id string) bool {
+	if x == nil {
+		return false
+	}
+	if _, ok := x.seen[id]; ok {
+		return true
+	}
+	x.seen[id] = struct{}{}
+	x.current = append(x.current, id)
+	x.added++
+	return false
+}
+
+// Filter drops events with seen IDs from the JSON array in field of the JSON object body. ID of an event is the string value of idField, and events without it are kept. The body is returned as is if nothing is dropped, otherwise it is encoded again. It returns number of dropped events.
+func (x *Set) Filter(body []byte, field, idField string) ([]byte, int, error) {
+	if x == nil {
+		return body, 0, nil
+	}
+
+	var obj map[string]json.RawMessage
+	if err := json.Unmarshal(body, &obj); err != nil {
+		return nil, 0, goerr.Wrap(err, "failed to unmarshal body for dedup")
+	}
+	var events []json.RawMessage
+	if raw, ok := obj[field]; ok {
+		if err := json.Unmarshal(raw, &events); err != nil {
+			return nil, 0, goerr.Wrap(err, "failed to unmarshal events for dedup").With("field", field)
+		}
+	}
+
+	kept := make([]json.RawMessage, 0, len(events))
+	for _, event := range events {
+		var attrs map[string]json.RawMessage
+		if err := json.Unmarshal(event, &attrs); err != nil {
+			return nil, 0, goerr.Wrap(err, "failed to unmarshal event for dedup").With("field", field)
+		}
+		var id string
+		if raw, ok := attrs[idField]; ok {
+			_ = json.Unmarshal(raw, &id) // Non-string ID is regarded as missing
+		}
+		if id != "" && x.Seen(id) {
+			continue
+		}
+		kept = append(kept, event)
+	}
+
+	dropped := len(events) - len(kept)
+	if dropped == 0 {
+		return body, 0, nil
+	}
+
+	raw, err := json.Marshal(kept)
+	if err != nil {
+		return nil, 0, goerr.Wrap(err, "failed to marshal events for dedup")
+	}
+	obj[field] = raw
+	filtered, err := json.Marshal(obj)
+	if err != nil {
+		return nil, 0, goerr.Wrap(err, "failed to marshal body for dedup")
+	}
+	return filtered, dropped, nil
+}
+
+// Save writes the partition of the execution hour with IDs recorded by Seen. It should be called after the events are written, because events with saved IDs are never collected again. Nothing is written if no ID is recorded.
+func (x *Set) Save(ctx context.Context) error {
+	if x == nil || x.added == 0 {
+		return nil
+	}
+
+	var buf bytes.Buffer
+	enc := gzip.NewWriter(&buf)
+	if err := json.NewEncoder(enc).Encode(x.current); err != nil {
+		return goerr.Wrap(err, "failed to encode dedup partition").With("object", x.object)
+	}
+	if err := enc.Close(); err != nil {
+		return goerr.Wrap(err, "failed to encode dedup partition").With("object", x.object)
+	}
+
+	w := x.storage.NewObjectWriter(ctx, x.bucket, x.object,
+		types.WithContentType("application/json"),
+		types.WithContentEncoding("gzip"),
+	)
+	if _, err := w.Write(buf.Bytes()); err != nil {
+		utils.SafeAbort(w)
+		return goerr.Wrap(err, "failed to write dedup partition").With("object", x.object)
+	}
+	if err := w.Close(); err != nil {
+		return goerr.Wrap(err, "failed to close dedup partition").With("object", x.object)
+	}
+
+	utils.CtxLogger(ctx).Info("saved dedup partition", "object", x.object, "added", x.added, "ids", len(x.current))
+	return nil
+}
@@ -0,0 +1,78 @@
+package dedup_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/apple/pkl-go/pkl"
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/hatchery/pkg/domain/config"
+	"github.com/m-mizutani/hatchery/pkg/infra/cs"
+	"github.com/m-mizutani/hatchery/pkg/infra/dedup"
+	"github.com/m-mizutani/hatchery/pkg/utils"
+)
+
+func TestSet(t *testing.T) {
+	mock := cs.NewMock()
+	prefix := "slack/"
+	action := &config.SlackImpl{Id: "test", Bucket: "test-bucket", Prefix: &prefix}
+	cfg := &config.Dedup{Retention: &pkl.Duration{Value: 2, Unit: pkl.Hour}}
+
+	now := time.Date(2024, 4, 1, 10, 30, 0, 0, time.UTC)
+	ctxAt := func(now time.Time) context.Context {
+		return utils.CtxWithNow(context.Background(), func() time.Time { return now })
+	}
+
+	t.Run("drop duplicates", func(t *testing.T) {
+		seen := gt.R1(dedup.Load(ctxAt(now), mock, action, cfg)).NoError(t)
+
+		body := []byte(`{"entries":[{"id":"a"},{"id":"b"},{"id":"a"},{"no_id":1}],"response_metadata":{"next_cursor":""}}`)
+		filtered, dropped := gt.R2(seen.Filter(body, "entries", "id")).NoError(t)
+		gt.Equal(t, dropped, 1)
+		gt.Equal(t, string(filtered), `{"entries":[{"id":"a"},{"id":"b"},{"no_id":1}],"response_metadata":{"next_cursor":""}}`)
+
+		// Body is not changed if nothing is dropped
+		body = []byte(`{"entries": [{"id": "c"}]}`)
+		filtered, dropped = gt.R2(seen.Filter(body, "entries", "id")).NoError(t)
+		gt.Equal(t, dropped, 0)
+		gt.Equal(t, string(filtered), string(body))
+
+		gt.NoError(t, seen.Save(ctxAt(now)))
+		gt.A(t, mock.Results).Length(1).At(0, func(t testing.TB, v *cs.MockResult) {
+			gt.Equal(t, v.Object, "slack/dedup/test/2024/04/01/10.json.gz")
+			gt.Equal(t, v.Attrs.ContentEncoding, "gzip")
+		})
+	})
+
+	t.Run("saved IDs within retention", func(t *testing.T) {
+		seen := gt.R1(dedup.Load(ctxAt(now.Add(time.Hour)), mock, action, cfg)).NoError(t)
+		gt.Equal(t, seen.Seen("a"), true)
+		gt.Equal(t, seen.Seen("c"), true)
+		gt.Equal(t, seen.Seen("d"), false)
+	})
+
+	t.Run("partition of the same hour is merged", func(t *testing.T) {
+		seen := gt.R1(dedup.Load(ctxAt(now.Add(10*time.Minute)), mock, action, cfg)).NoError(t)
+		gt.Equal(t, seen.Seen("e"), false)
+		gt.NoError(t, seen.Save(ctxAt(now)))
+
+		seen = gt.R1(dedup.Load(ctxAt(now), mock, action, cfg)).NoError(t)
+		gt.Equal(t, seen.Seen("a"), true)
+		gt.Equal(t, seen.Seen("e"), true)
+	})
+
+	t.Run("expired by retention", func(t *testing.T) {
+		seen := gt.R1(dedup.Load(ctxAt(now.Add(3*time.Hour)), mock, action, cfg)).NoError(t)
+		gt.Equal(t, seen.Seen("a"), false)
+	})
+
+	t.Run("nil set drops nothing", func(t *testing.T) {
+		seen := gt.R1(dedup.Load(ctxAt(now), mock, action, nil)).NoError(t)
+		body := []byte(`{"entries":[{"id":"a"}]}`)
+		filtered, dropped := gt.R2(seen.Filter(body, "entries", "id")).NoError(t)
+		gt.Equal(t, dropped, 0)
+		gt.Equal(t, string(filtered), string(body))
+		gt.NoError(t, seen.Save(ctxAt(now)))
+	})
+}
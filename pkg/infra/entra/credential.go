@@ -0,0 +1,146 @@
+package entra
+
+import (
+	"context"
+	"crypto/rsa"
+	"crypto/sha1" // #nosec G505 SHA-1 is required for x5t header of client assertion
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/m-mizutani/goerr"
+	"github.com/m-mizutani/hatchery/pkg/domain/interfaces"
+	"github.com/m-mizutani/hatchery/pkg/domain/types"
+	"github.com/m-mizutani/hatchery/pkg/infra/oauth"
+	"github.com/m-mizutani/hatchery/pkg/utils"
+)
+
+const (
+	// Token endpoint of Microsoft identity platform. Tenant ID is embedded.
+	tokenURLFormat = "https://login.microsoftonline.com/%s/oauth2/v2.0/token"
+
+	clientAssertionType = "urn:ietf:params:oauth:client-assertion-type:jwt-bearer"
+)
+
+// Credential acquires access tokens of Microsoft identity platform (Entra ID) by OAuth 2.0 client credentials flow with client secret or certificate. Tokens are cached per scope by oauth.TokenSource, so one Credential can be shared by Graph based collectors and Office 365 Management API.
+type Credential struct {
+	client   interfaces.HTTPClient
+	tenantID string
+	clientID string
+
+	secret string
+	cert   *certificate
+
+	mutex   sync.Mutex
+	sources map[string]oauth.TokenSource
+}
+
+type certificate struct {
+	key        *rsa.PrivateKey
+	thumbprint string
+}
+
+// NewSecretCredential creates Credential with client secret.
+func NewSecretCredential(client interfaces.HTTPClient, tenantID, clientID, secret string) *Credential {
+	return &Credential{
+		client:   client,
+		tenantID: tenantID,
+		clientID: clientID,
+		secret:   secret,
+		sources:  map[string]oauth.TokenSource{},
+	}
+}
+
+// NewCertificateCredential creates Credential with PEM encoded certificate and RSA private key (PKCS#1 or PKCS#8). The certificate must be registered to the application.
+func NewCertificateCredential(client interfaces.HTTPClient, tenantID, clientID string, certPEM, keyPEM []byte) (*Credential, error) {
+	certBlock, _ := pem.Decode(certPEM)
+	if certBlock == nil {
+		return nil, goerr.Wrap(types.ErrInvalidOption, "failed to decode certificate PEM")
+	}
+	cert, err := x509.ParseCertificate(certBlock.Bytes)
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to parse certificate")
+	}
+
+	key, err := oauth.ParseRSAPrivateKey(keyPEM)
+	if err != nil {
+		return nil, err
+	}
+
+	thumbprint := sha1.Sum(cert.Raw) // #nosec G401
+	return &Credential{
+		client:   client,
+		tenantID: tenantID,
+		clientID: clientID,
+		cert: &certificate{
+			key:        key,
+			thumbprint: base64.RawURLEncoding.EncodeToString(thumbprint[:]),
+		},
+		sources: map[string]oauth.TokenSource{},
+	}, nil
+}
+
+func (x *Credential) tokenURL() string {
+	return fmt.Sprintf(tokenURLFormat, url.PathEscape(x.tenantID))
+}
+
+// Token returns access token for the scope (e.g. https://graph.microsoft.com/.default). A cached token is returned if it is still valid.
+func (x *Credential) Token(ctx context.Context, scope string) (string, error) {
+	return x.TokenSource(scope).Token(ctx)
+}
+
+// TokenSource returns oauth.TokenSource of the scope to be used with oauth.NewClient. The same TokenSource is returned for the same scope.
+func (x *Credential) TokenSource(scope string) oauth.TokenSource {
+	x.mutex.Lock()
+	defer x.mutex.Unlock()
+
+	if ts, ok := x.sources[scope]; ok {
+		return ts
+	}
+
+	cfg := oauth.Config{
+		TokenURL:     x.tokenURL(),
+		ClientID:     x.clientID,
+		ClientSecret: x.secret,
+		Params:       url.Values{"scope": {scope}},
+	}
+	if x.cert != nil {
+		// Assertion expires shortly, then it is created for each token request
+		cfg.ParamsFunc = func(ctx context.Context) (url.Values, error) {
+			assertion, err := x.clientAssertion(utils.CtxNow(ctx))
+			if err != nil {
+				return nil, err
+			}
+			return url.Values{
+				"client_assertion_type": {clientAssertionType},
+				"client_assertion":      {assertion},
+			}, nil
+		}
+	}
+
+	ts := oauth.NewTokenSource(x.client, cfg)
+	x.sources[scope] = ts
+	return ts
+}
+
+// clientAssertion builds JWT signed by the certificate key.
+// See https://learn.microsoft.com/en-us/entra/identity-platform/certificate-credentials
+func (x *Credential) clientAssertion(now time.Time) (string, error) {
+	assertion, err := oauth.SignJWT(x.cert.key, map[string]string{"x5t": x.cert.thumbprint}, map[string]any{
+		"aud": x.tokenURL(),
+		"iss": x.clientID,
+		"sub": x.clientID,
+		"jti": uuid.NewString(),
+		"nbf": now.Unix(),
+		"exp": now.Add(10 * time.Minute).Unix(),
+	})
+	if err != nil {
+		return "", goerr.Wrap(err, "failed to sign client assertion")
+	}
+	return assertion, nil
+}
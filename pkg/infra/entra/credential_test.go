@@ -0,0 +1,100 @@
+package entra_test
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/hatchery/pkg/infra/entra"
+	"github.com/m-mizutani/hatchery/pkg/utils"
+)
+
+func TestSecretCredential(t *testing.T) {
+	var called int
+	client := utils.NewFakeHTTPClient(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called++
+		gt.Equal(t, r.URL.String(), "https://login.microsoftonline.com/test-tenant/oauth2/v2.0/token")
+		gt.NoError(t, r.ParseForm())
+		gt.Equal(t, r.PostForm.Get("client_id"), "test-client")
+		gt.Equal(t, r.PostForm.Get("client_secret"), "test-secret")
+		_, _ = w.Write([]byte(`{"access_token":"token-` + r.PostForm.Get("scope") + `","expires_in":3600}`))
+	}))
+
+	now := time.Date(2024, 4, 1, 10, 0, 0, 0, time.UTC)
+	ctx := utils.CtxWithNow(context.Background(), func() time.Time { return now })
+	cred := entra.NewSecretCredential(client, "test-tenant", "test-client", "test-secret")
+
+	gt.Equal(t, gt.R1(cred.Token(ctx, "scope-a")).NoError(t), "token-scope-a")
+	gt.Equal(t, gt.R1(cred.Token(ctx, "scope-a")).NoError(t), "token-scope-a")
+	gt.Equal(t, called, 1)
+
+	gt.Equal(t, gt.R1(cred.Token(ctx, "scope-b")).NoError(t), "token-scope-b")
+	gt.Equal(t, called, 2)
+
+	// Token is refreshed shortly before expiry
+	now = now.Add(58 * time.Minute)
+	gt.R1(cred.Token(ctx, "scope-a")).NoError(t)
+	gt.Equal(t, called, 2)
+
+	now = now.Add(90 * time.Second)
+	gt.R1(cred.Token(ctx, "scope-a")).NoError(t)
+	gt.Equal(t, called, 3)
+}
+
+func TestCertificateCredential(t *testing.T) {
+	key := gt.R1(rsa.GenerateKey(rand.Reader, 2048)).NoError(t)
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "hatchery-test"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der := gt.R1(x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)).NoError(t)
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+
+	assertions := map[string]bool{}
+	client := utils.NewFakeHTTPClient(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gt.NoError(t, r.ParseForm())
+		_, hasSecret := r.PostForm["client_secret"]
+		gt.Equal(t, hasSecret, false)
+		assertions[r.PostForm.Get("client_assertion")] = true
+		gt.Equal(t, r.PostForm.Get("client_assertion_type"), "urn:ietf:params:oauth:client-assertion-type:jwt-bearer")
+
+		parts := strings.Split(r.PostForm.Get("client_assertion"), ".")
+		gt.A(t, parts).Length(3).Must()
+		digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+		sig := gt.R1(base64.RawURLEncoding.DecodeString(parts[2])).NoError(t)
+		gt.NoError(t, rsa.VerifyPKCS1v15(&key.PublicKey, crypto.SHA256, digest[:], sig))
+
+		var claims map[string]any
+		gt.NoError(t, json.Unmarshal(gt.R1(base64.RawURLEncoding.DecodeString(parts[1])).NoError(t), &claims))
+		gt.Equal(t, claims["iss"], "test-client")
+		gt.Equal(t, claims["aud"], "https://login.microsoftonline.com/test-tenant/oauth2/v2.0/token")
+
+		_, _ = w.Write([]byte(`{"access_token":"cert-token","expires_in":3600}`))
+	}))
+
+	now := time.Now()
+	ctx := utils.CtxWithNow(context.Background(), func() time.Time { return now })
+	cred := gt.R1(entra.NewCertificateCredential(client, "test-tenant", "test-client", certPEM, keyPEM)).NoError(t)
+	gt.Equal(t, gt.R1(cred.Token(ctx, "scope")).NoError(t), "cert-token")
+
+	// Assertion is created again for refresh
+	now = now.Add(time.Hour)
+	gt.Equal(t, gt.R1(cred.Token(ctx, "scope")).NoError(t), "cert-token")
+	gt.Equal(t, len(assertions), 2)
+}
@@ -0,0 +1,244 @@
+package envelope
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"io"
+
+	"filippo.io/age"
+	"github.com/m-mizutani/goerr"
+	"github.com/m-mizutani/hatchery/pkg/domain/config"
+	"github.com/m-mizutani/hatchery/pkg/domain/interfaces"
+	"github.com/m-mizutani/hatchery/pkg/domain/types"
+	"github.com/m-mizutani/hatchery/pkg/infra"
+	"github.com/m-mizutani/hatchery/pkg/utils"
+)
+
+const keyVersion = 1
+
+// Key is stored as the sidecar object of an encrypted object. It has the data key wrapped by KEK.
+type Key struct {
+	Version    int    `json:"version"`
+	Algorithm  string `json:"algorithm"`
+	KEK        KEK    `json:"kek"`
+	WrappedKey []byte `json:"wrapped_key"`
+}
+
+// KeyObjectName returns name of the sidecar object that has Key of the object.
+func KeyObjectName(object types.CSObjectName) types.CSObjectName {
+	return object + ".key"
+}
+
+// Storage is CloudStorage that encrypts objects by envelope encryption before writing them into the base storage. Each object is encrypted by a random data key, and the data key wrapped by KEK is written as the sidecar object. Objects read from Storage are decrypted by the sidecar.
+type Storage struct {
+	base       interfaces.CloudStorage
+	clients    *infra.Clients
+	wrapper    keyWrapper
+	kek        KEK
+	identities []age.Identity
+}
+
+var _ interfaces.CloudStorage = (*Storage)(nil)
+
+type Option func(*Storage)
+
+// WithAgeIdentities sets age identities to decrypt objects encrypted by AgeEncryption.
+func WithAgeIdentities(identities ...age.Identity) Option {
+	return func(x *Storage) {
+		x.identities = append(x.identities, identities...)
+	}
+}
+
+// New creates Storage over base. If encryption is nil, Storage can only read encrypted objects (e.g. for decrypt command).
+func New(ctx context.Context, base interfaces.CloudStorage, clients *infra.Clients, encryption config.Encryption, opts ...Option) (*Storage, error) {
+	x := &Storage{
+		base:    base,
+		clients: clients,
+	}
+	for _, opt := range opts {
+		opt(x)
+	}
+
+	if encryption != nil {
+		wrapper, kek, err := newSealingWrapper(ctx, clients, encryption)
+		if err != nil {
+			return nil, err
+		}
+		x.wrapper, x.kek = wrapper, kek
+	}
+
+	return x, nil
+}
+
+// NewObjectWriter implements interfaces.CloudStorage. The data key is wrapped before the object is created, and error of wrapping is returned by Write and Close. Content type and encoding of opts are replaced because the encrypted object is opaque binary, and other attributes are set to both of the object and the key object.
+func (x *Storage) NewObjectWriter(ctx context.Context, bucket types.CSBucket, object types.CSObjectName, opts ...types.WriteOption) io.WriteCloser {
+	w, err := x.newObjectWriter(ctx, bucket, object, opts)
+	if err != nil {
+		return &errWriter{err: err}
+	}
+	return w
+}
+
+func (x *Storage) newObjectWriter(ctx context.Context, bucket types.CSBucket, object types.CSObjectName, opts []types.WriteOption) (*objectWriter, error) {
+	if x.wrapper == nil {
+		return nil, goerr.Wrap(types.ErrInvalidOption, "encryption is not configured").With("object", object)
+	}
+
+	dataKey := make([]byte, dataKeySize)
+	if _, err := rand.Read(dataKey); err != nil {
+		return nil, goerr.Wrap(err, "failed to generate data key")
+	}
+	wrapped, err := x.wrapper.wrap(ctx, dataKey)
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to wrap data key").With("object", object).With("kek", x.kek)
+	}
+
+	opts = append(append([]types.WriteOption{}, opts...),
+		types.WithContentEncoding(""),
+		types.WithMetadata("hatchery-encryption", Algorithm),
+	)
+	obj := x.base.NewObjectWriter(ctx, bucket, object, append(opts, types.WithContentType("application/octet-stream"))...)
+	enc, err := newEncryptWriter(obj, dataKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return &objectWriter{
+		ctx:    ctx,
+		base:   x.base,
+		bucket: bucket,
+		object: object,
+		opts:   opts,
+		obj:    obj,
+		enc:    enc,
+		key: &Key{
+			Version:    keyVersion,
+			Algorithm:  Algorithm,
+			KEK:        x.kek,
+			WrappedKey: wrapped,
+		},
+	}, nil
+}
+
+// NewObjectReader implements interfaces.CloudStorage. The data key is unwrapped by credentials of clients.
+func (x *Storage) NewObjectReader(ctx context.Context, bucket types.CSBucket, object types.CSObjectName) (io.ReadCloser, error) {
+	key, err := x.readKey(ctx, bucket, object)
+	if err != nil {
+		return nil, err
+	}
+
+	wrapper, err := newOpeningWrapper(ctx, x.clients, key.KEK, x.identities)
+	if err != nil {
+		return nil, err
+	}
+	dataKey, err := wrapper.unwrap(ctx, key.WrappedKey)
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to unwrap data key").With("object", object).With("kek", key.KEK)
+	}
+
+	obj, err := x.base.NewObjectReader(ctx, bucket, object)
+	if err != nil {
+		return nil, err
+	}
+	dec, err := newDecryptReader(obj, dataKey)
+	if err != nil {
+		utils.SafeClose(obj)
+		return nil, err
+	}
+
+	return &objectReader{Reader: dec, Closer: obj}, nil
+}
+
+func (x *Storage) readKey(ctx context.Context, bucket types.CSBucket, object types.CSObjectName) (*Key, error) {
+	keyObj := KeyObjectName(object)
+	r, err := x.base.NewObjectReader(ctx, bucket, keyObj)
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to read key object").With("object", keyObj)
+	}
+	defer utils.SafeClose(r)
+
+	var key Key
+	if err := json.NewDecoder(r).Decode(&key); err != nil {
+		return nil, goerr.Wrap(err, "failed to decode key object").With("object", keyObj)
+	}
+	if key.Version != keyVersion || key.Algorithm != Algorithm {
+		return nil, goerr.New("unsupported key object").With("object", keyObj).With("version", key.Version).With("algorithm", key.Algorithm)
+	}
+
+	return &key, nil
+}
+
+type objectWriter struct {
+	ctx    context.Context
+	base   interfaces.CloudStorage
+	bucket types.CSBucket
+	object types.CSObjectName
+	opts   []types.WriteOption
+	obj    io.WriteCloser
+	enc    io.WriteCloser
+	key    *Key
+}
+
+func (x *objectWriter) Write(p []byte) (int, error) {
+	return x.enc.Write(p)
+}
+
+// Abort implements interfaces.ObjectAborter.
+func (x *objectWriter) Abort() {
+	utils.SafeAbort(x.obj)
+}
+
+// Close writes the last chunk and the key object, and then closes the object. The object is aborted if any of them fails, not to complete an object that can not be decrypted.
+func (x *objectWriter) Close() (err error) {
+	defer func() {
+		if err != nil {
+			utils.SafeAbort(x.obj)
+		}
+	}()
+
+	if err := x.enc.Close(); err != nil {
+		return goerr.Wrap(err, "failed to close encrypter").With("object", x.object)
+	}
+
+	keyObj := KeyObjectName(x.object)
+	w := x.base.NewObjectWriter(x.ctx, x.bucket, keyObj, append(x.opts, types.WithContentType("application/json"))...)
+	if err := json.NewEncoder(w).Encode(x.key); err != nil {
+		utils.SafeAbort(w)
+		return goerr.Wrap(err, "failed to write key object").With("object", keyObj)
+	}
+	if err := w.Close(); err != nil {
+		if errors.Is(err, types.ErrObjectExists) {
+			return x.exists(err)
+		}
+		return goerr.Wrap(err, "failed to close key object").With("object", keyObj)
+	}
+
+	if err := x.obj.Close(); err != nil {
+		return goerr.Wrap(err, "failed to close object").With("object", x.object)
+	}
+	return nil
+}
+
+// exists is called when the key object already exists by precondition (types.WithIfNotExist). As the key object is written before the object, the object is regarded as written only if the object also exists. Otherwise the key object is left by a failed write and must be removed to write the object again, because the object can not be decrypted by a new key object.
+func (x *objectWriter) exists(cause error) error {
+	r, err := x.base.NewObjectReader(x.ctx, x.bucket, x.object)
+	if err != nil {
+		return goerr.Wrap(err, "key object exists without the object").With("object", x.object).With("key", KeyObjectName(x.object))
+	}
+	utils.SafeClose(r)
+	return goerr.Wrap(cause, "encrypted object already exists").With("object", x.object)
+}
+
+type objectReader struct {
+	io.Reader
+	io.Closer
+}
+
+type errWriter struct {
+	err error
+}
+
+func (x *errWriter) Write(p []byte) (int, error) { return 0, x.err }
+func (x *errWriter) Close() error                { return x.err }
@@ -0,0 +1,239 @@
+package envelope_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"testing"
+
+	"filippo.io/age"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/kms"
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/hatchery/pkg/domain/config"
+	"github.com/m-mizutani/hatchery/pkg/domain/interfaces"
+	"github.com/m-mizutani/hatchery/pkg/domain/types"
+	"github.com/m-mizutani/hatchery/pkg/infra"
+	"github.com/m-mizutani/hatchery/pkg/infra/cs"
+	"github.com/m-mizutani/hatchery/pkg/infra/envelope"
+	"github.com/m-mizutani/hatchery/pkg/utils"
+	"golang.org/x/oauth2"
+)
+
+func writeObject(t *testing.T, storage *envelope.Storage, data []byte) {
+	w := storage.NewObjectWriter(context.Background(), "test-bucket", "obj.json.gz.enc")
+	// Write in small pieces to cross chunk boundaries
+	for i := 0; i < len(data); i += 1000 {
+		_ = gt.R1(w.Write(data[i:min(i+1000, len(data))])).NoError(t)
+	}
+	gt.NoError(t, w.Close())
+}
+
+func readObject(storage *envelope.Storage) ([]byte, error) {
+	r, err := storage.NewObjectReader(context.Background(), "test-bucket", "obj.json.gz.enc")
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return io.ReadAll(r)
+}
+
+func readKey(t *testing.T, mock *cs.Mock) envelope.Key {
+	r := gt.R1(mock.NewObjectReader(context.Background(), "test-bucket", "obj.json.gz.enc.key")).NoError(t)
+	var key envelope.Key
+	gt.NoError(t, json.NewDecoder(r).Decode(&key))
+	return key
+}
+
+func TestAge(t *testing.T) {
+	ctx := context.Background()
+	identity := gt.R1(age.GenerateX25519Identity()).NoError(t)
+	encryption := &config.AgeEncryptionImpl{Recipient: identity.Recipient().String()}
+
+	for _, size := range []int{0, 1, 64 * 1024, 200 * 1024} {
+		data := make([]byte, size)
+		_ = gt.R1(rand.Read(data)).NoError(t)
+
+		mock := cs.NewMock()
+		clients := infra.New(infra.WithCloudStorage(mock))
+		storage := gt.R1(envelope.New(ctx, mock, clients, encryption, envelope.WithAgeIdentities(identity))).NoError(t)
+		writeObject(t, storage, data)
+
+		gt.A(t, mock.Results).Length(2).
+			At(0, func(t testing.TB, v *cs.MockResult) {
+				gt.Equal(t, v.Object, "obj.json.gz.enc")
+				gt.Equal(t, v.Body.Closed, true)
+				gt.Equal(t, v.Attrs.ContentType, "application/octet-stream")
+				gt.Equal(t, v.Attrs.Metadata["hatchery-encryption"], envelope.Algorithm)
+				if size > 1 {
+					gt.Equal(t, bytes.Contains(v.Body.Bytes(), data), false)
+				}
+			}).
+			At(1, func(t testing.TB, v *cs.MockResult) {
+				gt.Equal(t, v.Object, "obj.json.gz.enc.key")
+				gt.Equal(t, v.Body.Closed, true)
+				gt.Equal(t, v.Attrs.ContentType, "application/json")
+			})
+
+		key := readKey(t, mock)
+		gt.Equal(t, key.Algorithm, envelope.Algorithm)
+		gt.Equal(t, key.KEK, envelope.KEK{Type: envelope.KEKTypeAge, ID: encryption.Recipient})
+
+		got := gt.R1(readObject(storage)).NoError(t)
+		gt.Equal(t, len(got), size)
+		gt.B(t, bytes.Equal(got, data)).True()
+	}
+
+	t.Run("modified object", func(t *testing.T) {
+		mock := cs.NewMock()
+		storage := gt.R1(envelope.New(ctx, mock, infra.New(), encryption, envelope.WithAgeIdentities(identity))).NoError(t)
+		writeObject(t, storage, []byte("secret data"))
+		mock.Results[0].Body.Bytes()[3] ^= 0xff
+		gt.R1(readObject(storage)).Error(t)
+	})
+
+	t.Run("truncated object", func(t *testing.T) {
+		mock := cs.NewMock()
+		storage := gt.R1(envelope.New(ctx, mock, infra.New(), encryption, envelope.WithAgeIdentities(identity))).NoError(t)
+		writeObject(t, storage, make([]byte, 100*1024))
+		mock.Results[0].Body.Truncate(64*1024 + 16)
+		gt.R1(readObject(storage)).Error(t)
+	})
+
+	t.Run("identity is required", func(t *testing.T) {
+		mock := cs.NewMock()
+		storage := gt.R1(envelope.New(ctx, mock, infra.New(), encryption)).NoError(t)
+		writeObject(t, storage, []byte("secret data"))
+		gt.R1(readObject(storage)).Error(t)
+	})
+
+	t.Run("if not exist", func(t *testing.T) {
+		mock := cs.NewMock()
+		storage := gt.R1(envelope.New(ctx, mock, infra.New(), encryption, envelope.WithAgeIdentities(identity))).NoError(t)
+		write := func() error {
+			w := storage.NewObjectWriter(ctx, "test-bucket", "obj.json.gz.enc", types.WithIfNotExist())
+			_ = gt.R1(w.Write([]byte("secret data"))).NoError(t)
+			return w.Close()
+		}
+
+		gt.NoError(t, write())
+		err := write()
+		gt.B(t, errors.Is(err, types.ErrObjectExists)).True()
+		gt.A(t, mock.Results).Length(2)
+		gt.Equal(t, string(gt.R1(readObject(storage)).NoError(t)), "secret data")
+	})
+
+	t.Run("key object left by failed write", func(t *testing.T) {
+		mock := cs.NewMock()
+		storage := gt.R1(envelope.New(ctx, mock, infra.New(), encryption)).NoError(t)
+		kw := mock.NewObjectWriter(ctx, "test-bucket", "obj.json.gz.enc.key")
+		gt.NoError(t, kw.Close())
+
+		w := storage.NewObjectWriter(ctx, "test-bucket", "obj.json.gz.enc", types.WithIfNotExist())
+		_ = gt.R1(w.Write([]byte("secret data"))).NoError(t)
+		err := w.Close()
+		gt.Error(t, err)
+		gt.Equal(t, errors.Is(err, types.ErrObjectExists), false)
+	})
+
+	t.Run("read only", func(t *testing.T) {
+		mock := cs.NewMock()
+		storage := gt.R1(envelope.New(ctx, mock, infra.New(), nil)).NoError(t)
+		w := storage.NewObjectWriter(ctx, "test-bucket", "obj.json.gz.enc")
+		gt.Error(t, w.Close())
+		gt.A(t, mock.Results).Length(0)
+	})
+}
+
+type mockKMS struct {
+	fail bool
+}
+
+func (x *mockKMS) Encrypt(ctx context.Context, input *kms.EncryptInput, optFns ...func(*kms.Options)) (*kms.EncryptOutput, error) {
+	if x.fail {
+		return nil, errors.New("access denied")
+	}
+	return &kms.EncryptOutput{CiphertextBlob: append([]byte(*input.KeyId+":"), input.Plaintext...)}, nil
+}
+
+func (x *mockKMS) Decrypt(ctx context.Context, input *kms.DecryptInput, optFns ...func(*kms.Options)) (*kms.DecryptOutput, error) {
+	return &kms.DecryptOutput{Plaintext: bytes.TrimPrefix(input.CiphertextBlob, []byte(*input.KeyId+":"))}, nil
+}
+
+func TestAWSKMS(t *testing.T) {
+	ctx := context.Background()
+	encryption := &config.AWSKMSEncryptionImpl{
+		KeyId:     "alias/hatchery",
+		AwsRegion: "us-east-1",
+		AwsCredential: &config.AWSStaticCredentialImpl{
+			AccessKeyId:     "TESTACCESSKEYID0",
+			SecretAccessKey: "test-secret",
+		},
+	}
+
+	t.Run("round trip", func(t *testing.T) {
+		mock := cs.NewMock()
+		clients := infra.New(infra.WithNewKMS(func(cfg aws.Config, optFns ...func(*kms.Options)) interfaces.KMS {
+			return &mockKMS{}
+		}))
+		storage := gt.R1(envelope.New(ctx, mock, clients, encryption)).NoError(t)
+		writeObject(t, storage, []byte("secret data"))
+
+		key := readKey(t, mock)
+		gt.Equal(t, key.KEK, envelope.KEK{Type: envelope.KEKTypeAWSKMS, ID: "alias/hatchery", Region: "us-east-1"})
+		gt.Equal(t, string(gt.R1(readObject(storage)).NoError(t)), "secret data")
+	})
+
+	t.Run("object is not created if wrapping fails", func(t *testing.T) {
+		mock := cs.NewMock()
+		clients := infra.New(infra.WithNewKMS(func(cfg aws.Config, optFns ...func(*kms.Options)) interfaces.KMS {
+			return &mockKMS{fail: true}
+		}))
+		storage := gt.R1(envelope.New(ctx, mock, clients, encryption)).NoError(t)
+		w := storage.NewObjectWriter(ctx, "test-bucket", "obj.json.gz.enc")
+		gt.R1(w.Write([]byte("secret data"))).Error(t)
+		gt.Error(t, w.Close())
+		gt.A(t, mock.Results).Length(0)
+	})
+}
+
+func TestGCPKMS(t *testing.T) {
+	keyName := "projects/my-project/locations/global/keyRings/my-ring/cryptoKeys/my-key"
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST cloudkms.googleapis.com/v1/"+keyName+":encrypt", func(w http.ResponseWriter, r *http.Request) {
+		gt.Equal(t, r.Header.Get("Authorization"), "Bearer gcp-token")
+		var req struct {
+			Plaintext []byte `json:"plaintext"`
+		}
+		gt.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		gt.NoError(t, json.NewEncoder(w).Encode(map[string]any{"ciphertext": append([]byte("wrapped:"), req.Plaintext...)}))
+	})
+	mux.HandleFunc("POST cloudkms.googleapis.com/v1/"+keyName+":decrypt", func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Ciphertext []byte `json:"ciphertext"`
+		}
+		gt.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		gt.NoError(t, json.NewEncoder(w).Encode(map[string]any{"plaintext": bytes.TrimPrefix(req.Ciphertext, []byte("wrapped:"))}))
+	})
+
+	clients := infra.New(
+		infra.WithHTTPClient(utils.NewFakeHTTPClient(mux)),
+		infra.WithNewGoogleTokenSource(func(ctx context.Context, scopes ...string) (oauth2.TokenSource, error) {
+			return oauth2.StaticTokenSource(&oauth2.Token{AccessToken: "gcp-token"}), nil
+		}),
+	)
+
+	ctx := context.Background()
+	mock := cs.NewMock()
+	storage := gt.R1(envelope.New(ctx, mock, clients, &config.GCPKMSEncryptionImpl{KeyName: keyName})).NoError(t)
+	writeObject(t, storage, []byte("secret data"))
+
+	key := readKey(t, mock)
+	gt.Equal(t, key.KEK, envelope.KEK{Type: envelope.KEKTypeGCPKMS, ID: keyName})
+	gt.Equal(t, string(gt.R1(readObject(storage)).NoError(t)), "secret data")
+}
@@ -0,0 +1,227 @@
+package envelope
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"filippo.io/age"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/kms"
+	"github.com/m-mizutani/goerr"
+	"github.com/m-mizutani/hatchery/pkg/domain/config"
+	"github.com/m-mizutani/hatchery/pkg/domain/interfaces"
+	"github.com/m-mizutani/hatchery/pkg/domain/types"
+	"github.com/m-mizutani/hatchery/pkg/infra"
+	"github.com/m-mizutani/hatchery/pkg/infra/awscred"
+	"github.com/m-mizutani/hatchery/pkg/infra/oauth"
+	"github.com/m-mizutani/hatchery/pkg/utils"
+)
+
+const (
+	KEKTypeGCPKMS = "gcp_kms"
+	KEKTypeAWSKMS = "aws_kms"
+	KEKTypeAge    = "age"
+
+	// See https://cloud.google.com/kms/docs/reference/rest/v1/projects.locations.keyRings.cryptoKeys/encrypt
+	gcpKMSBaseURL = "https://cloudkms.googleapis.com/v1/"
+	gcpKMSScope   = "https://www.googleapis.com/auth/cloudkms"
+)
+
+// KEK identifies the key encryption key that wraps data key of an object.
+type KEK struct {
+	Type string `json:"type"`
+	// Key name of Cloud KMS, key ID of AWS KMS or recipient of age
+	ID       string  `json:"id"`
+	Region   string  `json:"region,omitempty"`
+	Endpoint *string `json:"endpoint,omitempty"`
+}
+
+// keyWrapper wraps and unwraps data keys by a key encryption key.
+type keyWrapper interface {
+	wrap(ctx context.Context, dataKey []byte) ([]byte, error)
+	unwrap(ctx context.Context, wrapped []byte) ([]byte, error)
+}
+
+// newSealingWrapper creates keyWrapper from the encryption config of an action.
+func newSealingWrapper(ctx context.Context, clients *infra.Clients, encryption config.Encryption) (keyWrapper, KEK, error) {
+	switch v := encryption.(type) {
+	case *config.GCPKMSEncryptionImpl:
+		kek := KEK{Type: KEKTypeGCPKMS, ID: v.KeyName}
+		w, err := newGCPKMS(ctx, clients, kek)
+		return w, kek, err
+
+	case *config.AWSKMSEncryptionImpl:
+		kek := KEK{Type: KEKTypeAWSKMS, ID: v.KeyId, Region: v.AwsRegion, Endpoint: v.AwsEndpoint}
+		w, err := newAWSKMS(ctx, clients, kek, v.AwsCredential)
+		return w, kek, err
+
+	case *config.AgeEncryptionImpl:
+		recipient, err := age.ParseX25519Recipient(v.Recipient)
+		if err != nil {
+			return nil, KEK{}, goerr.Wrap(err, "failed to parse age recipient")
+		}
+		return &ageKEK{recipient: recipient}, KEK{Type: KEKTypeAge, ID: v.Recipient}, nil
+
+	default:
+		return nil, KEK{}, goerr.Wrap(types.ErrInvalidOption, "unknown encryption").With("encryption", fmt.Sprintf("%T", encryption))
+	}
+}
+
+// newOpeningWrapper creates keyWrapper from KEK stored in Key. Credentials of the caller (e.g. Application Default Credentials) are used, not ones of the action.
+func newOpeningWrapper(ctx context.Context, clients *infra.Clients, kek KEK, identities []age.Identity) (keyWrapper, error) {
+	switch kek.Type {
+	case KEKTypeGCPKMS:
+		return newGCPKMS(ctx, clients, kek)
+	case KEKTypeAWSKMS:
+		return newAWSKMS(ctx, clients, kek, &config.AWSDefaultCredentialImpl{})
+	case KEKTypeAge:
+		if len(identities) == 0 {
+			return nil, goerr.Wrap(types.ErrInvalidOption, "age identity is required to decrypt").With("recipient", kek.ID)
+		}
+		return &ageKEK{identities: identities}, nil
+	default:
+		return nil, goerr.Wrap(types.ErrInvalidOption, "unknown KEK type").With("type", kek.Type)
+	}
+}
+
+type gcpKMS struct {
+	client  interfaces.HTTPClient
+	keyName string
+}
+
+func newGCPKMS(ctx context.Context, clients *infra.Clients, kek KEK) (*gcpKMS, error) {
+	ts, err := clients.GoogleTokenSource(ctx, gcpKMSScope)
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to get Google token source")
+	}
+	return &gcpKMS{
+		client:  oauth.NewClient(clients.HTTPClient(), oauth.FromOAuth2(ts)),
+		keyName: kek.ID,
+	}, nil
+}
+
+func (x *gcpKMS) wrap(ctx context.Context, dataKey []byte) ([]byte, error) {
+	var resp struct {
+		Ciphertext []byte `json:"ciphertext"`
+	}
+	if err := x.call(ctx, "encrypt", map[string][]byte{"plaintext": dataKey}, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Ciphertext, nil
+}
+
+func (x *gcpKMS) unwrap(ctx context.Context, wrapped []byte) ([]byte, error) {
+	var resp struct {
+		Plaintext []byte `json:"plaintext"`
+	}
+	if err := x.call(ctx, "decrypt", map[string][]byte{"ciphertext": wrapped}, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Plaintext, nil
+}
+
+// call sends request of Cloud KMS REST API. []byte fields are encoded as base64 by encoding/json as the API expects.
+func (x *gcpKMS) call(ctx context.Context, method string, body any, resp any) error {
+	raw, err := json.Marshal(body)
+	if err != nil {
+		return goerr.Wrap(err, "failed to marshal Cloud KMS request")
+	}
+
+	apiURL := gcpKMSBaseURL + x.keyName + ":" + method
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, apiURL, bytes.NewReader(raw))
+	if err != nil {
+		return goerr.Wrap(err, "failed to create Cloud KMS request").With("url", apiURL)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	httpResp, err := x.client.Do(httpReq)
+	if err != nil {
+		return goerr.Wrap(err, "failed to send Cloud KMS request").With("url", apiURL)
+	}
+	defer utils.SafeClose(httpResp.Body)
+
+	if httpResp.StatusCode != http.StatusOK {
+		data, _ := io.ReadAll(httpResp.Body)
+		return goerr.New("unexpected status code of Cloud KMS").With("status", httpResp.Status).With("body", string(data)).With("url", apiURL)
+	}
+	if err := json.NewDecoder(httpResp.Body).Decode(resp); err != nil {
+		return goerr.Wrap(err, "failed to decode Cloud KMS response").With("url", apiURL)
+	}
+	return nil
+}
+
+type awsKMS struct {
+	client interfaces.KMS
+	keyID  string
+}
+
+func newAWSKMS(ctx context.Context, clients *infra.Clients, kek KEK, cred config.AWSCredential) (*awsKMS, error) {
+	awsCfg, err := awscred.LoadConfig(ctx, kek.Region, cred)
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to create AWS config for KMS").With("region", kek.Region)
+	}
+	client := clients.NewKMS(awsCfg, func(o *kms.Options) {
+		if kek.Endpoint != nil {
+			o.BaseEndpoint = kek.Endpoint
+		}
+	})
+	return &awsKMS{client: client, keyID: kek.ID}, nil
+}
+
+func (x *awsKMS) wrap(ctx context.Context, dataKey []byte) ([]byte, error) {
+	resp, err := x.client.Encrypt(ctx, &kms.EncryptInput{
+		KeyId:     aws.String(x.keyID),
+		Plaintext: dataKey,
+	})
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to encrypt data key by AWS KMS").With("keyID", x.keyID)
+	}
+	return resp.CiphertextBlob, nil
+}
+
+func (x *awsKMS) unwrap(ctx context.Context, wrapped []byte) ([]byte, error) {
+	resp, err := x.client.Decrypt(ctx, &kms.DecryptInput{
+		KeyId:          aws.String(x.keyID),
+		CiphertextBlob: wrapped,
+	})
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to decrypt data key by AWS KMS").With("keyID", x.keyID)
+	}
+	return resp.Plaintext, nil
+}
+
+type ageKEK struct {
+	recipient  age.Recipient
+	identities []age.Identity
+}
+
+func (x *ageKEK) wrap(ctx context.Context, dataKey []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	w, err := age.Encrypt(&buf, x.recipient)
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to encrypt data key by age")
+	}
+	if _, err := w.Write(dataKey); err != nil {
+		return nil, goerr.Wrap(err, "failed to encrypt data key by age")
+	}
+	if err := w.Close(); err != nil {
+		return nil, goerr.Wrap(err, "failed to encrypt data key by age")
+	}
+	return buf.Bytes(), nil
+}
+
+func (x *ageKEK) unwrap(ctx context.Context, wrapped []byte) ([]byte, error) {
+	r, err := age.Decrypt(bytes.NewReader(wrapped), x.identities...)
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to decrypt data key by age")
+	}
+	dataKey, err := io.ReadAll(r)
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to decrypt data key by age")
+	}
+	return dataKey, nil
+}
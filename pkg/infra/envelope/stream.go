@@ -0,0 +1,147 @@
+package envelope
+
+import (
+	"bufio"
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/binary"
+	"errors"
+	"io"
+
+	"github.com/m-mizutani/goerr"
+)
+
+const (
+	// Algorithm is the identifier of the stream format stored in Key.
+	Algorithm = "AES-256-GCM-STREAM-64K"
+
+	dataKeySize = 32
+	chunkSize   = 64 * 1024
+)
+
+// The plaintext is split into chunks of chunkSize and each chunk is sealed with a nonce of the chunk counter and the last chunk flag, so that reordering and truncation of chunks are detected. The counter nonce is safe because a data key is used for only one object.
+func newAEAD(dataKey []byte) (cipher.AEAD, error) {
+	if len(dataKey) != dataKeySize {
+		return nil, goerr.New("invalid data key size").With("size", len(dataKey))
+	}
+	block, err := aes.NewCipher(dataKey)
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to create AES cipher")
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to create GCM")
+	}
+	return aead, nil
+}
+
+func chunkNonce(counter uint64, last bool) []byte {
+	nonce := make([]byte, 12)
+	binary.BigEndian.PutUint64(nonce[3:11], counter)
+	if last {
+		nonce[11] = 1
+	}
+	return nonce
+}
+
+type encryptWriter struct {
+	w       io.Writer
+	aead    cipher.AEAD
+	buf     []byte
+	counter uint64
+}
+
+// newEncryptWriter returns writer that encrypts data into w. Close writes the last chunk and it does not close w.
+func newEncryptWriter(w io.Writer, dataKey []byte) (io.WriteCloser, error) {
+	aead, err := newAEAD(dataKey)
+	if err != nil {
+		return nil, err
+	}
+	return &encryptWriter{w: w, aead: aead}, nil
+}
+
+func (x *encryptWriter) Write(p []byte) (int, error) {
+	x.buf = append(x.buf, p...)
+	// Keep at least one byte so that the last chunk is sealed on Close
+	for len(x.buf) > chunkSize {
+		if err := x.seal(x.buf[:chunkSize], false); err != nil {
+			return 0, err
+		}
+		x.buf = x.buf[chunkSize:]
+	}
+	return len(p), nil
+}
+
+func (x *encryptWriter) Close() error {
+	return x.seal(x.buf, true)
+}
+
+func (x *encryptWriter) seal(chunk []byte, last bool) error {
+	sealed := x.aead.Seal(nil, chunkNonce(x.counter, last), chunk, nil)
+	x.counter++
+	if _, err := x.w.Write(sealed); err != nil {
+		return goerr.Wrap(err, "failed to write encrypted chunk")
+	}
+	return nil
+}
+
+type decryptReader struct {
+	r       *bufio.Reader
+	aead    cipher.AEAD
+	chunk   []byte
+	plain   []byte
+	counter uint64
+	done    bool
+}
+
+// newDecryptReader returns reader that decrypts data of r. It returns error if the data is modified or truncated.
+func newDecryptReader(r io.Reader, dataKey []byte) (io.Reader, error) {
+	aead, err := newAEAD(dataKey)
+	if err != nil {
+		return nil, err
+	}
+	return &decryptReader{
+		r:     bufio.NewReader(r),
+		aead:  aead,
+		chunk: make([]byte, chunkSize+aead.Overhead()),
+	}, nil
+}
+
+func (x *decryptReader) Read(p []byte) (int, error) {
+	for len(x.plain) == 0 {
+		if x.done {
+			return 0, io.EOF
+		}
+		if err := x.open(); err != nil {
+			return 0, err
+		}
+	}
+
+	n := copy(p, x.plain)
+	x.plain = x.plain[n:]
+	return n, nil
+}
+
+func (x *decryptReader) open() error {
+	n, err := io.ReadFull(x.r, x.chunk)
+	last := false
+	switch {
+	case errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF):
+		last = true
+	case err != nil:
+		return goerr.Wrap(err, "failed to read encrypted chunk")
+	default:
+		if _, err := x.r.Peek(1); errors.Is(err, io.EOF) {
+			last = true
+		}
+	}
+
+	plain, err := x.aead.Open(x.chunk[:0], chunkNonce(x.counter, last), x.chunk[:n], nil)
+	if err != nil {
+		return goerr.Wrap(err, "failed to decrypt chunk, data is modified or truncated").With("chunk", x.counter)
+	}
+	x.counter++
+	x.plain = plain
+	x.done = last
+	return nil
+}
@@ -0,0 +1,67 @@
+package oauth
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+
+	"github.com/m-mizutani/goerr"
+	"github.com/m-mizutani/hatchery/pkg/domain/types"
+)
+
+// ParseRSAPrivateKey parses PEM encoded RSA private key in PKCS#1 or PKCS#8 format.
+func ParseRSAPrivateKey(keyPEM []byte) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode(keyPEM)
+	if block == nil {
+		return nil, goerr.Wrap(types.ErrInvalidOption, "failed to decode private key PEM")
+	}
+
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to parse private key")
+	}
+	rsaKey, ok := key.(*rsa.PrivateKey)
+	if !ok {
+		return nil, goerr.Wrap(types.ErrInvalidOption, "private key is not RSA").With("type", fmt.Sprintf("%T", key))
+	}
+	return rsaKey, nil
+}
+
+// SignJWT builds JWT signed by RS256 that is used as client assertion or authorization grant. "alg" and "typ" are set to header automatically.
+func SignJWT(key *rsa.PrivateKey, header map[string]string, claims map[string]any) (string, error) {
+	hdr := map[string]string{
+		"alg": "RS256",
+		"typ": "JWT",
+	}
+	for k, v := range header {
+		hdr[k] = v
+	}
+
+	rawHeader, err := json.Marshal(hdr)
+	if err != nil {
+		return "", goerr.Wrap(err, "failed to marshal JWT header")
+	}
+	rawClaims, err := json.Marshal(claims)
+	if err != nil {
+		return "", goerr.Wrap(err, "failed to marshal JWT claims")
+	}
+
+	signingInput := base64.RawURLEncoding.EncodeToString(rawHeader) + "." + base64.RawURLEncoding.EncodeToString(rawClaims)
+	digest := sha256.Sum256([]byte(signingInput))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", goerr.Wrap(err, "failed to sign JWT")
+	}
+
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(sig), nil
+}
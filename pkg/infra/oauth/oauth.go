@@ -0,0 +1,177 @@
+package oauth
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/m-mizutani/goerr"
+	"github.com/m-mizutani/hatchery/pkg/domain/interfaces"
+	"github.com/m-mizutani/hatchery/pkg/utils"
+	"golang.org/x/oauth2"
+)
+
+const (
+	// Token is refreshed this period before its expiry
+	expiryMargin = time.Minute
+)
+
+// TokenSource provides access token to be set as Bearer token of Authorization header.
+type TokenSource interface {
+	Token(ctx context.Context) (string, error)
+}
+
+// StaticToken is TokenSource of a fixed token such as API token.
+type StaticToken string
+
+func (x StaticToken) Token(ctx context.Context) (string, error) {
+	return string(x), nil
+}
+
+// Config is a token request of OAuth 2.0 token endpoint.
+type Config struct {
+	TokenURL     string
+	ClientID     string
+	ClientSecret string
+
+	// BasicAuth sends client credentials by Authorization header (client_secret_basic) instead of form parameters (client_secret_post)
+	BasicAuth bool
+
+	// Params are additional form parameters of token request. grant_type is client_credentials if not specified.
+	Params url.Values
+
+	// ParamsFunc returns additional form parameters generated for each token request, e.g. client assertion that expires shortly. They are set after Params.
+	ParamsFunc func(ctx context.Context) (url.Values, error)
+}
+
+// NewTokenSource returns TokenSource that requests access token to the token endpoint and caches it until shortly before expiry.
+func NewTokenSource(client interfaces.HTTPClient, cfg Config) TokenSource {
+	return &tokenSource{
+		client: client,
+		cfg:    cfg,
+	}
+}
+
+type tokenSource struct {
+	client interfaces.HTTPClient
+	cfg    Config
+
+	mutex  sync.Mutex
+	token  string
+	expiry time.Time
+}
+
+func (x *tokenSource) Token(ctx context.Context) (string, error) {
+	x.mutex.Lock()
+	defer x.mutex.Unlock()
+
+	now := utils.CtxNow(ctx)
+	if x.token != "" && now.Add(expiryMargin).Before(x.expiry) {
+		return x.token, nil
+	}
+
+	form := url.Values{}
+	for k, v := range x.cfg.Params {
+		form[k] = v
+	}
+	if x.cfg.ParamsFunc != nil {
+		params, err := x.cfg.ParamsFunc(ctx)
+		if err != nil {
+			return "", err
+		}
+		for k, v := range params {
+			form[k] = v
+		}
+	}
+	if form.Get("grant_type") == "" {
+		form.Set("grant_type", "client_credentials")
+	}
+	if !x.cfg.BasicAuth {
+		form.Set("client_id", x.cfg.ClientID)
+		// Client authenticated by other means (e.g. client assertion) has no secret
+		if x.cfg.ClientSecret != "" {
+			form.Set("client_secret", x.cfg.ClientSecret)
+		}
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, x.cfg.TokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", goerr.Wrap(err, "failed to create token request").With("url", x.cfg.TokenURL)
+	}
+	httpReq.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	if x.cfg.BasicAuth {
+		httpReq.SetBasicAuth(x.cfg.ClientID, x.cfg.ClientSecret)
+	}
+
+	httpResp, err := x.client.Do(httpReq)
+	if err != nil {
+		return "", goerr.Wrap(err, "failed to send token request").With("url", x.cfg.TokenURL)
+	}
+	defer utils.SafeClose(httpResp.Body)
+
+	if httpResp.StatusCode != http.StatusOK {
+		data, _ := io.ReadAll(httpResp.Body)
+		return "", goerr.New("unexpected status code of token endpoint").With("status", httpResp.Status).With("body", string(data)).With("url", x.cfg.TokenURL)
+	}
+
+	var resp tokenResponse
+	if err := json.NewDecoder(httpResp.Body).Decode(&resp); err != nil {
+		return "", goerr.Wrap(err, "failed to unmarshal token response")
+	}
+	if resp.AccessToken == "" {
+		return "", goerr.New("empty access token").With("url", x.cfg.TokenURL)
+	}
+
+	x.token = resp.AccessToken
+	x.expiry = now.Add(time.Duration(resp.ExpiresIn) * time.Second)
+	return x.token, nil
+}
+
+type tokenResponse struct {
+	AccessToken string `json:"access_token"`
+	ExpiresIn   int    `json:"expires_in"`
+}
+
+// NewClient returns HTTP client that sets Bearer token from ts to Authorization header of each request.
+func NewClient(base interfaces.HTTPClient, ts TokenSource) interfaces.HTTPClient {
+	return &client{base: base, ts: ts}
+}
+
+type client struct {
+	base interfaces.HTTPClient
+	ts   TokenSource
+}
+
+func (x *client) Do(req *http.Request) (*http.Response, error) {
+	token, err := x.ts.Token(req.Context())
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to get access token")
+	}
+
+	// Do not modify the original request
+	req = req.Clone(req.Context())
+	req.Header.Set("Authorization", "Bearer "+token)
+	return x.base.Do(req)
+}
+
+// FromOAuth2 converts oauth2.TokenSource (e.g. Google Application Default Credentials) to TokenSource.
+func FromOAuth2(ts oauth2.TokenSource) TokenSource {
+	return &oauth2TokenSource{ts: ts}
+}
+
+type oauth2TokenSource struct {
+	ts oauth2.TokenSource
+}
+
+func (x *oauth2TokenSource) Token(ctx context.Context) (string, error) {
+	token, err := x.ts.Token()
+	if err != nil {
+		return "", goerr.Wrap(err, "failed to get token from oauth2.TokenSource")
+	}
+	return token.AccessToken, nil
+}
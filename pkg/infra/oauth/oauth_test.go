@@ -0,0 +1,72 @@
+package oauth_test
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/hatchery/pkg/infra/oauth"
+	"github.com/m-mizutani/hatchery/pkg/utils"
+)
+
+func TestTokenSource(t *testing.T) {
+	var called int
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST example.com/oauth/token", func(w http.ResponseWriter, r *http.Request) {
+		called++
+		user, pass, ok := r.BasicAuth()
+		gt.Equal(t, ok, true)
+		gt.Equal(t, user, "test-id")
+		gt.Equal(t, pass, "test-secret")
+
+		gt.NoError(t, r.ParseForm())
+		gt.Equal(t, r.PostForm.Get("grant_type"), "account_credentials")
+		gt.Equal(t, r.PostForm.Get("account_id"), "test-account")
+		gt.Equal(t, r.PostForm.Get("client_secret"), "")
+		_, _ = w.Write([]byte(`{"access_token":"test-token","expires_in":3600}`))
+	})
+	mux.HandleFunc("GET example.com/api", func(w http.ResponseWriter, r *http.Request) {
+		gt.Equal(t, r.Header.Get("Authorization"), "Bearer test-token")
+		w.WriteHeader(http.StatusNoContent)
+	})
+	base := utils.NewFakeHTTPClient(mux)
+
+	now := time.Date(2024, 4, 1, 10, 0, 0, 0, time.UTC)
+	ctx := utils.CtxWithNow(context.Background(), func() time.Time { return now })
+
+	ts := oauth.NewTokenSource(base, oauth.Config{
+		TokenURL:     "https://example.com/oauth/token",
+		ClientID:     "test-id",
+		ClientSecret: "test-secret",
+		BasicAuth:    true,
+		Params: url.Values{
+			"grant_type": {"account_credentials"},
+			"account_id": {"test-account"},
+		},
+	})
+	client := oauth.NewClient(base, ts)
+
+	for i := 0; i < 2; i++ {
+		req := gt.R1(http.NewRequestWithContext(ctx, http.MethodGet, "https://example.com/api", nil)).NoError(t)
+		resp := gt.R1(client.Do(req)).NoError(t)
+		gt.Equal(t, resp.StatusCode, http.StatusNoContent)
+		gt.Equal(t, req.Header.Get("Authorization"), "")
+	}
+	gt.Equal(t, called, 1)
+
+	now = now.Add(time.Hour)
+	gt.R1(ts.Token(ctx)).NoError(t)
+	gt.Equal(t, called, 2)
+}
+
+func TestStaticToken(t *testing.T) {
+	base := utils.NewFakeHTTPClient(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gt.Equal(t, r.Header.Get("Authorization"), "Bearer static-token")
+	}))
+
+	req := gt.R1(http.NewRequest(http.MethodGet, "https://example.com/api", nil)).NoError(t)
+	gt.R1(oauth.NewClient(base, oauth.StaticToken("static-token")).Do(req)).NoError(t)
+}
@@ -0,0 +1,289 @@
+package output
+
+import (
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"time"
+
+	"github.com/klauspost/compress/s2"
+	"github.com/klauspost/compress/zstd"
+	"github.com/m-mizutani/goerr"
+	"github.com/m-mizutani/hatchery/pkg/domain/config"
+	"github.com/m-mizutani/hatchery/pkg/domain/interfaces"
+	"github.com/m-mizutani/hatchery/pkg/domain/model"
+	"github.com/m-mizutani/hatchery/pkg/domain/types"
+	"github.com/m-mizutani/hatchery/pkg/utils"
+)
+
+var zstdLevels = map[string]zstd.EncoderLevel{
+	"fastest": zstd.SpeedFastest,
+	"default": zstd.SpeedDefault,
+	"better":  zstd.SpeedBetterCompression,
+	"best":    zstd.SpeedBestCompression,
+}
+
+// NewEncoder returns writer that compresses data into w by the compression. nil is regarded as Gzip with default level. Close of the returned writer does not close w.
+func NewEncoder(w io.Writer, compression config.Compression) (io.WriteCloser, error) {
+	newEncoder, err := encoderOf(compression)
+	if err != nil {
+		return nil, err
+	}
+	return newEncoder(w), nil
+}
+
+// encoderOf validates the compression and returns constructor of encoder, so that an object is not created with invalid compression.
+func encoderOf(compression config.Compression) (func(w io.Writer) io.WriteCloser, error) {
+	switch v := compression.(type) {
+	case nil:
+		return func(w io.Writer) io.WriteCloser { return gzip.NewWriter(w) }, nil
+
+	case *config.GzipImpl:
+		level := gzip.DefaultCompression
+		if v.Level != nil {
+			level = *v.Level
+		}
+		if level != gzip.DefaultCompression && (level < gzip.BestSpeed || level > gzip.BestCompression) {
+			return nil, goerr.Wrap(types.ErrInvalidOption, "invalid gzip level").With("level", level)
+		}
+		return func(w io.Writer) io.WriteCloser {
+			enc, _ := gzip.NewWriterLevel(w, level) // level is already validated
+			return enc
+		}, nil
+
+	case *config.ZstdImpl:
+		level, ok := zstdLevels[v.Level]
+		if !ok {
+			return nil, goerr.Wrap(types.ErrInvalidOption, "invalid zstd level").With("level", v.Level)
+		}
+		return func(w io.Writer) io.WriteCloser {
+			enc, _ := zstd.NewWriter(w, zstd.WithEncoderLevel(level)) // error is returned only for invalid options
+			return enc
+		}, nil
+
+	case *config.SnappyImpl:
+		return func(w io.Writer) io.WriteCloser { return s2.NewWriter(w, s2.WriterSnappyCompat()) }, nil
+
+	case *config.NoCompressionImpl:
+		return func(w io.Writer) io.WriteCloser { return nopCloser{w} }, nil
+
+	default:
+		return nil, goerr.Wrap(types.ErrInvalidOption, "unknown compression").With("compression", fmt.Sprintf("%T", compression))
+	}
+}
+
+// NewDecoder returns reader that decompresses data of r by the compression.
+func NewDecoder(r io.Reader, compression config.Compression) (io.ReadCloser, error) {
+	switch compression.(type) {
+	case nil, *config.GzipImpl:
+		dec, err := gzip.NewReader(r)
+		if err != nil {
+			return nil, goerr.Wrap(err, "failed to create gzip reader")
+		}
+		return dec, nil
+
+	case *config.ZstdImpl:
+		dec, err := zstd.NewReader(r)
+		if err != nil {
+			return nil, goerr.Wrap(err, "failed to create zstd reader")
+		}
+		return dec.IOReadCloser(), nil
+
+	case *config.SnappyImpl:
+		return io.NopCloser(s2.NewReader(r)), nil
+
+	case *config.NoCompressionImpl:
+		return io.NopCloser(r), nil
+
+	default:
+		return nil, goerr.Wrap(types.ErrInvalidOption, "unknown compression").With("compression", fmt.Sprintf("%T", compression))
+	}
+}
+
+type nopCloser struct {
+	io.Writer
+}
+
+func (nopCloser) Close() error { return nil }
+
+const (
+	ContentTypeJSON   = "application/json"
+	ContentTypeNDJSON = "application/x-ndjson"
+	ContentTypeCSV    = "text/csv"
+
+	MetadataActionID    = "hatchery-action-id"
+	MetadataRequestID   = "hatchery-request-id"
+	MetadataVersion     = "hatchery-version"
+	MetadataWindowStart = "hatchery-window-start"
+	MetadataWindowEnd   = "hatchery-window-end"
+)
+
+// ContentEncoding returns Content-Encoding of the compression. Empty means no encoding.
+func ContentEncoding(compression config.Compression) string {
+	switch compression.(type) {
+	case *config.ZstdImpl:
+		return "zstd"
+	case *config.SnappyImpl:
+		return "x-snappy-framed"
+	case *config.NoCompressionImpl:
+		return ""
+	default:
+		return "gzip"
+	}
+}
+
+// WithWindow records time window of the data in the object as metadata.
+func WithWindow(start, end time.Time) types.WriteOption {
+	return func(attrs *types.ObjectAttrs) {
+		types.WithMetadata(MetadataWindowStart, start.UTC().Format(time.RFC3339))(attrs)
+		types.WithMetadata(MetadataWindowEnd, end.UTC().Format(time.RFC3339))(attrs)
+	}
+}
+
+// ObjectOptions returns attributes of objects of the action: metadata of the action, request ID and version, storage class, and precondition that the object does not exist if object_naming is "content". Content type and encoding are not included.
+func ObjectOptions(ctx context.Context, action config.Action) []types.WriteOption {
+	reqID, _ := utils.CtxRequestID(ctx)
+	opts := []types.WriteOption{
+		types.WithMetadata(MetadataActionID, action.GetId()),
+		types.WithMetadata(MetadataRequestID, string(reqID)),
+		types.WithMetadata(MetadataVersion, model.AppVersion),
+	}
+	if storageClass := action.GetStorageClass(); storageClass != nil {
+		opts = append(opts, types.WithStorageClass(*storageClass))
+	}
+	if action.GetObjectNaming() == model.ObjectNamingContent {
+		opts = append(opts, types.WithIfNotExist())
+	}
+	return opts
+}
+
+// Writer writes data into an object of CloudStorage with compression of the action.
+type Writer struct {
+	ctx    context.Context
+	w      io.Writer
+	enc    io.WriteCloser
+	obj    io.WriteCloser
+	object types.CSObjectName
+
+	// Set only if the object is named by content. Compressed data is buffered in tmp until the name is fixed by digest on Close.
+	storage interfaces.CloudStorage
+	bucket  types.CSBucket
+	opts    []types.WriteOption
+	tmp     *os.File
+	digest  hash.Hash
+}
+
+// NewObjectWriter creates object in the bucket of the action and returns Writer to it. Close must be called to complete the object. The object has ObjectOptions, content type of JSON and content encoding of the compression by default, and opts override them.
+func NewObjectWriter(ctx context.Context, storage interfaces.CloudStorage, action config.Action, object types.CSObjectName, opts ...types.WriteOption) (*Writer, error) {
+	newEncoder, err := encoderOf(action.GetCompression())
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to create object writer").With("object", object)
+	}
+
+	obj := storage.NewObjectWriter(ctx, types.CSBucket(action.GetBucket()), object, objectOptions(ctx, action, opts)...)
+	enc := newEncoder(obj)
+	return &Writer{ctx: ctx, w: enc, enc: enc, obj: obj, object: object}, nil
+}
+
+// NewLogObjectWriter is NewObjectWriter for log objects named by model.DefaultLogObjectName or model.DatasetLogObjectName. If object_naming of the action is "content", the object is created on Close with name replaced by model.ContentObjectName. Object returns the name.
+func NewLogObjectWriter(ctx context.Context, storage interfaces.CloudStorage, action config.Action, object types.CSObjectName, opts ...types.WriteOption) (*Writer, error) {
+	if action.GetObjectNaming() != model.ObjectNamingContent {
+		return NewObjectWriter(ctx, storage, action, object, opts...)
+	}
+
+	newEncoder, err := encoderOf(action.GetCompression())
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to create object writer").With("object", object)
+	}
+	tmp, err := os.CreateTemp("", "hatchery-object-*")
+	if err != nil {
+		return nil, goerr.Wrap(err, "failed to create temporary file for object").With("object", object)
+	}
+
+	enc := newEncoder(tmp)
+	digest := sha256.New()
+	return &Writer{
+		ctx:     ctx,
+		w:       io.MultiWriter(digest, enc),
+		enc:     enc,
+		object:  object,
+		storage: storage,
+		bucket:  types.CSBucket(action.GetBucket()),
+		opts:    objectOptions(ctx, action, opts),
+		tmp:     tmp,
+		digest:  digest,
+	}, nil
+}
+
+func objectOptions(ctx context.Context, action config.Action, opts []types.WriteOption) []types.WriteOption {
+	attrs := append(ObjectOptions(ctx, action),
+		types.WithContentType(ContentTypeJSON),
+		types.WithContentEncoding(ContentEncoding(action.GetCompression())),
+	)
+	return append(attrs, opts...)
+}
+
+// Object returns name of the object. If the object is named by content, the name is fixed after Close.
+func (x *Writer) Object() types.CSObjectName {
+	return x.object
+}
+
+func (x *Writer) Write(p []byte) (int, error) {
+	return x.w.Write(p)
+}
+
+// Abort discards the data without creating the object. It must be called instead of Close when writing fails, to release the object writer and the temporary file.
+func (x *Writer) Abort() {
+	// Release the encoder. Data flushed by it is discarded with the object
+	_ = x.enc.Close()
+	if x.tmp != nil {
+		utils.SafeClose(x.tmp)
+		_ = os.Remove(x.tmp.Name())
+	}
+	if x.obj != nil {
+		utils.SafeAbort(x.obj)
+	}
+}
+
+// Close flushes compressed data and closes the object. The object is aborted if flushing fails, not to complete a broken object. If the object already exists by precondition, the data is discarded and Close succeeds because the same data has been written.
+func (x *Writer) Close() error {
+	if x.tmp != nil {
+		defer func() {
+			utils.SafeClose(x.tmp)
+			_ = os.Remove(x.tmp.Name())
+		}()
+	}
+
+	if err := x.enc.Close(); err != nil {
+		if x.obj != nil {
+			utils.SafeAbort(x.obj)
+		}
+		return goerr.Wrap(err, "failed to close encoder").With("object", x.object)
+	}
+
+	if x.tmp != nil {
+		x.object = model.ContentObjectName(x.object, x.digest.Sum(nil))
+		if _, err := x.tmp.Seek(0, io.SeekStart); err != nil {
+			return goerr.Wrap(err, "failed to seek temporary file for object").With("object", x.object)
+		}
+		x.obj = x.storage.NewObjectWriter(x.ctx, x.bucket, x.object, x.opts...)
+		if _, err := io.Copy(x.obj, x.tmp); err != nil {
+			utils.SafeAbort(x.obj)
+			return goerr.Wrap(err, "failed to write object").With("object", x.object)
+		}
+	}
+
+	if err := x.obj.Close(); err != nil {
+		if errors.Is(err, types.ErrObjectExists) {
+			utils.CtxLogger(x.ctx).Info("object already exists, skipped", "object", x.object)
+			return nil
+		}
+		return goerr.Wrap(err, "failed to close object").With("object", x.object)
+	}
+	return nil
+}
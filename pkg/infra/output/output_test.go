@@ -0,0 +1,167 @@
+package output_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/hatchery/pkg/domain/config"
+	"github.com/m-mizutani/hatchery/pkg/domain/model"
+	"github.com/m-mizutani/hatchery/pkg/domain/types"
+	"github.com/m-mizutani/hatchery/pkg/infra/cs"
+	"github.com/m-mizutani/hatchery/pkg/infra/output"
+	"github.com/m-mizutani/hatchery/pkg/utils"
+)
+
+func TestCompression(t *testing.T) {
+	level := 9
+	testCases := map[string]struct {
+		compression config.Compression
+		ext         string
+	}{
+		"default":        {compression: nil, ext: ".gz"},
+		"gzip":           {compression: &config.GzipImpl{Level: &level}, ext: ".gz"},
+		"zstd":           {compression: &config.ZstdImpl{Level: "best"}, ext: ".zst"},
+		"snappy":         {compression: &config.SnappyImpl{}, ext: ".sz"},
+		"no compression": {compression: &config.NoCompressionImpl{}, ext: ""},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			mock := cs.NewMock()
+			action := &config.WebhookImpl{Id: "test", Bucket: "test-bucket", Compression: tc.compression}
+
+			w := gt.R1(output.NewObjectWriter(ctx, mock, action, "obj.json"+types.CSObjectName(model.CompressionExt(tc.compression)))).NoError(t)
+			_ = gt.R1(w.Write([]byte(`{"a":1}`))).NoError(t)
+			gt.NoError(t, w.Close())
+
+			gt.A(t, mock.Results).Length(1).At(0, func(t testing.TB, v *cs.MockResult) {
+				gt.Equal(t, v.Object, types.CSObjectName("obj.json"+tc.ext))
+				gt.Equal(t, v.Body.Closed, true)
+				r := gt.R1(output.NewDecoder(bytes.NewReader(v.Body.Bytes()), tc.compression)).NoError(t)
+				gt.Equal(t, string(gt.R1(io.ReadAll(r)).NoError(t)), `{"a":1}`)
+			})
+		})
+	}
+
+	t.Run("object attributes", func(t *testing.T) {
+		ctx := context.Background()
+		reqID, ctx := utils.CtxRequestID(ctx)
+		mock := cs.NewMock()
+		storageClass := "COLDLINE"
+		action := &config.WebhookImpl{Id: "test", Bucket: "test-bucket", Compression: &config.ZstdImpl{Level: "default"}, StorageClass: &storageClass}
+
+		start := time.Date(2024, 4, 1, 9, 0, 0, 0, time.UTC)
+		w := gt.R1(output.NewObjectWriter(ctx, mock, action, "obj.csv.zst",
+			output.WithWindow(start, start.Add(time.Hour)),
+			types.WithContentType(output.ContentTypeCSV),
+		)).NoError(t)
+		gt.NoError(t, w.Close())
+
+		gt.A(t, mock.Results).Length(1).At(0, func(t testing.TB, v *cs.MockResult) {
+			gt.Equal(t, v.Attrs.ContentType, "text/csv")
+			gt.Equal(t, v.Attrs.ContentEncoding, "zstd")
+			gt.Equal(t, v.Attrs.StorageClass, "COLDLINE")
+			gt.Equal(t, v.Attrs.Metadata, map[string]string{
+				"hatchery-action-id":    "test",
+				"hatchery-request-id":   string(reqID),
+				"hatchery-version":      model.AppVersion,
+				"hatchery-window-start": "2024-04-01T09:00:00Z",
+				"hatchery-window-end":   "2024-04-01T10:00:00Z",
+			})
+		})
+	})
+
+	t.Run("invalid gzip level", func(t *testing.T) {
+		mock := cs.NewMock()
+		invalid := 10
+		action := &config.WebhookImpl{Id: "test", Bucket: "test-bucket", Compression: &config.GzipImpl{Level: &invalid}}
+		gt.R1(output.NewObjectWriter(context.Background(), mock, action, "obj.json.gz")).Error(t)
+		gt.A(t, mock.Results).Length(0)
+	})
+}
+
+func TestObjectNaming(t *testing.T) {
+	now := time.Date(2024, 4, 1, 9, 0, 0, 0, time.UTC)
+	write := func(ctx context.Context, mock *cs.Mock, action config.Action, data string) types.CSObjectName {
+		w := gt.R1(output.NewLogObjectWriter(ctx, mock, action, model.DefaultLogObjectName(ctx, action, now, 0))).NoError(t)
+		_ = gt.R1(w.Write([]byte(data))).NoError(t)
+		gt.NoError(t, w.Close())
+		return w.Object()
+	}
+
+	t.Run("request", func(t *testing.T) {
+		mock := cs.NewMock()
+		action := &config.WebhookImpl{Id: "test", Bucket: "test-bucket", ObjectNaming: model.ObjectNamingRequest}
+
+		_, ctx1 := utils.CtxRequestID(context.Background())
+		_, ctx2 := utils.CtxRequestID(context.Background())
+		gt.NotEqual(t, write(ctx1, mock, action, `{"a":1}`), write(ctx2, mock, action, `{"a":1}`))
+		gt.A(t, mock.Results).Length(2).At(0, func(t testing.TB, v *cs.MockResult) {
+			gt.Equal(t, v.Attrs.IfNotExist, false)
+		})
+	})
+
+	t.Run("content", func(t *testing.T) {
+		mock := cs.NewMock()
+		prefix := "webhook/"
+		action := &config.WebhookImpl{Id: "test", Bucket: "test-bucket", Prefix: &prefix, ObjectNaming: model.ObjectNamingContent}
+		digest := sha256.Sum256([]byte(`{"a":1}`))
+
+		// Retried executions have different request IDs
+		_, ctx1 := utils.CtxRequestID(context.Background())
+		_, ctx2 := utils.CtxRequestID(context.Background())
+		obj1 := write(ctx1, mock, action, `{"a":1}`)
+		obj2 := write(ctx2, mock, action, `{"a":1}`)
+		gt.Equal(t, obj1, types.CSObjectName("webhook/logs/2024/04/01/09/"+hex.EncodeToString(digest[:])+".json.gz"))
+		gt.Equal(t, obj2, obj1)
+
+		gt.A(t, mock.Results).Length(1).At(0, func(t testing.TB, v *cs.MockResult) {
+			gt.Equal(t, v.Object, obj1)
+			gt.Equal(t, v.Body.Closed, true)
+			gt.Equal(t, v.Attrs.IfNotExist, true)
+			r := gt.R1(output.NewDecoder(bytes.NewReader(v.Body.Bytes()), nil)).NoError(t)
+			gt.Equal(t, string(gt.R1(io.ReadAll(r)).NoError(t)), `{"a":1}`)
+		})
+
+		// Different content is written into another object
+		gt.NotEqual(t, write(ctx1, mock, action, `{"a":2}`), obj1)
+		gt.A(t, mock.Results).Length(2)
+	})
+}
+
+func TestAbort(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("request", func(t *testing.T) {
+		mock := cs.NewMock()
+		action := &config.WebhookImpl{Id: "test", Bucket: "test-bucket"}
+
+		w := gt.R1(output.NewObjectWriter(ctx, mock, action, "obj.json.gz")).NoError(t)
+		_ = gt.R1(w.Write([]byte(`{"a":1}`))).NoError(t)
+		w.Abort()
+
+		gt.A(t, mock.Results).Length(1).At(0, func(t testing.TB, v *cs.MockResult) {
+			gt.Equal(t, v.Body.Aborted, true)
+			gt.Equal(t, v.Body.Closed, false)
+		})
+	})
+
+	t.Run("content", func(t *testing.T) {
+		mock := cs.NewMock()
+		action := &config.WebhookImpl{Id: "test", Bucket: "test-bucket", ObjectNaming: model.ObjectNamingContent}
+
+		w := gt.R1(output.NewLogObjectWriter(ctx, mock, action, model.DefaultLogObjectName(ctx, action, time.Now(), 0))).NoError(t)
+		_ = gt.R1(w.Write([]byte(`{"a":1}`))).NoError(t)
+		w.Abort()
+
+		// The object is not created because it is named on Close
+		gt.A(t, mock.Results).Length(0)
+	})
+}
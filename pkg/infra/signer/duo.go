@@ -0,0 +1,68 @@
+package signer
+
+import (
+	"crypto/hmac"
+	"crypto/sha1" // #nosec G505 HMAC-SHA1 is required by Duo API
+	"encoding/hex"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/m-mizutani/hatchery/pkg/domain/interfaces"
+	"github.com/m-mizutani/hatchery/pkg/utils"
+)
+
+// Duo signs requests of Duo Admin API by HMAC-SHA1 of canonical request. Parameters are taken from query string, then it supports GET requests only.
+// See https://duo.com/docs/adminapi#authentication
+type Duo struct {
+	IntegrationKey string
+	SecretKey      string
+}
+
+var _ interfaces.RequestSigner = &Duo{}
+
+// RFC 2822 format required by Date header of Duo API
+const duoDateLayout = "Mon, 02 Jan 2006 15:04:05 -0700"
+
+func (x *Duo) Sign(req *http.Request) error {
+	date := utils.CtxNow(req.Context()).UTC().Format(duoDateLayout)
+
+	canon := strings.Join([]string{
+		date,
+		strings.ToUpper(req.Method),
+		strings.ToLower(req.URL.Host),
+		req.URL.Path,
+		duoCanonParams(req.URL.Query()),
+	}, "\n")
+
+	mac := hmac.New(sha1.New, []byte(x.SecretKey))
+	_, _ = mac.Write([]byte(canon))
+
+	req.Header.Set("Date", date)
+	req.SetBasicAuth(x.IntegrationKey, hex.EncodeToString(mac.Sum(nil)))
+	return nil
+}
+
+// duoCanonParams returns sorted and URL encoded parameters. Space must be encoded as %20 instead of +.
+func duoCanonParams(qv url.Values) string {
+	keys := make([]string, 0, len(qv))
+	for k := range qv {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var params []string
+	for _, k := range keys {
+		vs := append([]string{}, qv[k]...)
+		sort.Strings(vs)
+		for _, v := range vs {
+			params = append(params, duoEscape(k)+"="+duoEscape(v))
+		}
+	}
+	return strings.Join(params, "&")
+}
+
+func duoEscape(s string) string {
+	return strings.ReplaceAll(url.QueryEscape(s), "+", "%20")
+}
@@ -0,0 +1,27 @@
+package signer
+
+import (
+	"net/http"
+
+	"github.com/m-mizutani/goerr"
+	"github.com/m-mizutani/hatchery/pkg/domain/interfaces"
+)
+
+// NewClient returns HTTP client that signs each request by signer before sending it with base.
+func NewClient(base interfaces.HTTPClient, signer interfaces.RequestSigner) interfaces.HTTPClient {
+	return &client{base: base, signer: signer}
+}
+
+type client struct {
+	base   interfaces.HTTPClient
+	signer interfaces.RequestSigner
+}
+
+func (x *client) Do(req *http.Request) (*http.Response, error) {
+	// Do not modify the original request
+	req = req.Clone(req.Context())
+	if err := x.signer.Sign(req); err != nil {
+		return nil, goerr.Wrap(err, "failed to sign request").With("url", req.URL.String())
+	}
+	return x.base.Do(req)
+}
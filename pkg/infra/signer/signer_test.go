@@ -0,0 +1,47 @@
+package signer_test
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/hex"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/m-mizutani/gt"
+	"github.com/m-mizutani/hatchery/pkg/infra/signer"
+	"github.com/m-mizutani/hatchery/pkg/utils"
+)
+
+func TestDuo(t *testing.T) {
+	now := time.Date(2024, 4, 1, 10, 0, 0, 0, time.UTC)
+	ctx := utils.CtxWithNow(context.Background(), func() time.Time { return now })
+
+	canon := "Mon, 01 Apr 2024 10:00:00 +0000\n" +
+		"GET\n" +
+		"api-xxxxxxxx.duosecurity.com\n" +
+		"/admin/v2/logs/authentication\n" +
+		"limit=100&maxtime=1711965600000&mintime=1711962000000&name=First%20Last"
+	mac := hmac.New(sha1.New, []byte("test-skey"))
+	_, _ = mac.Write([]byte(canon))
+	expected := hex.EncodeToString(mac.Sum(nil))
+
+	var called int
+	client := signer.NewClient(utils.NewFakeHTTPClient(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called++
+		gt.Equal(t, r.Header.Get("Date"), "Mon, 01 Apr 2024 10:00:00 +0000")
+		user, pass, ok := r.BasicAuth()
+		gt.Equal(t, ok, true)
+		gt.Equal(t, user, "test-ikey")
+		gt.Equal(t, pass, expected)
+	})), &signer.Duo{IntegrationKey: "test-ikey", SecretKey: "test-skey"})
+
+	req := gt.R1(http.NewRequestWithContext(ctx, http.MethodGet,
+		"https://API-xxxxxxxx.duosecurity.com/admin/v2/logs/authentication?mintime=1711962000000&name=First+Last&maxtime=1711965600000&limit=100", nil)).NoError(t)
+	gt.R1(client.Do(req)).NoError(t)
+	gt.Equal(t, called, 1)
+
+	// Original request is not modified
+	gt.Equal(t, req.Header.Get("Authorization"), "")
+}
@@ -2,23 +2,46 @@ package usecase
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"log/slog"
-	"sync"
+	"strings"
+	"time"
 
 	"github.com/m-mizutani/goerr"
+	"github.com/m-mizutani/hatchery/pkg/actions/atlassian"
+	"github.com/m-mizutani/hatchery/pkg/actions/box"
+	"github.com/m-mizutani/hatchery/pkg/actions/cloudflare"
+	"github.com/m-mizutani/hatchery/pkg/actions/dropbox"
+	"github.com/m-mizutani/hatchery/pkg/actions/duo"
+	"github.com/m-mizutani/hatchery/pkg/actions/entra_id"
 	"github.com/m-mizutani/hatchery/pkg/actions/fdr"
+	"github.com/m-mizutani/hatchery/pkg/actions/gcp_audit"
+	"github.com/m-mizutani/hatchery/pkg/actions/gcp_pubsub"
+	"github.com/m-mizutani/hatchery/pkg/actions/jamf"
+	"github.com/m-mizutani/hatchery/pkg/actions/kafka"
+	"github.com/m-mizutani/hatchery/pkg/actions/kandji"
+	"github.com/m-mizutani/hatchery/pkg/actions/office365"
 	"github.com/m-mizutani/hatchery/pkg/actions/one_password"
+	"github.com/m-mizutani/hatchery/pkg/actions/salesforce"
 	"github.com/m-mizutani/hatchery/pkg/actions/slack"
+	"github.com/m-mizutani/hatchery/pkg/actions/slack_workspace"
+	"github.com/m-mizutani/hatchery/pkg/actions/snowflake"
+	"github.com/m-mizutani/hatchery/pkg/actions/tailscale"
+	"github.com/m-mizutani/hatchery/pkg/actions/zoom"
 	"github.com/m-mizutani/hatchery/pkg/domain/config"
 	"github.com/m-mizutani/hatchery/pkg/domain/model"
 	"github.com/m-mizutani/hatchery/pkg/domain/types"
 	"github.com/m-mizutani/hatchery/pkg/infra"
+	"github.com/m-mizutani/hatchery/pkg/infra/cs"
 	"github.com/m-mizutani/hatchery/pkg/utils"
 )
 
 type executeConfig struct {
-	dryRun bool
-	execFn func(context.Context, *infra.Clients, config.Action) error
+	dryRun      bool
+	maxParallel int
+	timeout     time.Duration
+	execFn      func(context.Context, *infra.Clients, config.Action) error
 }
 
 type ExecuteOption func(*executeConfig)
@@ -29,6 +52,20 @@ func WithDryRun() ExecuteOption {
 	}
 }
 
+// WithMaxParallel limits number of actions executed in parallel. 0 means unlimited.
+func WithMaxParallel(n int) ExecuteOption {
+	return func(c *executeConfig) {
+		c.maxParallel = n
+	}
+}
+
+// WithTimeout sets deadline of whole execution. Actions still running or waiting at the deadline are canceled and reported as timed out. 0 means unlimited.
+func WithTimeout(d time.Duration) ExecuteOption {
+	return func(c *executeConfig) {
+		c.timeout = d
+	}
+}
+
 // WithExecFn is an option to specify a function to execute an action. This is used for testing.
 func WithExecFn(fn func(context.Context, *infra.Clients, config.Action) error) ExecuteOption {
 	return func(c *executeConfig) {
@@ -36,6 +73,7 @@ func WithExecFn(fn func(context.Context, *infra.Clients, config.Action) error) E
 	}
 }
 
+// Execute runs selected actions in parallel. Actions are scheduled by max parallel of options, concurrency_key and depends_on of actions, both in dry run and with WithExecFn. Each action is canceled by its timeout, and all actions by timeout of options.
 func Execute(ctx context.Context, clients *infra.Clients, actions []config.Action, selector *model.Selector, options ...ExecuteOption) error {
 	_, ctx = utils.CtxRequestID(ctx)
 	cfg := executeConfig{
@@ -45,6 +83,16 @@ func Execute(ctx context.Context, clients *infra.Clients, actions []config.Actio
 		opt(&cfg)
 	}
 
+	if cfg.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, cfg.timeout)
+		defer cancel()
+	}
+
+	if err := validateDependencies(actions); err != nil {
+		return err
+	}
+
 	var executable []config.Action
 	for _, action := range actions {
 		if !selector.Contains(action) {
@@ -58,40 +106,62 @@ func Execute(ctx context.Context, clients *infra.Clients, actions []config.Actio
 	for _, action := range executable {
 		attrs = append(attrs, actionToAttr(action))
 	}
-	utils.CtxLogger(ctx).Info("Start execution", slog.Group("actions", attrs...))
+	utils.CtxLogger(ctx).Info("Start execution", slog.Group("actions", attrs...), "maxParallel", cfg.maxParallel, "timeout", cfg.timeout)
 
-	wg := sync.WaitGroup{}
-	errCh := make(chan error, len(executable))
+	tasks := newScheduler(executable, cfg.maxParallel).run(ctx, func(action config.Action) error {
+		attr := actionToAttr(action)
+		if cfg.dryRun {
+			utils.CtxLogger(ctx).Info("Dry run", attr)
+			return nil
+		}
 
-	for _, action := range executable {
-		wg.Add(1)
-		go func(action config.Action) {
-			defer wg.Done()
-
-			attr := actionToAttr(action)
-			if cfg.dryRun {
-				utils.CtxLogger(ctx).Info("Dry run", attr)
-				return
-			}
-
-			utils.CtxLogger(ctx).Info("Start action", attr)
-			if err := cfg.execFn(ctx, clients, action); err != nil {
-				utils.HandleError(ctx, "failed to execute action", err)
-				errCh <- err
-			}
-		}(action)
-	}
+		storage, err := cs.ForAction(ctx, clients, action)
+		if err != nil {
+			utils.HandleError(ctx, "failed to configure destinations", err)
+			return err
+		}
 
-	wg.Wait()
-	close(errCh)
+		actionCtx := ctx
+		if timeout := action.GetTimeout(); timeout != nil {
+			var cancel context.CancelFunc
+			actionCtx, cancel = context.WithTimeout(ctx, timeout.GoDuration())
+			defer cancel()
+		}
 
-	var errs []error
-	for err := range errCh {
-		errs = append(errs, err)
+		utils.CtxLogger(ctx).Info("Start action", attr)
+		if err := cfg.execFn(actionCtx, clients.With(infra.WithCloudStorage(storage), infra.WithStateStorage(clients.StateStorage())), action); err != nil {
+			err = asTimeout(actionCtx, action.GetId(), err)
+			utils.HandleError(ctx, "failed to execute action", err)
+			return err
+		}
+		return nil
+	})
+
+	var (
+		errs                        []error
+		succeeded, failed, timedOut []string
+	)
+	for _, t := range tasks {
+		id := t.action.GetId()
+		switch {
+		case t.err == nil:
+			succeeded = append(succeeded, id)
+		case errors.Is(t.err, types.ErrActionTimeout):
+			timedOut = append(timedOut, id)
+			errs = append(errs, t.err)
+		default:
+			failed = append(failed, id)
+			errs = append(errs, t.err)
+		}
 	}
+	utils.CtxLogger(ctx).Info("Finish execution", "succeeded", succeeded, "failed", failed, "timedOut", timedOut)
+
 	if len(errs) > 0 {
 		// This is a case that multiple actions are executed and some of them are failed. This error will not be reported to Sentry, just logging.
-		return goerr.Wrap(types.ErrActonFailed, "failed to execute actions").With("errors", errs)
+		return goerr.Wrap(types.ErrActonFailed, "failed to execute actions").
+			With("errors", errs).
+			With("failed", failed).
+			With("timed_out", timedOut)
 	}
 
 	return nil
@@ -105,29 +175,54 @@ func executeAction(ctx context.Context, clients *infra.Clients, action config.Ac
 		return fdr.Exec(ctx, clients, v)
 	case *config.SlackImpl:
 		return slack.Exec(ctx, clients, v)
+	case *config.SlackWorkspaceImpl:
+		return slack_workspace.Exec(ctx, clients, v)
+	case *config.Office365Impl:
+		return office365.Exec(ctx, clients, v)
+	case *config.EntraIDImpl:
+		return entra_id.Exec(ctx, clients, v)
+	case *config.AtlassianAuditImpl:
+		return atlassian.Exec(ctx, clients, v)
+	case *config.SnowflakeAccountUsageImpl:
+		return snowflake.Exec(ctx, clients, v)
+	case *config.GCPAuditLogsImpl:
+		return gcp_audit.Exec(ctx, clients, v)
+	case *config.TailscaleImpl:
+		return tailscale.Exec(ctx, clients, v)
+	case *config.JamfProImpl:
+		return jamf.Exec(ctx, clients, v)
+	case *config.KandjiImpl:
+		return kandji.Exec(ctx, clients, v)
+	case *config.DuoImpl:
+		return duo.Exec(ctx, clients, v)
+	case *config.CloudflareImpl:
+		return cloudflare.Exec(ctx, clients, v)
+	case *config.SalesforceEventLogImpl:
+		return salesforce.Exec(ctx, clients, v)
+	case *config.ZoomImpl:
+		return zoom.Exec(ctx, clients, v)
+	case *config.BoxImpl:
+		return box.Exec(ctx, clients, v)
+	case *config.DropboxImpl:
+		return dropbox.Exec(ctx, clients, v)
+	case *config.KafkaTopicImpl:
+		return kafka.Exec(ctx, clients, v)
+	case *config.PubSubSubscriptionImpl:
+		return gcp_pubsub.Exec(ctx, clients, v)
 	default:
 		return goerr.Wrap(types.ErrAssertFailed, "unknown action type").With("action", action)
 	}
 }
 
 func actionToAttr(action config.Action) slog.Attr {
-	switch v := action.(type) {
-	case *config.OnePasswordImpl:
-		return slog.Group(action.GetId(),
-			slog.String("type", "OnePassword"),
-			slog.Any("config", *v),
-		)
-
-	case *config.FalconDataReplicatorImpl:
-		return slog.Group(action.GetId(),
-			slog.String("type", "FalconDataReplicator"),
-			slog.Any("config", *v),
-		)
+	return slog.Group(action.GetId(),
+		slog.String("type", actionType(action)),
+		slog.Any("config", action),
+	)
+}
 
-	default:
-		return slog.Group(action.GetId(),
-			slog.String("type", "unknown"),
-			slog.Any("config", action),
-		)
-	}
+// actionType returns name of the action type from its config, e.g. "Slack" for *config.SlackImpl.
+func actionType(action config.Action) string {
+	name := fmt.Sprintf("%T", action)
+	return strings.TrimSuffix(name[strings.LastIndex(name, ".")+1:], "Impl")
 }
@@ -2,11 +2,17 @@ package usecase
 
 import (
 	"context"
+	"errors"
+	"sync"
 	"testing"
+	"time"
 
+	"github.com/apple/pkl-go/pkl"
+	"github.com/m-mizutani/goerr"
 	"github.com/m-mizutani/gt"
 	"github.com/m-mizutani/hatchery/pkg/domain/config"
 	"github.com/m-mizutani/hatchery/pkg/domain/model"
+	"github.com/m-mizutani/hatchery/pkg/domain/types"
 	"github.com/m-mizutani/hatchery/pkg/infra"
 )
 
@@ -59,8 +65,11 @@ func TestExecute(t *testing.T) {
 
 	for name, tc := range testCases {
 		t.Run(name, func(t *testing.T) {
+			var mutex sync.Mutex
 			var executed []string
 			execFn := func(ctx context.Context, clients *infra.Clients, action config.Action) error {
+				mutex.Lock()
+				defer mutex.Unlock()
 				executed = append(executed, action.GetId())
 				return nil
 			}
@@ -76,3 +85,225 @@ func TestExecute(t *testing.T) {
 		})
 	}
 }
+
+// recorder records executed actions and the max number of actions running at the same time
+type recorder struct {
+	mutex    sync.Mutex
+	running  map[string]int
+	max      map[string]int
+	executed []string
+	fail     map[string]bool
+}
+
+func newRecorder() *recorder {
+	return &recorder{running: map[string]int{}, max: map[string]int{}, fail: map[string]bool{}}
+}
+
+func (x *recorder) enter(keys ...string) {
+	x.mutex.Lock()
+	defer x.mutex.Unlock()
+	for _, key := range keys {
+		x.running[key]++
+		x.max[key] = max(x.max[key], x.running[key])
+	}
+}
+
+func (x *recorder) leave(id string, keys ...string) {
+	x.mutex.Lock()
+	defer x.mutex.Unlock()
+	for _, key := range keys {
+		x.running[key]--
+	}
+	x.executed = append(x.executed, id)
+}
+
+func (x *recorder) execFn(ctx context.Context, clients *infra.Clients, action config.Action) error {
+	keys := []string{"all"}
+	if key := action.GetConcurrencyKey(); key != nil {
+		keys = append(keys, *key)
+	}
+	x.enter(keys...)
+	time.Sleep(10 * time.Millisecond)
+	x.leave(action.GetId(), keys...)
+
+	if x.fail[action.GetId()] {
+		return errors.New("failed")
+	}
+	return nil
+}
+
+func key(k string) *string {
+	return &k
+}
+
+func TestExecuteSchedule(t *testing.T) {
+	all := &model.Selector{All: true}
+
+	t.Run("max parallel", func(t *testing.T) {
+		var actions []config.Action
+		for _, id := range []string{"a1", "a2", "a3", "a4", "a5", "a6"} {
+			actions = append(actions, &config.FalconDataReplicatorImpl{Id: id})
+		}
+
+		rec := newRecorder()
+		gt.NoError(t, Execute(context.Background(), &infra.Clients{}, actions, all, WithExecFn(rec.execFn), WithMaxParallel(2)))
+		gt.A(t, rec.executed).Length(6)
+		gt.Equal(t, rec.max["all"], 2)
+	})
+
+	t.Run("concurrency key", func(t *testing.T) {
+		actions := []config.Action{
+			&config.SlackImpl{Id: "slack1", ConcurrencyKey: key("slack-token")},
+			&config.SlackImpl{Id: "slack2", ConcurrencyKey: key("slack-token")},
+			&config.SlackImpl{Id: "slack3", ConcurrencyKey: key("slack-token")},
+			&config.FalconDataReplicatorImpl{Id: "falcon1"},
+		}
+
+		rec := newRecorder()
+		gt.NoError(t, Execute(context.Background(), &infra.Clients{}, actions, all, WithExecFn(rec.execFn)))
+		gt.A(t, rec.executed).Length(4)
+		gt.Equal(t, rec.max["slack-token"], 1)
+	})
+
+	t.Run("depends on", func(t *testing.T) {
+		actions := []config.Action{
+			&config.FalconDataReplicatorImpl{Id: "third", DependsOn: []string{"second"}},
+			&config.FalconDataReplicatorImpl{Id: "second", DependsOn: []string{"first"}},
+			&config.FalconDataReplicatorImpl{Id: "first"},
+		}
+
+		rec := newRecorder()
+		gt.NoError(t, Execute(context.Background(), &infra.Clients{}, actions, all, WithExecFn(rec.execFn)))
+		gt.Equal(t, rec.executed, []string{"first", "second", "third"})
+	})
+
+	t.Run("dependency not selected is ignored", func(t *testing.T) {
+		actions := []config.Action{
+			&config.FalconDataReplicatorImpl{Id: "first"},
+			&config.FalconDataReplicatorImpl{Id: "second", DependsOn: []string{"first"}},
+		}
+
+		rec := newRecorder()
+		gt.NoError(t, Execute(context.Background(), &infra.Clients{}, actions, &model.Selector{IDs: []string{"second"}}, WithExecFn(rec.execFn)))
+		gt.Equal(t, rec.executed, []string{"second"})
+	})
+
+	t.Run("skipped if dependency fails", func(t *testing.T) {
+		actions := []config.Action{
+			&config.FalconDataReplicatorImpl{Id: "first"},
+			&config.FalconDataReplicatorImpl{Id: "second", DependsOn: []string{"first"}},
+			&config.FalconDataReplicatorImpl{Id: "third", DependsOn: []string{"second"}},
+			&config.FalconDataReplicatorImpl{Id: "other"},
+		}
+
+		rec := newRecorder()
+		rec.fail["first"] = true
+		err := Execute(context.Background(), &infra.Clients{}, actions, all, WithExecFn(rec.execFn))
+		gt.B(t, errors.Is(err, types.ErrActonFailed)).True()
+		gt.A(t, rec.executed).Length(2).Have("first").Have("other")
+	})
+
+	t.Run("invalid depends on", func(t *testing.T) {
+		testCases := map[string][]config.Action{
+			"unknown action": {
+				&config.FalconDataReplicatorImpl{Id: "first", DependsOn: []string{"missing"}},
+			},
+			"cycle": {
+				&config.FalconDataReplicatorImpl{Id: "first", DependsOn: []string{"second"}},
+				&config.FalconDataReplicatorImpl{Id: "second", DependsOn: []string{"first"}},
+			},
+			"self": {
+				&config.FalconDataReplicatorImpl{Id: "first", DependsOn: []string{"first"}},
+			},
+		}
+		for name, actions := range testCases {
+			t.Run(name, func(t *testing.T) {
+				rec := newRecorder()
+				err := Execute(context.Background(), &infra.Clients{}, actions, all, WithExecFn(rec.execFn))
+				gt.B(t, errors.Is(err, types.ErrInvalidOption)).True()
+				gt.A(t, rec.executed).Length(0)
+			})
+		}
+	})
+
+	t.Run("dry run", func(t *testing.T) {
+		actions := []config.Action{
+			&config.FalconDataReplicatorImpl{Id: "first", DependsOn: []string{"second"}},
+			&config.FalconDataReplicatorImpl{Id: "second", DependsOn: []string{"first"}},
+		}
+		gt.Error(t, Execute(context.Background(), &infra.Clients{}, actions, all, WithDryRun()))
+
+		rec := newRecorder()
+		actions = []config.Action{
+			&config.FalconDataReplicatorImpl{Id: "first"},
+			&config.FalconDataReplicatorImpl{Id: "second", DependsOn: []string{"first"}},
+		}
+		gt.NoError(t, Execute(context.Background(), &infra.Clients{}, actions, all, WithDryRun(), WithExecFn(rec.execFn), WithMaxParallel(1)))
+		gt.A(t, rec.executed).Length(0)
+	})
+}
+
+func TestExecuteTimeout(t *testing.T) {
+	all := &model.Selector{All: true}
+
+	// blockFn blocks actions except "fast" until ctx is canceled
+	blockFn := func(ctx context.Context, clients *infra.Clients, action config.Action) error {
+		if action.GetId() == "fast" {
+			return nil
+		}
+		<-ctx.Done()
+		return ctx.Err()
+	}
+	timeout := &pkl.Duration{Value: 10, Unit: pkl.Millisecond}
+
+	t.Run("action timeout", func(t *testing.T) {
+		actions := []config.Action{
+			&config.FalconDataReplicatorImpl{Id: "slow", Timeout: timeout},
+			&config.FalconDataReplicatorImpl{Id: "fast"},
+		}
+
+		err := Execute(context.Background(), &infra.Clients{}, actions, all, WithExecFn(blockFn))
+		gt.B(t, errors.Is(err, types.ErrActonFailed)).True()
+		values := goerr.Unwrap(err).Values()
+		gt.Equal(t, values["timed_out"], any([]string{"slow"}))
+		gt.Equal(t, values["failed"], any([]string(nil)))
+		errs := values["errors"].([]error)
+		gt.A(t, errs).Length(1)
+		gt.B(t, errors.Is(errs[0], types.ErrActionTimeout)).True()
+		gt.B(t, errors.Is(errs[0], context.DeadlineExceeded)).True()
+	})
+
+	t.Run("other error is not regarded as timeout", func(t *testing.T) {
+		actions := []config.Action{
+			&config.FalconDataReplicatorImpl{Id: "broken", Timeout: timeout},
+		}
+		brokenFn := func(ctx context.Context, clients *infra.Clients, action config.Action) error {
+			<-ctx.Done()
+			return errors.New("broken")
+		}
+
+		err := Execute(context.Background(), &infra.Clients{}, actions, all, WithExecFn(brokenFn))
+		values := goerr.Unwrap(err).Values()
+		gt.Equal(t, values["failed"], any([]string{"broken"}))
+		gt.Equal(t, values["timed_out"], any([]string(nil)))
+	})
+
+	t.Run("execution timeout", func(t *testing.T) {
+		actions := []config.Action{
+			&config.FalconDataReplicatorImpl{Id: "slow"},
+			&config.FalconDataReplicatorImpl{Id: "waiting", DependsOn: []string{"slow"}},
+			&config.FalconDataReplicatorImpl{Id: "fast"},
+		}
+
+		err := Execute(context.Background(), &infra.Clients{}, actions, all, WithExecFn(blockFn), WithTimeout(10*time.Millisecond))
+		gt.B(t, errors.Is(err, types.ErrActonFailed)).True()
+		values := goerr.Unwrap(err).Values()
+		gt.Equal(t, values["timed_out"], any([]string{"slow", "waiting"}))
+	})
+}
+
+func TestActionType(t *testing.T) {
+	gt.Equal(t, actionType(&config.SlackImpl{}), "Slack")
+	gt.Equal(t, actionType(&config.OnePasswordImpl{}), "OnePassword")
+	gt.Equal(t, actionType(&config.SnowflakeAccountUsageImpl{}), "SnowflakeAccountUsage")
+}
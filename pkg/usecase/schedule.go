@@ -0,0 +1,177 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/m-mizutani/goerr"
+	"github.com/m-mizutani/hatchery/pkg/domain/config"
+	"github.com/m-mizutani/hatchery/pkg/domain/types"
+	"github.com/m-mizutani/hatchery/pkg/utils"
+)
+
+// validateDependencies checks that depends_on of all actions refers to existing actions without cycle. All actions are checked regardless of selection, so that invalid config is found by any execution.
+func validateDependencies(actions []config.Action) error {
+	byID := map[string]config.Action{}
+	for _, action := range actions {
+		byID[action.GetId()] = action
+	}
+	for _, action := range actions {
+		for _, dep := range action.GetDependsOn() {
+			if _, ok := byID[dep]; !ok {
+				return goerr.Wrap(types.ErrInvalidOption, "unknown action in depends_on").With("id", action.GetId()).With("depends_on", dep)
+			}
+		}
+	}
+
+	const (
+		visiting = iota + 1
+		visited
+	)
+	state := map[string]int{}
+	var visit func(id string, path []string) error
+	visit = func(id string, path []string) error {
+		path = append(path, id)
+		switch state[id] {
+		case visiting:
+			return goerr.Wrap(types.ErrInvalidOption, "circular depends_on").With("path", path)
+		case visited:
+			return nil
+		}
+
+		state[id] = visiting
+		for _, dep := range byID[id].GetDependsOn() {
+			if err := visit(dep, path); err != nil {
+				return err
+			}
+		}
+		state[id] = visited
+		return nil
+	}
+
+	for _, action := range actions {
+		if err := visit(action.GetId(), nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type task struct {
+	action config.Action
+	done   chan struct{}
+	// err is set before done is closed
+	err error
+}
+
+// scheduler runs actions with limits of max parallel and concurrency keys, and in order of depends_on.
+type scheduler struct {
+	tasks []*task
+	byID  map[string]*task
+	// sem is nil if max parallel is unlimited
+	sem  chan struct{}
+	keys map[string]chan struct{}
+}
+
+func newScheduler(actions []config.Action, maxParallel int) *scheduler {
+	x := &scheduler{
+		byID: map[string]*task{},
+		keys: map[string]chan struct{}{},
+	}
+	if maxParallel > 0 {
+		x.sem = make(chan struct{}, maxParallel)
+	}
+
+	for _, action := range actions {
+		t := &task{action: action, done: make(chan struct{})}
+		x.tasks = append(x.tasks, t)
+		x.byID[action.GetId()] = t
+
+		if key := action.GetConcurrencyKey(); key != nil {
+			if _, ok := x.keys[*key]; !ok {
+				x.keys[*key] = make(chan struct{}, 1)
+			}
+		}
+	}
+
+	return x
+}
+
+// run calls fn for each action after its dependencies finish, holding the lock of its concurrency key and a slot of max parallel. Dependencies that are not scheduled are ignored, and an action is skipped if any of its dependencies fails or is skipped. An action still waiting when ctx exceeds its deadline is regarded as timed out, as well as one failed by the deadline. It returns all tasks with their errors.
+func (x *scheduler) run(ctx context.Context, fn func(action config.Action) error) []*task {
+	var wg sync.WaitGroup
+	for _, t := range x.tasks {
+		wg.Add(1)
+		go func(t *task) {
+			defer wg.Done()
+			defer close(t.done)
+			t.err = asTimeout(ctx, t.action.GetId(), x.exec(ctx, t, fn))
+		}(t)
+	}
+	wg.Wait()
+
+	return x.tasks
+}
+
+func (x *scheduler) exec(ctx context.Context, t *task, fn func(action config.Action) error) error {
+	id := t.action.GetId()
+
+	for _, dep := range t.action.GetDependsOn() {
+		d, ok := x.byID[dep]
+		if !ok {
+			continue
+		}
+		select {
+		case <-d.done:
+		case <-ctx.Done():
+			return goerr.Wrap(ctx.Err(), "canceled while waiting for dependency").With("id", id).With("dependency", dep)
+		}
+		// The dependency may have finished by cancellation of ctx
+		if err := ctx.Err(); err != nil {
+			return goerr.Wrap(err, "canceled while waiting for dependency").With("id", id).With("dependency", dep)
+		}
+		if d.err != nil {
+			utils.CtxLogger(ctx).Warn("Skip action because dependency failed", "id", id, "dependency", dep)
+			return goerr.Wrap(types.ErrActonFailed, "dependency failed").With("id", id).With("dependency", dep)
+		}
+	}
+
+	// The key is acquired before the slot, not to occupy a slot while waiting for the key
+	if key := t.action.GetConcurrencyKey(); key != nil {
+		release, err := acquire(ctx, x.keys[*key])
+		if err != nil {
+			return goerr.Wrap(err, "canceled while waiting for concurrency key").With("id", id).With("key", *key)
+		}
+		defer release()
+	}
+
+	if x.sem != nil {
+		release, err := acquire(ctx, x.sem)
+		if err != nil {
+			return goerr.Wrap(err, "canceled while waiting for slot of max parallel").With("id", id)
+		}
+		defer release()
+	}
+
+	return fn(t.action)
+}
+
+func acquire(ctx context.Context, sem chan struct{}) (func(), error) {
+	select {
+	case sem <- struct{}{}:
+		return func() { <-sem }, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// asTimeout marks err as ErrActionTimeout if it is caused by the deadline of ctx, so that timeouts are distinguished from failures in the run summary. The original error is kept in the chain.
+func asTimeout(ctx context.Context, id string, err error) error {
+	if err == nil || errors.Is(err, types.ErrActionTimeout) ||
+		!errors.Is(err, context.DeadlineExceeded) || !errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return err
+	}
+	return goerr.Wrap(fmt.Errorf("%w: %w", types.ErrActionTimeout, err), "action timed out").With("id", id)
+}
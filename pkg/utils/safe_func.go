@@ -1,6 +1,10 @@
 package utils
 
-import "io"
+import (
+	"io"
+
+	"github.com/m-mizutani/hatchery/pkg/domain/interfaces"
+)
 
 func SafeClose(closer io.Closer) {
 	if closer != nil {
@@ -9,3 +13,10 @@ func SafeClose(closer io.Closer) {
 		}
 	}
 }
+
+// SafeAbort discards the object of a writer of CloudStorage on failure. Writers that do not implement interfaces.ObjectAborter are left as is.
+func SafeAbort(w io.Writer) {
+	if aborter, ok := w.(interfaces.ObjectAborter); ok {
+		aborter.Abort()
+	}
+}
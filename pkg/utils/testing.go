@@ -1,6 +1,8 @@
 package utils
 
 import (
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"testing"
 )
@@ -14,3 +16,18 @@ func LoadEnv(t *testing.T, key string) string {
 
 	return v
 }
+
+// FakeHTTPClient is HTTP client for testing that serves requests by the handler in process instead of sending them.
+type FakeHTTPClient struct {
+	handler http.Handler
+}
+
+func NewFakeHTTPClient(handler http.Handler) *FakeHTTPClient {
+	return &FakeHTTPClient{handler: handler}
+}
+
+func (x *FakeHTTPClient) Do(req *http.Request) (*http.Response, error) {
+	w := httptest.NewRecorder()
+	x.handler.ServeHTTP(w, req)
+	return w.Result(), nil
+}